@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/htchan/BookSpider/internal/common"
 	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
 	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
 	"github.com/htchan/BookSpider/internal/router"
 	"github.com/rs/zerolog"
@@ -53,6 +58,8 @@ func main() {
 
 	defer db.Close()
 
+	metrics.RegisterDBStats(db)
+
 	// ctx := context.Background()
 	// publicSema := semaphore.NewWeighted(int64(conf.BatchConfig.MaxWorkingThreads))
 	// services := make(map[string]service_new.Service)
@@ -67,7 +74,15 @@ func main() {
 
 	// 	services[siteName] = serv
 	// }
-	services := common.LoadServices(conf.AvailableSiteNames, db, conf.SiteConfigs, 1)
+	services, loadServErr := common.LoadServices(conf.AvailableSiteNames, db, conf.SiteConfigs, 1)
+	if loadServErr != nil {
+		log.Error().Err(loadServErr).Msg("load services fail")
+		return
+	}
+
+	if conf.AdminToken == "" {
+		log.Warn().Msg("admin token not set, jobs and book tasks admin api reject all requests")
+	}
 
 	// load routes
 	r := chi.NewRouter()
@@ -79,6 +94,8 @@ func main() {
 	router.AddLiteRoutes(r, conf, services)
 	// }
 
+	router.AddMetricsRoutes(r)
+
 	server := http.Server{
 		Addr:         ":9427",
 		Handler:      r,
@@ -86,22 +103,35 @@ func main() {
 		WriteTimeout: 300 * time.Second,
 		IdleTimeout:  300 * time.Second,
 	}
-	// go func() {
-	log.Info().Msg("start http server")
 
-	if httpErr := server.ListenAndServe(); httpErr != nil {
-		log.Error().Err(httpErr).Msg("backend stopped")
-		return
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Info().Msg("start http server")
+
+		if httpErr := server.ListenAndServe(); httpErr != nil && !errors.Is(httpErr, http.ErrServerClosed) {
+			log.Error().Err(httpErr).Msg("backend stopped")
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info().Msg("received stop signal")
+
+	// drain in-flight requests before the deferred db close
+	shutdownTimeout := conf.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = config.DefaultShutdownTimeout
 	}
-	// }()
 
-	// sigChan := make(chan os.Signal, 1)
-	// signal.Notify(sigChan, os.Interrupt)
-	// <-sigChan
-	// log.Println("received interrupt signal")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Error().Err(shutdownErr).Msg("shutdown http server failed")
+		return
+	}
 
-	// // Setup graceful shutdown
-	// ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	// defer cancel()
-	// server.Shutdown(ctx)
+	log.Info().Msg("http server stopped")
 }
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+
+	_ "github.com/lib/pq"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/caarlos0/env/v6"
+	"github.com/htchan/BookSpider/internal/model"
+)
+
+// patch-search-text recomputes books.normalized_title and writers.normalized_name
+// with model.NormalizeSearchText. the migration adding these columns can only
+// lower case the text in sql, so traditional chinese records have to be
+// converted by this command before they can be found by simplified keywords
+
+type DbConf struct {
+	Host     string `env:"PSQL_HOST,required" validate:"min=1"`
+	Port     string `env:"PSQL_PORT,required" validate:"min=1"`
+	User     string `env:"PSQL_USER,required" validate:"min=1"`
+	Password string `env:"PSQL_PASSWORD,required" validate:"min=1"`
+	Name     string `env:"PSQL_NAME,required" validate:"min=1"`
+}
+
+type TitleSource struct {
+	Site            string
+	ID              int
+	Hash            int
+	Title           string
+	NormalizedTitle string
+}
+
+type WriterSource struct {
+	ID             int
+	Name           string
+	NormalizedName string
+}
+
+func main() {
+	var conf DbConf
+	if err := env.Parse(&conf); err != nil {
+		log.Fatalln(err)
+	}
+
+	conn := fmt.Sprintf(
+		"host=%v port=%v user=%v password=%v dbname=%v sslmode=disable",
+		conf.Host, conf.Port, conf.User, conf.Password, conf.Name,
+	)
+	db, err := sql.Open("postgres", conn)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	sema := semaphore.NewWeighted(100)
+
+	titleCount, err := patchTitles(ctx, db, sema)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writerCount, err := patchWriters(ctx, db, sema)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	log.Printf("patched %d books and %d writers", titleCount, writerCount)
+}
+
+func patchTitles(ctx context.Context, db *sql.DB, sema *semaphore.Weighted) (int, error) {
+	rows, err := db.QueryContext(ctx, "select site, id, hash_code, coalesce(title, ''), coalesce(normalized_title, '') from books")
+	if err != nil {
+		return 0, fmt.Errorf("query books fail: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		count int
+	)
+
+	for rows.Next() {
+		var src TitleSource
+		if err := rows.Scan(&src.Site, &src.ID, &src.Hash, &src.Title, &src.NormalizedTitle); err != nil {
+			log.Println(err)
+			continue
+		}
+
+		normalized := model.NormalizeSearchText(src.Title)
+		if normalized == src.NormalizedTitle {
+			continue
+		}
+
+		wg.Add(1)
+		sema.Acquire(ctx, 1)
+		go func(src TitleSource) {
+			defer wg.Done()
+			defer sema.Release(1)
+
+			_, err := db.ExecContext(ctx,
+				"update books set normalized_title=$1 where site=$2 and id=$3 and hash_code=$4",
+				normalized, src.Site, src.ID, src.Hash,
+			)
+			if err != nil {
+				log.Printf("[%v-%v-%v] patch normalized title fail: %v", src.Site, src.ID, src.Hash, err)
+				return
+			}
+
+			mu.Lock()
+			count++
+			mu.Unlock()
+		}(src)
+	}
+
+	wg.Wait()
+
+	return count, rows.Err()
+}
+
+func patchWriters(ctx context.Context, db *sql.DB, sema *semaphore.Weighted) (int, error) {
+	rows, err := db.QueryContext(ctx, "select id, name, coalesce(normalized_name, '') from writers")
+	if err != nil {
+		return 0, fmt.Errorf("query writers fail: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		count int
+	)
+
+	for rows.Next() {
+		var src WriterSource
+		if err := rows.Scan(&src.ID, &src.Name, &src.NormalizedName); err != nil {
+			log.Println(err)
+			continue
+		}
+
+		normalized := model.NormalizeSearchText(src.Name)
+		if normalized == src.NormalizedName {
+			continue
+		}
+
+		wg.Add(1)
+		sema.Acquire(ctx, 1)
+		go func(src WriterSource) {
+			defer wg.Done()
+			defer sema.Release(1)
+
+			_, err := db.ExecContext(ctx, "update writers set normalized_name=$1 where id=$2", normalized, src.ID)
+			if err != nil {
+				log.Printf("[writer-%v] patch normalized name fail: %v", src.ID, err)
+				return
+			}
+
+			mu.Lock()
+			count++
+			mu.Unlock()
+		}(src)
+	}
+
+	wg.Wait()
+
+	return count, rows.Err()
+}
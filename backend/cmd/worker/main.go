@@ -2,42 +2,27 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/htchan/BookSpider/internal/common"
 	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
 	repo "github.com/htchan/BookSpider/internal/repo/sqlc"
+	"github.com/htchan/BookSpider/internal/router"
+	"github.com/htchan/BookSpider/internal/schedule"
 	"github.com/htchan/BookSpider/internal/service"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-func CalculateNextRunTime(conf *config.ScheduleConfig) time.Time {
-	result := time.Now().UTC().Truncate(24 * time.Hour)
-
-	for true {
-		result = time.Date(result.Year(), result.Month(), conf.InitDate, conf.InitHour, conf.InitMinute, 0, 0, time.UTC)
-		if result.Weekday() != conf.MatchWeekday {
-			nDaysLater := int(conf.MatchWeekday - result.Weekday())
-			if nDaysLater < 0 {
-				nDaysLater += 7
-			}
-
-			result = result.AddDate(0, 0, nDaysLater)
-		}
-
-		if time.Now().Before(result) {
-			return result
-		}
-
-		result = result.AddDate(0, conf.IntervalMonth, conf.IntervalDay)
-	}
-
-	return result
-}
-
 func main() {
 	outputPath := os.Getenv("OUTPUT_PATH")
 	if outputPath != "" {
@@ -67,6 +52,13 @@ func main() {
 		return
 	}
 
+	if envs := config.RemovedScheduleEnvsSet(); len(envs) > 0 {
+		log.Warn().
+			Strs("envs", envs).
+			Str("default_schedule", config.DefaultProcessSchedule).
+			Msg("schedule envs are removed and ignored, configure schedules of sites in config yaml instead")
+	}
+
 	repo.Migrate(conf.DatabaseConfig, "/migrations")
 
 	db, dbErr := repo.OpenDatabaseByConfig(conf.DatabaseConfig)
@@ -77,31 +69,180 @@ func main() {
 
 	defer db.Close()
 
-	services := common.LoadServices(conf.AvailableSiteNames, db, conf.SiteConfigs, int64(conf.MaxWorkingThreads))
+	metrics.RegisterDBStats(db)
+
+	services, loadServErr := common.LoadServices(conf.AvailableSiteNames, db, conf.SiteConfigs, int64(conf.MaxWorkingThreads))
+	if loadServErr != nil {
+		log.Error().Err(loadServErr).Msg("load services fail")
+		return
+	}
+
+	entries, scheduleErr := scheduleEntries(services, conf.SiteConfigs)
+	if scheduleErr != nil {
+		log.Error().Err(scheduleErr).Msg("load schedules fail")
+		return
+	}
+
+	shutdownTimeout := conf.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = config.DefaultShutdownTimeout
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ctx = log.Logger.WithContext(ctx)
+
+	adminServer := startAdminServer(conf, services, stop)
+
+	jobPollInterval := conf.JobPollInterval
+	if jobPollInterval <= 0 {
+		jobPollInterval = config.DefaultJobPollInterval
+	}
+
+	jobConsumersDone := startJobConsumers(ctx, services, jobPollInterval)
+
+	schedulerDone := make(chan struct{})
+	go func() {
+		schedule.NewScheduler(entries...).Run(ctx)
+		close(schedulerDone)
+	}()
+
+	// once ctx is done, scheduled operations stop picking new books and are
+	// given shutdownTimeout to checkpoint the chapters in progress. scheduler,
+	// job consumers and admin server share the same deadline, so the worker
+	// stops within the termination grace period
+	<-ctx.Done()
+	log.Log().Dur("shutdown_timeout", shutdownTimeout).Msg("received stop signal, wait for scheduled runs to stop")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	select {
+	case <-schedulerDone:
+	case <-shutdownCtx.Done():
+		log.Error().Msg("scheduled runs not stopped before shutdown timeout")
+	}
+
+	select {
+	case <-jobConsumersDone:
+	case <-shutdownCtx.Done():
+		log.Error().Msg("job consumers not stopped before shutdown timeout")
+	}
 
-	// loop all sites by calling process
+	if adminServer != nil {
+		if shutdownErr := adminServer.Shutdown(shutdownCtx); shutdownErr != nil {
+			log.Error().Err(shutdownErr).Msg("shutdown admin server failed")
+		}
+	}
+
+	log.Log().Msg("worker stopped")
+}
+
+// startAdminServer serve the admin routes of services in background. it
+// returns nil if admin address is not configured
+func startAdminServer(conf *config.WorkerConfig, services map[string]service.Service, stop func()) *http.Server {
+	if conf.AdminAddr == "" {
+		return nil
+	}
+
+	if conf.AdminToken == "" {
+		log.Warn().Msg("admin token not set, admin routes reject all requests")
+	}
+
+	r := chi.NewRouter()
+	router.AddAdminRoutes(r, conf.AdminToken, services)
+	router.AddMetricsRoutes(r)
+
+	server := &http.Server{
+		Addr:         conf.AdminAddr,
+		Handler:      r,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  300 * time.Second,
+	}
+
+	go func() {
+		log.Info().Str("addr", conf.AdminAddr).Msg("start admin server")
+
+		if httpErr := server.ListenAndServe(); httpErr != nil && !errors.Is(httpErr, http.ErrServerClosed) {
+			log.Error().Err(httpErr).Msg("admin server stopped")
+			stop()
+		}
+	}()
+
+	return server
+}
+
+// startJobConsumers run the jobs enqueued through api for each service in
+// background until ctx is done. the returned channel is closed once all
+// consumers stopped
+func startJobConsumers(ctx context.Context, services map[string]service.Service, interval time.Duration) <-chan struct{} {
 	var wg sync.WaitGroup
 
-	for true {
-		until := CalculateNextRunTime(&conf.ScheduleConfig)
-		log.Log().Time("scheduled_at", until).Msg("start sleep")
-		time.Sleep(time.Until(until))
-		log.Log().Msg("start regular batch process")
-
-		for _, serv := range services {
-			serv := serv
-			wg.Add(1)
-			go func(serv service.Service) {
-				defer wg.Done()
-				ctx := log.Logger.WithContext(context.Background())
-				processErr := serv.Process(ctx)
-				if processErr != nil {
-					log.Error().Err(processErr).Str("site", serv.Name()).Msg("process failed")
+	for _, serv := range services {
+		wg.Add(1)
+		go func(serv service.Service) {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				runErr := serv.RunQueuedJobs(ctx)
+				if runErr != nil {
+					log.Error().Err(runErr).Str("site", serv.Name()).Msg("run queued jobs failed")
 				}
-			}(serv)
-		}
 
+				select {
+				case <-ctx.Done():
+				case <-time.After(interval):
+				}
+			}
+		}(serv)
+	}
+
+	done := make(chan struct{})
+	go func() {
 		wg.Wait()
-		log.Log().Msg("completed regular batch process")
+		close(done)
+	}()
+
+	return done
+}
+
+// scheduleEntries build the scheduled operations of all services. site
+// without schedules configured runs the whole process on default schedule
+func scheduleEntries(services map[string]service.Service, siteConfs map[string]config.SiteConfig) ([]schedule.Entry, error) {
+	var entries []schedule.Entry
+
+	for name, serv := range services {
+		if len(siteConfs[name].ScheduleConfig) == 0 {
+			log.Warn().
+				Str("site", name).
+				Str("default_schedule", config.DefaultProcessSchedule).
+				Msg("schedules not configured, run process on default schedule")
+		}
+
+		for operation, expr := range siteConfs[name].ScheduleConfig.WithDefaults() {
+			cron, err := schedule.Parse(expr)
+			if err != nil {
+				return nil, fmt.Errorf("parse schedule of %s %s: %w", name, operation, err)
+			}
+
+			operation, serv := operation, serv
+			entries = append(entries, schedule.Entry{
+				Site:      name,
+				Operation: operation,
+				Cron:      cron,
+				Run: func(ctx context.Context) error {
+					err := serv.RunOperation(ctx, operation)
+					if errors.Is(err, service.ErrOperationRunning) {
+						return fmt.Errorf("%w: %w", schedule.ErrSkipped, err)
+					}
+
+					return err
+				},
+			})
+		}
 	}
+
+	return entries, nil
 }
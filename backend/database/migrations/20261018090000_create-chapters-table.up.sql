@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS chapters (
+  site character varying(15) NOT NULL,
+  book_id integer NOT NULL,
+  hash_code integer NOT NULL,
+  chapter_index integer NOT NULL,
+  url text NOT NULL,
+  title text NOT NULL DEFAULT '',
+  content text NOT NULL DEFAULT '',
+  fetched_at timestamp with time zone,
+  error text NOT NULL DEFAULT '',
+  PRIMARY KEY (site, book_id, hash_code, chapter_index)
+);
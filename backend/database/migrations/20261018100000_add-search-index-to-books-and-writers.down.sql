@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS books__normalized_title_trgm;
+DROP INDEX IF EXISTS writers__normalized_name_trgm;
+
+ALTER TABLE books DROP COLUMN IF EXISTS normalized_title;
+ALTER TABLE writers DROP COLUMN IF EXISTS normalized_name;
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS normalized_title text;
+ALTER TABLE writers ADD COLUMN IF NOT EXISTS normalized_name text;
+
+-- traditional chinese cannot be simplified in sql, run cmd/patch-search-text
+-- after this migration to normalize existing records with the application
+UPDATE books SET normalized_title=lower(replace(title, ' ', '')) WHERE normalized_title IS NULL;
+UPDATE writers SET normalized_name=lower(replace(name, ' ', '')) WHERE normalized_name IS NULL;
+
+CREATE INDEX IF NOT EXISTS books__normalized_title_trgm ON books USING gin (normalized_title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS writers__normalized_name_trgm ON writers USING gin (normalized_name gin_trgm_ops);
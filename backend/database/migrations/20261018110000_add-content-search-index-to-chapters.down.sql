@@ -0,0 +1 @@
+DROP INDEX IF EXISTS chapters__content_trgm;
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS chapters__content_trgm ON chapters USING gin (content gin_trgm_ops);
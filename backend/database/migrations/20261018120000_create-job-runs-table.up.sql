@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+  id serial PRIMARY KEY,
+  site character varying(15) NOT NULL,
+  operation character varying(31) NOT NULL,
+  started_at timestamp with time zone NOT NULL,
+  ended_at timestamp with time zone,
+  outcome character varying(15) NOT NULL,
+  error text NOT NULL DEFAULT '',
+  stats jsonb NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS job_runs__site_operation_started_at ON job_runs (site, operation, started_at DESC);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS jobs (
+  id serial PRIMARY KEY,
+  site character varying(15) NOT NULL,
+  operation character varying(31) NOT NULL,
+  book_id integer,
+  hash_code integer,
+  status character varying(15) NOT NULL,
+  error text NOT NULL DEFAULT '',
+  stats jsonb NOT NULL DEFAULT '{}',
+  created_at timestamp with time zone NOT NULL,
+  started_at timestamp with time zone,
+  ended_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS jobs__site_status ON jobs (site, status, id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS book_tasks (
+  id serial PRIMARY KEY,
+  site character varying(15) NOT NULL,
+  operation character varying(31) NOT NULL,
+  book_id integer NOT NULL,
+  hash_code integer NOT NULL,
+  status character varying(15) NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  available_at timestamp with time zone NOT NULL,
+  lease_owner text NOT NULL DEFAULT '',
+  lease_expires_at timestamp with time zone,
+  error text NOT NULL DEFAULT '',
+  updated_at timestamp with time zone NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS book_tasks__book ON book_tasks (site, operation, book_id, hash_code);
+CREATE INDEX IF NOT EXISTS book_tasks__site_operation_status ON book_tasks (site, operation, status, id);
@@ -0,0 +1,3 @@
+-- books of the same work are looked up across all sites by their checksums
+CREATE INDEX IF NOT EXISTS books__work ON books (checksum, writer_checksum, site, id, hash_code desc)
+WHERE checksum != '' AND writer_checksum != '';
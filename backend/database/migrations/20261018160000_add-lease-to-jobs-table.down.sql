@@ -0,0 +1,3 @@
+ALTER TABLE jobs DROP COLUMN IF EXISTS lease_expires_at;
+ALTER TABLE jobs DROP COLUMN IF EXISTS lease_owner;
+ALTER TABLE jobs DROP COLUMN IF EXISTS attempts;
@@ -1,23 +1,23 @@
 -- name: CreateBookWithZeroHash :one
 INSERT INTO books
 (site, id, hash_code, title, writer_id, writer_checksum, type, 
-update_date, update_chapter, status, is_downloaded, checksum)
+update_date, update_chapter, status, is_downloaded, checksum, normalized_title)
 VALUES
-($1, $2, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+($1, $2, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: CreateBookWithHash :one
 INSERT INTO books
 (site, id, hash_code, title, writer_id, writer_checksum, type, 
-update_date, update_chapter, status, is_downloaded, checksum)
+update_date, update_chapter, status, is_downloaded, checksum, normalized_title)
 VALUES
-($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 RETURNING *;
 
 -- name: UpdateBook :one
 Update books SET 
 title=$4, writer_id=$5, writer_checksum=$12, type=$6, update_date=$7, update_chapter=$8,
-status=$9, is_downloaded=$10, checksum=$11
+status=$9, is_downloaded=$10, checksum=$11, normalized_title=$13
 WHERE site=$1 and id=$2 and hash_code=$3
 RETURNING *;
 
@@ -81,17 +81,38 @@ from books left join writers on books.writer_id=writers.id
 where books.site=$1 and books.status='END' and books.is_downloaded=false
 order by books.site, books.id desc, books.hash_code desc;
 
--- name: ListBooksByTitleWriter :many
+-- name: ListDownloadedBooksWithoutChapters :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
   books.status, books.is_downloaded, coalesce(errors.data, '')
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.is_downloaded=true and not exists (
+  select 1 from chapters where chapters.site=books.site
+    and chapters.book_id=books.id and chapters.hash_code=books.hash_code
+)
+order by books.id desc, books.hash_code desc;
+
+-- name: SearchBooksByTitleWriter :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  cast(
+    similarity(coalesce(books.normalized_title, ''), sqlc.arg(title)::text) +
+    similarity(coalesce(writers.normalized_name, ''), sqlc.arg(writer)::text)
+  as real) as relevance
 from books left join writers on books.writer_id=writers.id
   left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1 and books.status != 'ERROR' and 
-  (($2 != '%%' and books.title like $2) or
-  ($3 != '%%' and writers.name like $3))
-order by books.update_date desc, books.id desc limit $4 offset $5;
+where books.site=sqlc.arg(site) and books.status != 'ERROR' and (
+  (sqlc.arg(title)::text != '' and
+    books.normalized_title like '%' || sqlc.arg(title_pattern)::text || '%' escape '\') or
+  (sqlc.arg(writer)::text != '' and
+    writers.normalized_name like '%' || sqlc.arg(writer_pattern)::text || '%' escape '\')
+)
+order by relevance desc, books.update_date desc, books.id desc
+limit sqlc.arg(limit_count) offset sqlc.arg(offset_count);
 
 -- name: ListRandomBooks :many
 select books.site, books.id, books.hash_code, books.title,
@@ -138,6 +159,18 @@ where (books.checksum, books.writer_checksum) = (
   order by bks.hash_code desc limit 1
 ) or books.site=$1 and books.id=$2;
 
+-- name: ListBooksByChecksum :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, '')
+from books
+  left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.checksum=$1 and books.writer_checksum=$2
+  and books.checksum != '' and books.writer_checksum != ''
+order by books.site, books.id, books.hash_code desc;
+
 -- name: UpdateBooksStatus :exec
 update books set is_downloaded=false, status='END' 
 where (update_date < $1 or 
@@ -161,8 +194,8 @@ where (update_date < $1 or
   status='INPROGRESS' and site=$2;
 
 -- name: CreateWriter :one
-insert into writers (name, checksum) values ($1, $2) 
-on conflict (name) do update set name=$1 
+insert into writers (name, checksum, normalized_name) values ($1, $2, $3) 
+on conflict (name) do update set name=$1, normalized_name=$3 
 returning *;
 
 -- name: CreateError :one
@@ -209,3 +242,175 @@ from books where site=$1;
 
 -- name: FindAllBookIDs :many
 select distinct(id) as book_id from books where site=$1 order by book_id;
+
+-- name: SaveChapter :exec
+insert into chapters
+(site, book_id, hash_code, chapter_index, url, title, content, fetched_at, error)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+on conflict (site, book_id, hash_code, chapter_index)
+do update set url=$5, title=$6, content=$7, fetched_at=$8, error=$9;
+
+-- name: ListChaptersByBook :many
+select * from chapters
+where site=$1 and book_id=$2 and hash_code=$3
+order by chapter_index;
+
+-- name: GetChapter :one
+select * from chapters
+where site=$1 and book_id=$2 and hash_code=$3 and chapter_index=$4;
+
+-- name: DeleteChaptersByBook :exec
+delete from chapters where site=$1 and book_id=$2 and hash_code=$3;
+
+-- name: SearchChapterContent :many
+select chapters.site, chapters.book_id, chapters.hash_code,
+  books.title as book_title, books.writer_id, coalesce(writers.name, '') as writer_name,
+  books.type, books.update_date, books.update_chapter,
+  books.status, books.is_downloaded,
+  chapters.chapter_index, chapters.title as chapter_title, chapters.content
+from chapters join books on chapters.site=books.site
+  and chapters.book_id=books.id and chapters.hash_code=books.hash_code
+  left join writers on books.writer_id=writers.id
+where chapters.site=sqlc.arg(site) and chapters.error=''
+  and chapters.content like '%' || sqlc.arg(keyword)::text || '%' escape '\'
+order by chapters.book_id desc, chapters.hash_code desc, chapters.chapter_index
+limit sqlc.arg(limit_count) offset sqlc.arg(offset_count);
+
+-- name: CreateJobRun :one
+insert into job_runs (site, operation, started_at, outcome)
+values ($1, $2, $3, $4)
+returning id;
+
+-- name: FinishJobRun :exec
+update job_runs set ended_at=$2, outcome=$3, error=$4, stats=$5
+where id=$1;
+
+-- name: ListJobRuns :many
+select * from job_runs
+where site=sqlc.arg(site)
+  and (sqlc.arg(operation)::text='' or operation=sqlc.arg(operation)::text)
+order by started_at desc, id desc
+limit sqlc.arg(limit_count) offset sqlc.arg(offset_count);
+
+-- name: GetJobRun :one
+select * from job_runs where site=$1 and id=$2;
+
+-- name: CreateJob :one
+insert into jobs (site, operation, book_id, hash_code, status, created_at)
+values ($1, $2, $3, $4, $5, $6)
+returning id;
+
+-- name: ClaimJob :one
+update jobs set status='running', started_at=sqlc.arg(started_at),
+  attempts=attempts+1, lease_owner=sqlc.arg(lease_owner),
+  lease_expires_at=now() + sqlc.arg(lease_seconds)::integer * interval '1 second'
+where id=(
+  select id from jobs where site=sqlc.arg(site) and (
+    status='queued' or (status='running' and lease_expires_at<now())
+  )
+  order by id limit 1
+  for update skip locked
+)
+returning *;
+
+-- name: RenewJobLease :execrows
+update jobs
+set lease_expires_at=now() + sqlc.arg(lease_seconds)::integer * interval '1 second'
+where id=sqlc.arg(id) and status='running' and lease_owner=sqlc.arg(lease_owner);
+
+-- name: FinishJob :execrows
+update jobs set status=$2, error=$3, stats=$4, ended_at=$5, attempts=$7,
+  lease_owner='', lease_expires_at=null
+where id=$1 and status='running' and lease_owner=$6;
+
+-- name: GetJob :one
+select * from jobs where site=$1 and id=$2;
+
+-- name: EnqueueUpdateBookTasks :execrows
+insert into book_tasks (site, operation, book_id, hash_code, status, available_at, updated_at)
+select distinct on (books.site, books.id)
+  books.site, 'update', books.id, books.hash_code, 'queued', now(), now()
+from books
+where books.site=$1
+order by books.site, books.id desc, books.hash_code desc
+on conflict (site, operation, book_id, hash_code)
+do update set status='queued', attempts=0, available_at=now(),
+  lease_owner='', lease_expires_at=null, error='', updated_at=now()
+where book_tasks.status='done';
+
+-- name: EnqueueDownloadBookTasks :execrows
+insert into book_tasks (site, operation, book_id, hash_code, status, available_at, updated_at)
+select distinct on (books.site, books.id)
+  books.site, 'download', books.id, books.hash_code, 'queued', now(), now()
+from books
+where books.site=sqlc.arg(site) and (
+  (books.status='END' and books.is_downloaded=false)
+  or (sqlc.arg(include_in_progress)::boolean and books.status='INPROGRESS')
+)
+order by books.site, books.id desc, books.hash_code desc
+on conflict (site, operation, book_id, hash_code)
+do update set status='queued', attempts=0, available_at=now(),
+  lease_owner='', lease_expires_at=null, error='', updated_at=now()
+where book_tasks.status='done';
+
+-- name: LeaseBookTask :one
+update book_tasks set status='leased', attempts=attempts+1,
+  lease_owner=sqlc.arg(lease_owner),
+  lease_expires_at=now() + sqlc.arg(lease_seconds)::integer * interval '1 second',
+  updated_at=now()
+where id=(
+  select id from book_tasks
+  where site=sqlc.arg(site) and operation=sqlc.arg(operation) and (
+    (status='queued' and available_at<=now())
+    or (status='leased' and lease_expires_at<now())
+  )
+  order by id limit 1
+  for update skip locked
+)
+returning *;
+
+-- name: RenewBookTaskLease :execrows
+update book_tasks
+set lease_expires_at=now() + sqlc.arg(lease_seconds)::integer * interval '1 second',
+  updated_at=now()
+where id=sqlc.arg(id) and status='leased' and lease_owner=sqlc.arg(lease_owner);
+
+-- name: CompleteBookTask :execrows
+update book_tasks
+set status='done', error='', lease_owner='', lease_expires_at=null, updated_at=now()
+where id=$1 and status='leased' and lease_owner=$2;
+
+-- name: FailBookTask :execrows
+update book_tasks
+set status=sqlc.arg(status), attempts=sqlc.arg(attempts), error=sqlc.arg(error),
+  available_at=now() + sqlc.arg(retry_seconds)::integer * interval '1 second',
+  lease_owner='', lease_expires_at=null, updated_at=now()
+where id=sqlc.arg(id) and status='leased' and lease_owner=sqlc.arg(lease_owner);
+
+-- name: ListBookTasks :many
+select * from book_tasks
+where site=sqlc.arg(site)
+  and (sqlc.arg(operation)::text='' or operation=sqlc.arg(operation)::text)
+  and (sqlc.arg(status)::text='' or status=sqlc.arg(status)::text)
+order by updated_at desc, id desc
+limit sqlc.arg(limit_count) offset sqlc.arg(offset_count);
+
+-- name: RequeueBookTask :one
+update book_tasks
+set status='queued', attempts=0, available_at=now(), error='', updated_at=now()
+where site=$1 and id=$2 and status='dead'
+returning *;
+
+-- name: DeleteFinishedBookTasks :execrows
+delete from book_tasks
+where site=$1 and status='done' and updated_at<$2;
+
+-- name: TryLockOperation :one
+select pg_try_advisory_lock(
+  hashtext(sqlc.arg(site)::text), hashtext(sqlc.arg(operation)::text)
+) as locked;
+
+-- name: UnlockOperation :one
+select pg_advisory_unlock(
+  hashtext(sqlc.arg(site)::text), hashtext(sqlc.arg(operation)::text)
+) as unlocked;
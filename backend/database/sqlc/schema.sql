@@ -16,6 +16,20 @@ SET xmloption = content;
 SET client_min_messages = warning;
 SET row_security = off;
 
+--
+-- Name: pg_trgm; Type: EXTENSION; Schema: -; Owner: -
+--
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm WITH SCHEMA public;
+
+
+--
+-- Name: EXTENSION pg_trgm; Type: COMMENT; Schema: -; Owner: 
+--
+
+COMMENT ON EXTENSION pg_trgm IS 'text similarity measurement and index searching based on trigrams';
+
+
 SET default_tablespace = '';
 
 SET default_table_access_method = heap;
@@ -36,12 +50,76 @@ CREATE TABLE public.books (
     status character varying(10) NOT NULL,
     is_downloaded boolean DEFAULT false NOT NULL,
     checksum text,
-    writer_checksum text
+    writer_checksum text,
+    normalized_title text
 );
 
 
 ALTER TABLE public.books OWNER TO test;
 
+--
+-- Name: book_tasks; Type: TABLE; Schema: public; Owner: test
+--
+
+CREATE TABLE public.book_tasks (
+    id integer NOT NULL,
+    site character varying(15) NOT NULL,
+    operation character varying(31) NOT NULL,
+    book_id integer NOT NULL,
+    hash_code integer NOT NULL,
+    status character varying(15) NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    available_at timestamp with time zone NOT NULL,
+    lease_owner text DEFAULT ''::text NOT NULL,
+    lease_expires_at timestamp with time zone,
+    error text DEFAULT ''::text NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+
+ALTER TABLE public.book_tasks OWNER TO test;
+
+--
+-- Name: book_tasks_id_seq; Type: SEQUENCE; Schema: public; Owner: test
+--
+
+CREATE SEQUENCE public.book_tasks_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE public.book_tasks_id_seq OWNER TO test;
+
+--
+-- Name: book_tasks_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: test
+--
+
+ALTER SEQUENCE public.book_tasks_id_seq OWNED BY public.book_tasks.id;
+
+
+--
+-- Name: chapters; Type: TABLE; Schema: public; Owner: test
+--
+
+CREATE TABLE public.chapters (
+    site character varying(15) NOT NULL,
+    book_id integer NOT NULL,
+    hash_code integer NOT NULL,
+    chapter_index integer NOT NULL,
+    url text NOT NULL,
+    title text DEFAULT ''::text NOT NULL,
+    content text DEFAULT ''::text NOT NULL,
+    fetched_at timestamp with time zone,
+    error text DEFAULT ''::text NOT NULL
+);
+
+
+ALTER TABLE public.chapters OWNER TO test;
+
 --
 -- Name: errors; Type: TABLE; Schema: public; Owner: test
 --
@@ -55,6 +133,91 @@ CREATE TABLE public.errors (
 
 ALTER TABLE public.errors OWNER TO test;
 
+--
+-- Name: job_runs; Type: TABLE; Schema: public; Owner: test
+--
+
+CREATE TABLE public.job_runs (
+    id integer NOT NULL,
+    site character varying(15) NOT NULL,
+    operation character varying(31) NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone,
+    outcome character varying(15) NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    stats jsonb DEFAULT '{}'::jsonb NOT NULL
+);
+
+
+ALTER TABLE public.job_runs OWNER TO test;
+
+--
+-- Name: job_runs_id_seq; Type: SEQUENCE; Schema: public; Owner: test
+--
+
+CREATE SEQUENCE public.job_runs_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE public.job_runs_id_seq OWNER TO test;
+
+--
+-- Name: job_runs_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: test
+--
+
+ALTER SEQUENCE public.job_runs_id_seq OWNED BY public.job_runs.id;
+
+--
+-- Name: jobs; Type: TABLE; Schema: public; Owner: test
+--
+
+CREATE TABLE public.jobs (
+    id integer NOT NULL,
+    site character varying(15) NOT NULL,
+    operation character varying(31) NOT NULL,
+    book_id integer,
+    hash_code integer,
+    status character varying(15) NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    stats jsonb DEFAULT '{}'::jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    started_at timestamp with time zone,
+    ended_at timestamp with time zone,
+    attempts integer DEFAULT 0 NOT NULL,
+    lease_owner text DEFAULT ''::text NOT NULL,
+    lease_expires_at timestamp with time zone
+);
+
+
+ALTER TABLE public.jobs OWNER TO test;
+
+--
+-- Name: jobs_id_seq; Type: SEQUENCE; Schema: public; Owner: test
+--
+
+CREATE SEQUENCE public.jobs_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE public.jobs_id_seq OWNER TO test;
+
+--
+-- Name: jobs_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: test
+--
+
+ALTER SEQUENCE public.jobs_id_seq OWNED BY public.jobs.id;
+
+
 --
 -- Name: writers; Type: TABLE; Schema: public; Owner: test
 --
@@ -62,7 +225,8 @@ ALTER TABLE public.errors OWNER TO test;
 CREATE TABLE public.writers (
     id integer NOT NULL,
     name text,
-    checksum text
+    checksum text,
+    normalized_name text
 );
 
 
@@ -90,6 +254,27 @@ ALTER TABLE public.writers_id_seq OWNER TO test;
 ALTER SEQUENCE public.writers_id_seq OWNED BY public.writers.id;
 
 
+--
+-- Name: book_tasks id; Type: DEFAULT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.book_tasks ALTER COLUMN id SET DEFAULT nextval('public.book_tasks_id_seq'::regclass);
+
+
+--
+-- Name: job_runs id; Type: DEFAULT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.job_runs ALTER COLUMN id SET DEFAULT nextval('public.job_runs_id_seq'::regclass);
+
+
+--
+-- Name: jobs id; Type: DEFAULT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.jobs ALTER COLUMN id SET DEFAULT nextval('public.jobs_id_seq'::regclass);
+
+
 --
 -- Name: writers id; Type: DEFAULT; Schema: public; Owner: test
 --
@@ -97,6 +282,38 @@ ALTER SEQUENCE public.writers_id_seq OWNED BY public.writers.id;
 ALTER TABLE ONLY public.writers ALTER COLUMN id SET DEFAULT nextval('public.writers_id_seq'::regclass);
 
 
+--
+-- Name: book_tasks book_tasks_pkey; Type: CONSTRAINT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.book_tasks
+    ADD CONSTRAINT book_tasks_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: chapters chapters_pkey; Type: CONSTRAINT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.chapters
+    ADD CONSTRAINT chapters_pkey PRIMARY KEY (site, book_id, hash_code, chapter_index);
+
+
+--
+-- Name: job_runs job_runs_pkey; Type: CONSTRAINT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.job_runs
+    ADD CONSTRAINT job_runs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: jobs jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: test
+--
+
+ALTER TABLE ONLY public.jobs
+    ADD CONSTRAINT jobs_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: writers writers_pkey; Type: CONSTRAINT; Schema: public; Owner: test
 --
@@ -105,6 +322,20 @@ ALTER TABLE ONLY public.writers
     ADD CONSTRAINT writers_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: book_tasks__book; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE UNIQUE INDEX book_tasks__book ON public.book_tasks USING btree (site, operation, book_id, hash_code);
+
+
+--
+-- Name: book_tasks__site_operation_status; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX book_tasks__site_operation_status ON public.book_tasks USING btree (site, operation, status, id);
+
+
 --
 -- Name: books__checksum; Type: INDEX; Schema: public; Owner: test
 --
@@ -112,6 +343,13 @@ ALTER TABLE ONLY public.writers
 CREATE INDEX books__checksum ON public.books USING btree (checksum, writer_checksum);
 
 
+--
+-- Name: books__normalized_title_trgm; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX books__normalized_title_trgm ON public.books USING gin (normalized_title public.gin_trgm_ops);
+
+
 --
 -- Name: books__status; Type: INDEX; Schema: public; Owner: test
 --
@@ -126,6 +364,13 @@ CREATE INDEX books__status ON public.books USING btree (status, is_downloaded);
 CREATE INDEX books__vendor_reference ON public.books USING btree (site, id, hash_code DESC);
 
 
+--
+-- Name: books__work; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX books__work ON public.books USING btree (checksum, writer_checksum, site, id, hash_code DESC) WHERE ((checksum <> ''::text) AND (writer_checksum <> ''::text));
+
+
 --
 -- Name: books_index; Type: INDEX; Schema: public; Owner: test
 --
@@ -161,6 +406,13 @@ CREATE INDEX books_writer ON public.books USING btree (writer_id);
 CREATE INDEX books_writer_checksum ON public.books USING btree (writer_checksum);
 
 
+--
+-- Name: chapters__content_trgm; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX chapters__content_trgm ON public.chapters USING gin (content public.gin_trgm_ops);
+
+
 --
 -- Name: checksum_index; Type: INDEX; Schema: public; Owner: test
 --
@@ -175,6 +427,20 @@ CREATE INDEX checksum_index ON public.books USING btree (checksum);
 CREATE UNIQUE INDEX errors_index ON public.errors USING btree (site, id);
 
 
+--
+-- Name: job_runs__site_operation_started_at; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX job_runs__site_operation_started_at ON public.job_runs USING btree (site, operation, started_at DESC);
+
+
+--
+-- Name: jobs__site_status; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX jobs__site_status ON public.jobs USING btree (site, status, id);
+
+
 --
 -- Name: writers__name; Type: INDEX; Schema: public; Owner: test
 --
@@ -182,6 +448,13 @@ CREATE UNIQUE INDEX errors_index ON public.errors USING btree (site, id);
 CREATE INDEX writers__name ON public.writers USING btree (name);
 
 
+--
+-- Name: writers__normalized_name_trgm; Type: INDEX; Schema: public; Owner: test
+--
+
+CREATE INDEX writers__normalized_name_trgm ON public.writers USING gin (normalized_name public.gin_trgm_ops);
+
+
 --
 -- Name: writers_checksum_index; Type: INDEX; Schema: public; Owner: test
 --
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/rs/zerolog"
+)
+
+type CacheClient struct {
+	conf   *CacheClientConfig
+	client client.BookClient
+	store  *diskStore
+
+	// unix nano of the last cleanup, cleanup is skipped if it is already
+	// running
+	lastCleanup atomic.Int64
+	cleaning    atomic.Bool
+}
+
+var _ client.BookClient = (*CacheClient)(nil)
+
+func NewClient(conf *CacheClientConfig, bookClient client.BookClient) *CacheClient {
+	return &CacheClient{
+		conf:   conf,
+		client: bookClient,
+		store:  &diskStore{dir: conf.Dir},
+	}
+}
+
+func (c *CacheClient) ttl(class client.URLClass) time.Duration {
+	switch class {
+	case client.URLClassBook:
+		return c.conf.BookTTL
+	case client.URLClassChapterList:
+		return c.conf.ChapterListTTL
+	case client.URLClassChapter:
+		return c.conf.ChapterTTL
+	default:
+		return 0
+	}
+}
+
+func (c *CacheClient) Get(ctx context.Context, url string) (string, error) {
+	ttl := c.ttl(client.URLClassFromContext(ctx))
+	if c.conf.Dir == "" || ttl <= 0 {
+		return c.client.Get(ctx, url)
+	}
+
+	logger := zerolog.Ctx(ctx).With().
+		Str("client", "CacheClient").
+		Str("url", url).
+		Logger()
+
+	cached, err := c.store.get(url)
+	if err != nil {
+		logger.Warn().Err(err).Msg("get cache failed")
+	}
+
+	if cached != nil && !c.conf.Bypass && time.Since(cached.StoredAt) < ttl {
+		logger.Debug().Msg("cache hit")
+		fillResponseInfo(ctx, url)
+
+		return cached.Body, nil
+	}
+
+	validators := &client.Validators{}
+	if cached != nil {
+		validators.ETag = cached.ETag
+		validators.LastModified = cached.LastModified
+	}
+
+	body, err := c.client.Get(client.WithValidators(ctx, validators), url)
+	if errors.Is(err, client.ErrNotModified) && cached != nil {
+		logger.Debug().Msg("cache revalidated")
+
+		cached.StoredAt = time.Now()
+		if err := c.store.set(cached); err != nil {
+			logger.Warn().Err(err).Msg("set cache failed")
+		}
+		fillResponseInfo(ctx, url)
+
+		return cached.Body, nil
+	} else if err != nil {
+		return body, err
+	}
+
+	if err := c.store.set(&entry{
+		URL:          url,
+		Body:         body,
+		ETag:         validators.ETag,
+		LastModified: validators.LastModified,
+		StoredAt:     time.Now(),
+	}); err != nil {
+		logger.Warn().Err(err).Msg("set cache failed")
+	}
+
+	c.cleanup(logger)
+
+	return body, nil
+}
+
+// ReportParseResult evict the cached page of url if it cannot be parsed, so
+// anti-bot or placeholder page returned with success status is fetched again
+// by next request instead of being served until it expires
+func (c *CacheClient) ReportParseResult(ctx context.Context, url string, err error) {
+	if err == nil || c.conf.Dir == "" {
+		return
+	}
+
+	if err := c.store.delete(url); err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).
+			Str("client", "CacheClient").
+			Str("url", url).
+			Msg("evict cache failed")
+	}
+}
+
+// cleanup remove the expired entries and keep the cache within max size in
+// background, at most once per cleanup interval
+func (c *CacheClient) cleanup(logger zerolog.Logger) {
+	now := time.Now()
+	if now.Sub(time.Unix(0, c.lastCleanup.Load())) < c.conf.cleanupInterval() {
+		return
+	}
+
+	if !c.cleaning.CompareAndSwap(false, true) {
+		return
+	}
+	c.lastCleanup.Store(now.UnixNano())
+
+	go func() {
+		defer c.cleaning.Store(false)
+
+		removed, err := c.store.cleanup(now, c.conf.maxAge(), c.conf.MaxSize)
+		if err != nil {
+			logger.Warn().Err(err).Int("removed", removed).Msg("cleanup cache failed")
+		} else {
+			logger.Debug().Int("removed", removed).Msg("cleanup cache completed")
+		}
+	}()
+}
+
+// fillResponseInfo describe the response served from cache as a plain 200
+// response of url
+func fillResponseInfo(ctx context.Context, url string) {
+	if info := client.ResponseInfoFromContext(ctx); info != nil {
+		info.StatusCode, info.URL, info.Raw = http.StatusOK, url, nil
+		info.FromCache = true
+		if info.Header == nil {
+			info.Header = http.Header{}
+		}
+	}
+}
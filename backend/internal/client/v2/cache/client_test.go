@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	conf := &CacheClientConfig{Dir: "/cache"}
+
+	got := NewClient(conf, nil)
+	assert.Equal(t, &CacheClient{
+		conf:  conf,
+		store: &diskStore{dir: "/cache"},
+	}, got)
+}
+
+func TestCacheClient_Get(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://test.com/book/1"
+
+	tests := []struct {
+		name         string
+		conf         *CacheClientConfig
+		cached       *entry
+		setupClient  func(*gomock.Controller) client.BookClient
+		class        client.URLClass
+		want         string
+		wantError    error
+		wantCached   *entry
+		wantNotFresh bool
+	}{
+		{
+			name: "pass through if cache dir is empty",
+			conf: &CacheClientConfig{BookTTL: time.Hour},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("body", nil)
+
+				return cli
+			},
+			class: client.URLClassBook,
+			want:  "body",
+		},
+		{
+			name: "pass through if url class has no ttl",
+			conf: &CacheClientConfig{BookTTL: time.Hour},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("body", nil)
+
+				return cli
+			},
+			class: client.URLClassUnknown,
+			want:  "body",
+		},
+		{
+			name:   "return fresh cached body",
+			conf:   &CacheClientConfig{BookTTL: time.Hour},
+			cached: &entry{URL: url, Body: "cached", StoredAt: time.Now()},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				return mockclient.NewMockBookClient(ctrl)
+			},
+			class:      client.URLClassBook,
+			want:       "cached",
+			wantCached: &entry{URL: url, Body: "cached"},
+		},
+		{
+			name: "fetch and store body if not cached",
+			conf: &CacheClientConfig{ChapterTTL: time.Hour},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).DoAndReturn(
+					func(ctx context.Context, _ string) (string, error) {
+						validators := client.ValidatorsFromContext(ctx)
+						validators.ETag = `"etag"`
+
+						return "body", nil
+					},
+				)
+
+				return cli
+			},
+			class:      client.URLClassChapter,
+			want:       "body",
+			wantCached: &entry{URL: url, Body: "body", ETag: `"etag"`},
+		},
+		{
+			name:   "fetch body if bypass is enabled",
+			conf:   &CacheClientConfig{BookTTL: time.Hour, Bypass: true},
+			cached: &entry{URL: url, Body: "cached", StoredAt: time.Now()},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("body", nil)
+
+				return cli
+			},
+			class:      client.URLClassBook,
+			want:       "body",
+			wantCached: &entry{URL: url, Body: "body"},
+		},
+		{
+			name: "revalidate stale cached body",
+			conf: &CacheClientConfig{ChapterListTTL: time.Hour},
+			cached: &entry{
+				URL: url, Body: "cached", LastModified: "Sat, 17 Oct 2026 00:00:00 GMT",
+				StoredAt: time.Now().Add(-2 * time.Hour),
+			},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).DoAndReturn(
+					func(ctx context.Context, _ string) (string, error) {
+						validators := client.ValidatorsFromContext(ctx)
+						if validators.LastModified != "Sat, 17 Oct 2026 00:00:00 GMT" {
+							return "", errors.New("missing validators")
+						}
+
+						return "", client.ErrNotModified
+					},
+				)
+
+				return cli
+			},
+			class:      client.URLClassChapterList,
+			want:       "cached",
+			wantCached: &entry{URL: url, Body: "cached", LastModified: "Sat, 17 Oct 2026 00:00:00 GMT"},
+		},
+		{
+			name: "keep stale cached body if request failed",
+			conf: &CacheClientConfig{BookTTL: time.Hour},
+			cached: &entry{
+				URL: url, Body: "cached", ETag: `"etag"`,
+				StoredAt: time.Now().Add(-2 * time.Hour),
+			},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("", client.ErrTimeout)
+
+				return cli
+			},
+			class:        client.URLClassBook,
+			want:         "",
+			wantError:    client.ErrTimeout,
+			wantCached:   &entry{URL: url, Body: "cached", ETag: `"etag"`},
+			wantNotFresh: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			if test.cached != nil || test.wantCached != nil {
+				test.conf.Dir = t.TempDir()
+			}
+			cli := NewClient(test.conf, test.setupClient(ctrl))
+			if test.cached != nil {
+				assert.NoError(t, cli.store.set(test.cached))
+			}
+
+			got, err := cli.Get(client.WithURLClass(context.Background(), test.class), url)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+
+			if test.wantCached != nil {
+				cached, err := cli.store.get(url)
+				assert.NoError(t, err)
+				assert.Equal(t, !test.wantNotFresh, time.Since(cached.StoredAt) < time.Minute)
+				cached.StoredAt = time.Time{}
+				assert.Equal(t, test.wantCached, cached)
+			}
+		})
+	}
+}
+
+func TestCacheClient_Get_ResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://test.com/book/1"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cli := NewClient(
+		&CacheClientConfig{Dir: t.TempDir(), BookTTL: time.Hour},
+		mockclient.NewMockBookClient(ctrl),
+	)
+	assert.NoError(t, cli.store.set(&entry{URL: url, Body: "cached", StoredAt: time.Now()}))
+
+	got, err := client.GetResponse(client.WithURLClass(context.Background(), client.URLClassBook), cli, url)
+	assert.NoError(t, err)
+	assert.Equal(t, "cached", got.Body)
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+	assert.Equal(t, url, got.URL)
+	assert.Nil(t, got.Raw)
+	assert.True(t, got.FromCache)
+}
+
+func TestCacheClient_Get_Cleanup(t *testing.T) {
+	t.Parallel()
+
+	const (
+		url        = "https://test.com/book/1"
+		expiredURL = "https://test.com/book/2"
+	)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookClient := mockclient.NewMockBookClient(ctrl)
+	bookClient.EXPECT().Get(gomock.Any(), url).Return("body", nil)
+
+	cli := NewClient(&CacheClientConfig{Dir: t.TempDir(), BookTTL: time.Hour}, bookClient)
+	expiredAt := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, cli.store.set(&entry{URL: expiredURL, Body: "expired", StoredAt: expiredAt}))
+	assert.NoError(t, os.Chtimes(cli.store.path(expiredURL), expiredAt, expiredAt))
+
+	got, err := cli.Get(client.WithURLClass(context.Background(), client.URLClassBook), url)
+	assert.NoError(t, err)
+	assert.Equal(t, "body", got)
+
+	assert.Eventually(t, func() bool { return !cli.cleaning.Load() }, time.Second, 10*time.Millisecond)
+
+	expired, err := cli.store.get(expiredURL)
+	assert.NoError(t, err)
+	assert.Nil(t, expired)
+
+	cached, err := cli.store.get(url)
+	assert.NoError(t, err)
+	assert.NotNil(t, cached)
+}
+
+func TestCacheClient_ReportParseResult(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://test.com/book/1"
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCached bool
+	}{
+		{
+			name:       "evict cached page failed to be parsed",
+			err:        errors.New("some error"),
+			wantCached: false,
+		},
+		{
+			name:       "keep cached page parsed successfully",
+			err:        nil,
+			wantCached: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli := NewClient(&CacheClientConfig{Dir: t.TempDir(), BookTTL: time.Hour}, nil)
+			assert.NoError(t, cli.store.set(&entry{URL: url, Body: "anti bot", StoredAt: time.Now()}))
+
+			cli.ReportParseResult(context.Background(), url, test.err)
+
+			cached, err := cli.store.get(url)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantCached, cached != nil)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import "time"
+
+const defaultCleanupInterval = time.Hour
+
+// CacheClientConfig cache the responses on disk. the client does not cache
+// any response if Dir is empty, and does not cache the urls of a class with
+// zero ttl. Bypass skip the cached responses but still store the new ones.
+// entries not stored within MaxAge (the longest ttl if zero) are removed, and
+// the oldest entries are removed once the cache exceed MaxSize bytes (no
+// limit if zero). the cleanup runs once per CleanupInterval (1h if zero)
+type CacheClientConfig struct {
+	Dir             string        `yaml:"dir"`
+	Bypass          bool          `yaml:"bypass"`
+	BookTTL         time.Duration `yaml:"book_ttl" validate:"min=0"`
+	ChapterListTTL  time.Duration `yaml:"chapter_list_ttl" validate:"min=0"`
+	ChapterTTL      time.Duration `yaml:"chapter_ttl" validate:"min=0"`
+	MaxAge          time.Duration `yaml:"max_age" validate:"min=0"`
+	MaxSize         int64         `yaml:"max_size" validate:"min=0"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" validate:"min=0"`
+}
+
+func (conf *CacheClientConfig) maxAge() time.Duration {
+	if conf.MaxAge > 0 {
+		return conf.MaxAge
+	}
+
+	return max(conf.BookTTL, conf.ChapterListTTL, conf.ChapterTTL)
+}
+
+func (conf *CacheClientConfig) cleanupInterval() time.Duration {
+	if conf.CleanupInterval > 0 {
+		return conf.CleanupInterval
+	}
+
+	return defaultCleanupInterval
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validate_CacheClientConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  CacheClientConfig
+		valid bool
+	}{
+		{
+			name: "valid",
+			conf: CacheClientConfig{
+				Dir:            "/cache",
+				BookTTL:        time.Hour,
+				ChapterListTTL: time.Hour,
+				ChapterTTL:     24 * time.Hour,
+			},
+			valid: true,
+		},
+		{
+			name:  "valid with cache disabled",
+			conf:  CacheClientConfig{},
+			valid: true,
+		},
+		{
+			name:  "invalid book ttl",
+			conf:  CacheClientConfig{BookTTL: -1 * time.Second},
+			valid: false,
+		},
+		{
+			name:  "invalid chapter list ttl",
+			conf:  CacheClientConfig{ChapterListTTL: -1 * time.Second},
+			valid: false,
+		},
+		{
+			name:  "invalid chapter ttl",
+			conf:  CacheClientConfig{ChapterTTL: -1 * time.Second},
+			valid: false,
+		},
+		{
+			name:  "invalid max age",
+			conf:  CacheClientConfig{MaxAge: -1 * time.Second},
+			valid: false,
+		},
+		{
+			name:  "invalid max size",
+			conf:  CacheClientConfig{MaxSize: -1},
+			valid: false,
+		},
+		{
+			name:  "invalid cleanup interval",
+			conf:  CacheClientConfig{CleanupInterval: -1 * time.Second},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := validator.New().Struct(test.conf)
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
+
+func TestCacheClientConfig_maxAge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		conf CacheClientConfig
+		want time.Duration
+	}{
+		{
+			name: "use configured max age",
+			conf: CacheClientConfig{MaxAge: 2 * time.Hour, ChapterTTL: 720 * time.Hour},
+			want: 2 * time.Hour,
+		},
+		{
+			name: "default to longest ttl",
+			conf: CacheClientConfig{BookTTL: time.Hour, ChapterListTTL: 2 * time.Hour, ChapterTTL: 720 * time.Hour},
+			want: 720 * time.Hour,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.want, test.conf.maxAge())
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type entry struct {
+	URL          string    `json:"url"`
+	Body         string    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	StoredAt     time.Time `json:"stored_at"`
+}
+
+type diskStore struct {
+	dir string
+}
+
+func (s *diskStore) path(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(hash[:])
+
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
+
+// get return nil entry without error if the url is not cached
+func (s *diskStore) get(url string) (*entry, error) {
+	data, err := os.ReadFile(s.path(url))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read cache: %w", err)
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("decode cache: %w", err)
+	}
+
+	// different urls with same hash are treated as cache miss
+	if e.URL != url {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+// set write the entry to a temp file and rename it, so concurrent readers
+// never see a partially written entry
+func (s *diskStore) set(e *entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode cache: %w", err)
+	}
+
+	path := s.path(e.URL)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, writeErr := file.Write(data)
+	closeErr := file.Close()
+	if err := errors.Join(writeErr, closeErr); err != nil {
+		return fmt.Errorf("write cache: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("rename cache: %w", err)
+	}
+
+	return nil
+}
+
+// delete remove the entry of url, missing entry is not treated as error
+func (s *diskStore) delete(url string) error {
+	if err := os.Remove(s.path(url)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete cache: %w", err)
+	}
+
+	return nil
+}
+
+// cleanup remove the entries not stored within maxAge, then remove the oldest
+// entries until the total size is not larger than maxSize. the modified time
+// of the files is used, so revalidated entries are kept. zero maxAge or
+// maxSize disable the corresponding limit. it return the number of entries
+// removed
+func (s *diskStore) cleanup(now time.Time, maxAge time.Duration, maxSize int64) (int, error) {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var (
+		files   []file
+		total   int64
+		removed int
+	)
+
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if maxAge > 0 && now.Sub(info.ModTime()) > maxAge {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			removed++
+
+			return nil
+		}
+
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("cleanup cache: %w", err)
+	}
+
+	if maxSize <= 0 || total <= maxSize {
+		return removed, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	for _, f := range files {
+		if total <= maxSize {
+			break
+		}
+
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("cleanup cache: %w", err)
+		}
+		total -= f.size
+		removed++
+	}
+
+	return removed, nil
+}
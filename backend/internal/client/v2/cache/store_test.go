@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_diskStore(t *testing.T) {
+	t.Parallel()
+
+	store := &diskStore{dir: t.TempDir()}
+	storedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("return nil for missing entry", func(t *testing.T) {
+		got, err := store.get("https://test.com/missing")
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("return stored entry", func(t *testing.T) {
+		e := &entry{
+			URL:      "https://test.com/book/1",
+			Body:     "body",
+			ETag:     `"etag"`,
+			StoredAt: storedAt,
+		}
+		assert.NoError(t, store.set(e))
+
+		got, err := store.get("https://test.com/book/1")
+		assert.NoError(t, err)
+		assert.Equal(t, e, got)
+	})
+
+	t.Run("overwrite stored entry", func(t *testing.T) {
+		assert.NoError(t, store.set(&entry{URL: "https://test.com/book/2", Body: "old", StoredAt: storedAt}))
+		assert.NoError(t, store.set(&entry{URL: "https://test.com/book/2", Body: "new", StoredAt: storedAt}))
+
+		got, err := store.get("https://test.com/book/2")
+		assert.NoError(t, err)
+		assert.Equal(t, &entry{URL: "https://test.com/book/2", Body: "new", StoredAt: storedAt}, got)
+	})
+
+	t.Run("delete stored entry", func(t *testing.T) {
+		url := "https://test.com/book/4"
+		assert.NoError(t, store.set(&entry{URL: url, Body: "body", StoredAt: storedAt}))
+		assert.NoError(t, store.delete(url))
+
+		got, err := store.get(url)
+		assert.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("delete missing entry", func(t *testing.T) {
+		assert.NoError(t, store.delete("https://test.com/missing"))
+	})
+
+	t.Run("return error for corrupted entry", func(t *testing.T) {
+		url := "https://test.com/book/3"
+		assert.NoError(t, store.set(&entry{URL: url, StoredAt: storedAt}))
+		assert.NoError(t, os.WriteFile(store.path(url), []byte("not json"), 0644))
+
+		got, err := store.get(url)
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func Test_diskStore_cleanup(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		maxAge      time.Duration
+		maxSize     int64
+		wantRemoved int
+		wantURLs    []string
+	}{
+		{
+			name:        "remove entries older than max age",
+			maxAge:      48 * time.Hour,
+			wantRemoved: 1,
+			wantURLs:    []string{"https://test.com/new", "https://test.com/mid"},
+		},
+		{
+			name:        "remove oldest entries until cache is within max size",
+			maxSize:     120,
+			wantRemoved: 2,
+			wantURLs:    []string{"https://test.com/new"},
+		},
+		{
+			name:        "keep all entries without limits",
+			wantRemoved: 0,
+			wantURLs:    []string{"https://test.com/new", "https://test.com/mid", "https://test.com/old"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &diskStore{dir: t.TempDir()}
+			for url, modTime := range map[string]time.Time{
+				"https://test.com/new": now.Add(-time.Hour),
+				"https://test.com/mid": now.Add(-24 * time.Hour),
+				"https://test.com/old": now.Add(-72 * time.Hour),
+			} {
+				assert.NoError(t, store.set(&entry{URL: url, Body: "body", StoredAt: modTime}))
+				assert.NoError(t, os.Chtimes(store.path(url), modTime, modTime))
+			}
+
+			removed, err := store.cleanup(now, test.maxAge, test.maxSize)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantRemoved, removed)
+
+			for _, url := range test.wantURLs {
+				got, err := store.get(url)
+				assert.NoError(t, err)
+				assert.NotNil(t, got, url)
+			}
+		})
+	}
+
+	t.Run("ignore missing cache dir", func(t *testing.T) {
+		t.Parallel()
+
+		store := &diskStore{dir: t.TempDir() + "/missing"}
+		removed, err := store.cleanup(now, time.Hour, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, removed)
+	})
+}
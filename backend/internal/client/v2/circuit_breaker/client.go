@@ -2,10 +2,13 @@ package circuitbreaker
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -19,6 +22,31 @@ const (
 	StatusClosed   CircuitBreakerStatus = "closed"
 )
 
+const (
+	ReasonReachOpenThreshold = "fail count reached open threshold"
+	ReasonErrorRateExceeded  = "error rate exceeded threshold"
+	ReasonFailDuringRecover  = "request failed during recover"
+	ReasonOpenDurationPassed = "open duration passed"
+	ReasonRecovered          = "recovered"
+)
+
+// State is the snapshot of circuit breaker. Since is zero if the status is
+// never changed after start. Transitions count the times of entering each
+// status
+type State struct {
+	Status        CircuitBreakerStatus `json:"status"`
+	Reason        string               `json:"reason"`
+	Since         time.Time            `json:"since"`
+	Forced        bool                 `json:"forced"`
+	FailCount     uint32               `json:"fail_count"`
+	HalfOpenLevel int32                `json:"half_open_level"`
+	// requests and failures in the sliding window, they are always 0 if
+	// window is disabled
+	WindowRequests int64                          `json:"window_requests"`
+	WindowFailures int64                          `json:"window_failures"`
+	Transitions    map[CircuitBreakerStatus]int64 `json:"transitions"`
+}
+
 type CircuitBreakerClient struct {
 	config *CircuitBreakerClientConfig
 	client client.BookClient
@@ -28,29 +56,138 @@ type CircuitBreakerClient struct {
 	status        atomic.Value
 	halfOpenLevel atomic.Int32
 	failChecks    []FailCheck
+	// url classes whose parse failures are counted
+	parseFailureClasses map[client.URLClass]bool
+	window              *slidingWindow
+	// status transition
+	name        string
+	forced      atomic.Bool
+	stateLock   sync.Mutex
+	reason      string
+	since       time.Time
+	transitions map[CircuitBreakerStatus]int64
 }
 
-var _ client.BookClient = (*CircuitBreakerClient)(nil)
+var _ client.ResponseClient = (*CircuitBreakerClient)(nil)
 
 func NewClient(
 	conf *CircuitBreakerClientConfig,
 	bookClient client.BookClient,
 ) *CircuitBreakerClient {
 	c := &CircuitBreakerClient{
-		config:   conf,
-		client:   bookClient,
-		weighted: semaphore.NewWeighted(conf.MaxConcurrencyThreads),
+		config:      conf,
+		client:      bookClient,
+		weighted:    semaphore.NewWeighted(conf.MaxConcurrencyThreads),
+		transitions: make(map[CircuitBreakerStatus]int64),
 	}
 	c.status.Store(StatusClosed)
 	c.halfOpenLevel.Store(0)
 	c.failCount.Store(0)
 	for _, checkConf := range conf.CheckConfigs {
-		c.failChecks = append(c.failChecks, newFailCheck(checkConf))
+		if check := newFailCheck(checkConf); check != nil {
+			c.failChecks = append(c.failChecks, check)
+		}
 	}
+	c.parseFailureClasses = newParseFailureClasses(conf.CheckConfigs)
+	c.window = newSlidingWindow(conf.Window.Duration)
 
 	return c
 }
 
+// WithName set the name used in the logs of status transition
+func (c *CircuitBreakerClient) WithName(name string) *CircuitBreakerClient {
+	c.name = name
+	c.reportStatus(c.status.Load().(CircuitBreakerStatus))
+
+	return c
+}
+
+// reportStatus update the status metrics, it is skipped for unnamed client
+func (c *CircuitBreakerClient) reportStatus(status CircuitBreakerStatus) {
+	if c.name == "" {
+		return
+	}
+
+	metrics.SetCircuitBreakerStatus(
+		c.name, string(status),
+		string(StatusOpen), string(StatusHalfOpen), string(StatusClosed),
+	)
+}
+
+// transit change the status to `to` if current status is `from`, or from any
+// status if `from` is empty. the transition is recorded and logged
+func (c *CircuitBreakerClient) transit(from, to CircuitBreakerStatus, reason string) bool {
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+
+	current := c.status.Load().(CircuitBreakerStatus)
+	if from != "" && current != from {
+		return false
+	}
+
+	c.status.Store(to)
+	c.reason, c.since = reason, time.Now()
+	c.transitions[to]++
+	c.reportStatus(to)
+	if c.name != "" {
+		metrics.CircuitBreakerTransitions.WithLabelValues(c.name, string(to)).Inc()
+	}
+
+	log.Info().
+		Str("client", "CircuitBreakerClient").
+		Str("name", c.name).
+		Str("from", string(current)).
+		Str("to", string(to)).
+		Str("reason", reason).
+		Msg("circuit breaker status changed")
+
+	return true
+}
+
+func (c *CircuitBreakerClient) State() State {
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+
+	transitions := make(map[CircuitBreakerStatus]int64, len(c.transitions))
+	for status, count := range c.transitions {
+		transitions[status] = count
+	}
+
+	var windowRequests, windowFailures int64
+	if c.window != nil {
+		windowRequests, windowFailures = c.window.counts(time.Now())
+	}
+
+	return State{
+		Status:        c.status.Load().(CircuitBreakerStatus),
+		Reason:        c.reason,
+		Since:         c.since,
+		Forced:        c.forced.Load(),
+		FailCount:     c.failCount.Load(),
+		HalfOpenLevel: c.halfOpenLevel.Load(),
+		Transitions:   transitions,
+
+		WindowRequests: windowRequests,
+		WindowFailures: windowFailures,
+	}
+}
+
+// ForceOpen open the circuit and keep it open until ForceClose is called
+func (c *CircuitBreakerClient) ForceOpen(reason string) {
+	c.forced.Store(true)
+	c.transit("", StatusOpen, reason)
+	c.halfOpenLevel.Store(0)
+}
+
+// ForceClose close the circuit immediately and resume automatic control
+func (c *CircuitBreakerClient) ForceClose(reason string) {
+	c.forced.Store(false)
+	c.failCount.Store(0)
+	c.halfOpenLevel.Store(0)
+	c.resetWindow()
+	c.transit("", StatusClosed, reason)
+}
+
 func (c *CircuitBreakerClient) requestWeights() int64 {
 	var weight int64
 
@@ -71,8 +208,12 @@ func (c *CircuitBreakerClient) requestWeights() int64 {
 	return weight
 }
 
-func (c *CircuitBreakerClient) acquire(ctx context.Context) int64 {
+func (c *CircuitBreakerClient) acquire(ctx context.Context) (int64, error) {
 	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
 		weight := c.requestWeights()
 
 		err := func() error {
@@ -81,7 +222,7 @@ func (c *CircuitBreakerClient) acquire(ctx context.Context) int64 {
 			return c.weighted.Acquire(ctxTimeout, weight)
 		}()
 		if err == nil {
-			return weight
+			return weight, nil
 		}
 	}
 }
@@ -89,8 +230,12 @@ func (c *CircuitBreakerClient) acquire(ctx context.Context) int64 {
 func (c *CircuitBreakerClient) recover() {
 	// keep status open for a duration
 	time.Sleep(c.config.OpenDuration)
+	// forced open circuit is only closed by ForceClose
+	if c.forced.Load() {
+		return
+	}
 	// assumed no request is on the flight
-	ok := c.status.CompareAndSwap(StatusOpen, StatusHalfOpen)
+	ok := c.transit(StatusOpen, StatusHalfOpen, ReasonOpenDurationPassed)
 	if !ok {
 		return
 	}
@@ -103,7 +248,7 @@ func (c *CircuitBreakerClient) recover() {
 
 		// set status to open again if there is any failure during the recover
 		if c.failCount.Load() > 0 {
-			c.handleCircuitOpen()
+			c.handleCircuitOpen(ReasonFailDuringRecover)
 			return
 		}
 
@@ -113,15 +258,30 @@ func (c *CircuitBreakerClient) recover() {
 		}
 	}
 
-	ok = c.status.CompareAndSwap(StatusHalfOpen, StatusClosed)
+	ok = c.transit(StatusHalfOpen, StatusClosed, ReasonRecovered)
 	if ok {
 		c.halfOpenLevel.Store(0)
+		// failures before open should not open the recovered circuit again
+		c.resetWindow()
+	}
+}
+
+func (c *CircuitBreakerClient) resetWindow() {
+	if c.window != nil {
+		c.window.reset()
 	}
 }
 
-func (c *CircuitBreakerClient) handleCircuitOpen() {
-	// set status to open
-	c.status.Store(StatusOpen)
+func (c *CircuitBreakerClient) handleCircuitOpen(reason string) {
+	c.openCircuit("", reason)
+}
+
+// openCircuit set status to open if current status is `from`, or from any
+// status if `from` is empty, and start recovering after open duration
+func (c *CircuitBreakerClient) openCircuit(from CircuitBreakerStatus, reason string) {
+	if !c.transit(from, StatusOpen, reason) {
+		return
+	}
 	c.halfOpenLevel.Store(0)
 	// deploy go routine to delay recover
 	go c.recover()
@@ -129,32 +289,85 @@ func (c *CircuitBreakerClient) handleCircuitOpen() {
 }
 
 func (c *CircuitBreakerClient) reachOpenThreshold() {
-	failCount := c.failCount.Load()
-	if failCount >= c.config.OpenThreshold {
-		c.handleCircuitOpen()
+	if c.window == nil {
+		failCount := c.failCount.Load()
+		if failCount >= c.config.OpenThreshold {
+			c.handleCircuitOpen(ReasonReachOpenThreshold)
+		}
+
+		return
 	}
-}
 
-func (c *CircuitBreakerClient) Get(ctx context.Context, url string) (string, error) {
-	acquireAmount := c.acquire(ctx)
-	defer func() {
-		c.weighted.Release(acquireAmount)
-	}()
+	// failures during recover are handled by recover itself
+	total, fails := c.window.counts(time.Now())
+	if total > 0 && total >= c.config.Window.MinRequests &&
+		float64(fails) >= c.config.Window.ErrorRate*float64(total) {
+		c.openCircuit(StatusClosed, ReasonErrorRateExceeded)
+	}
+}
 
-	res, reqErr := c.client.Get(ctx, url)
-	isRequestFail := false
-	for _, check := range c.failChecks {
-		if check(res, reqErr) {
-			isRequestFail = true
-			break
-		}
+// record count the result of a request in fail count and sliding window
+func (c *CircuitBreakerClient) record(failed bool) {
+	if c.window != nil {
+		c.window.add(time.Now(), failed)
 	}
-	if isRequestFail {
+
+	if failed {
 		c.failCount.Add(1)
 		c.reachOpenThreshold()
 	} else if c.status.Load() != StatusHalfOpen {
 		c.failCount.Store(0)
 	}
+}
+
+// ReportParseResult count the request of url class as failed if its response
+// cannot be parsed and parse failures of the class are checked. response
+// served by cache is skipped, as it tells nothing about the site now
+func (c *CircuitBreakerClient) ReportParseResult(class client.URLClass, res *client.Response, err error) {
+	if err == nil || !c.parseFailureClasses[class] || (res != nil && res.FromCache) {
+		return
+	}
+
+	if c.window != nil {
+		c.window.markFail(time.Now())
+	}
+
+	c.failCount.Add(1)
+	c.reachOpenThreshold()
+}
+
+func (c *CircuitBreakerClient) isRequestFail(res string, err error) bool {
+	for _, check := range c.failChecks {
+		if check(res, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *CircuitBreakerClient) Get(ctx context.Context, url string) (string, error) {
+	acquireAmount, err := c.acquire(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		c.weighted.Release(acquireAmount)
+	}()
+
+	// share the fail checks with inner clients, so they can track the health
+	// of their own resources (e.g. proxies) in the same way
+	res, reqErr := c.client.Get(client.WithFailCheck(ctx, c.isRequestFail), url)
+	// request stopped by caller tells nothing about the health of the site
+	if ctx.Err() != nil {
+		return res, reqErr
+	}
+
+	c.record(c.isRequestFail(res, reqErr))
 
 	return res, reqErr
 }
+
+func (c *CircuitBreakerClient) GetResponse(ctx context.Context, url string) (*client.Response, error) {
+	return client.GetResponse(ctx, c, url)
+}
@@ -2,6 +2,7 @@ package circuitbreaker
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -9,8 +10,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
+	"github.com/htchan/BookSpider/internal/metrics"
+	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/sync/semaphore"
 )
@@ -175,7 +180,9 @@ func TestCircuitBreakerClient_acquire(t *testing.T) {
 	tests := []struct {
 		name          string
 		prepareClient func() *CircuitBreakerClient
+		getContext    func() context.Context
 		want          int64
+		wantError     error
 		wantDuration  time.Duration
 	}{
 		{
@@ -235,6 +242,30 @@ func TestCircuitBreakerClient_acquire(t *testing.T) {
 			want:         1,
 			wantDuration: 75 * time.Millisecond,
 		},
+		{
+			name: "stop acquiring with open client if context is cancelled",
+			prepareClient: func() *CircuitBreakerClient {
+				cli := NewClient(
+					&CircuitBreakerClientConfig{
+						MaxConcurrencyThreads: 10,
+						AcquireTimeout:        25 * time.Millisecond,
+					},
+					nil,
+				)
+				cli.status.Store(StatusOpen)
+
+				return cli
+			},
+			getContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(40*time.Millisecond, cancel)
+
+				return ctx
+			},
+			want:         0,
+			wantError:    context.Canceled,
+			wantDuration: 40 * time.Millisecond,
+		},
 	}
 
 	for _, test := range tests {
@@ -243,11 +274,19 @@ func TestCircuitBreakerClient_acquire(t *testing.T) {
 			t.Parallel()
 
 			cli := test.prepareClient()
+			ctx := context.Background()
+			if test.getContext != nil {
+				ctx = test.getContext()
+			}
 
 			startTime := time.Now()
-			got := cli.acquire(context.Background())
+			got, err := cli.acquire(ctx)
 			assert.Equal(t, test.wantDuration, time.Since(startTime).Truncate(5*time.Millisecond))
 			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+			if err != nil {
+				return
+			}
 
 			acquireFailAmount := cli.config.MaxConcurrencyThreads - got + 1
 			failure := cli.weighted.TryAcquire(acquireFailAmount)
@@ -398,7 +437,7 @@ func TestCircuitBreakerClient_handleCircuitOpen(t *testing.T) {
 			t.Parallel()
 
 			cli := test.prepareClient()
-			cli.handleCircuitOpen()
+			cli.handleCircuitOpen(ReasonReachOpenThreshold)
 			assert.Equal(t, test.wantStatus, cli.status.Load())
 			assert.Equal(t, test.wantHalfOpenLevel, cli.halfOpenLevel.Load())
 			assert.Equal(t, test.wantFailCount, cli.failCount.Load())
@@ -411,6 +450,184 @@ func TestCircuitBreakerClient_handleCircuitOpen(t *testing.T) {
 	}
 }
 
+func TestCircuitBreakerClient_ForceOpen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		prepareClient   func() *CircuitBreakerClient
+		waitDuration    time.Duration
+		wantStatus      CircuitBreakerStatus
+		wantReason      string
+		wantForced      bool
+		wantTransitions map[CircuitBreakerStatus]int64
+	}{
+		{
+			name: "keep open after open duration",
+			prepareClient: func() *CircuitBreakerClient {
+				return NewClient(
+					&CircuitBreakerClientConfig{
+						MaxConcurrencyThreads: 10,
+						RecoverThreads:        []int64{1},
+						RecoverDuration:       10 * time.Millisecond,
+						OpenDuration:          10 * time.Millisecond,
+					},
+					nil,
+				)
+			},
+			waitDuration:    50 * time.Millisecond,
+			wantStatus:      StatusOpen,
+			wantReason:      "vendor outage",
+			wantForced:      true,
+			wantTransitions: map[CircuitBreakerStatus]int64{StatusOpen: 1},
+		},
+		{
+			name: "stop recover of opened circuit",
+			prepareClient: func() *CircuitBreakerClient {
+				cli := NewClient(
+					&CircuitBreakerClientConfig{
+						MaxConcurrencyThreads: 10,
+						RecoverThreads:        []int64{1},
+						RecoverDuration:       10 * time.Millisecond,
+						OpenDuration:          10 * time.Millisecond,
+					},
+					nil,
+				)
+				cli.handleCircuitOpen(ReasonReachOpenThreshold)
+
+				return cli
+			},
+			waitDuration:    50 * time.Millisecond,
+			wantStatus:      StatusOpen,
+			wantReason:      "vendor outage",
+			wantForced:      true,
+			wantTransitions: map[CircuitBreakerStatus]int64{StatusOpen: 2},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli := test.prepareClient()
+			cli.ForceOpen("vendor outage")
+			time.Sleep(test.waitDuration)
+
+			state := cli.State()
+			assert.Equal(t, test.wantStatus, state.Status)
+			assert.Equal(t, test.wantReason, state.Reason)
+			assert.Equal(t, test.wantForced, state.Forced)
+			assert.Equal(t, test.wantTransitions, state.Transitions)
+			assert.False(t, state.Since.IsZero())
+		})
+	}
+}
+
+func TestCircuitBreakerClient_ForceClose(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		prepareClient   func() *CircuitBreakerClient
+		wantStatus      CircuitBreakerStatus
+		wantReason      string
+		wantForced      bool
+		wantFailCount   uint32
+		wantTransitions map[CircuitBreakerStatus]int64
+	}{
+		{
+			name: "close forced open circuit",
+			prepareClient: func() *CircuitBreakerClient {
+				cli := NewClient(
+					&CircuitBreakerClientConfig{
+						MaxConcurrencyThreads: 10,
+						RecoverThreads:        []int64{1},
+						RecoverDuration:       10 * time.Millisecond,
+						OpenDuration:          time.Second,
+					},
+					nil,
+				)
+				cli.ForceOpen("vendor outage")
+
+				return cli
+			},
+			wantStatus:      StatusClosed,
+			wantReason:      "vendor back",
+			wantForced:      false,
+			wantFailCount:   0,
+			wantTransitions: map[CircuitBreakerStatus]int64{StatusOpen: 1, StatusClosed: 1},
+		},
+		{
+			name: "reset fail count of closed circuit",
+			prepareClient: func() *CircuitBreakerClient {
+				cli := NewClient(
+					&CircuitBreakerClientConfig{
+						MaxConcurrencyThreads: 10,
+						OpenThreshold:         10,
+						RecoverThreads:        []int64{1},
+						RecoverDuration:       10 * time.Millisecond,
+						OpenDuration:          time.Second,
+					},
+					nil,
+				)
+				cli.failCount.Store(5)
+
+				return cli
+			},
+			wantStatus:      StatusClosed,
+			wantReason:      "vendor back",
+			wantForced:      false,
+			wantFailCount:   0,
+			wantTransitions: map[CircuitBreakerStatus]int64{StatusClosed: 1},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cli := test.prepareClient()
+			cli.ForceClose("vendor back")
+
+			state := cli.State()
+			assert.Equal(t, test.wantStatus, state.Status)
+			assert.Equal(t, test.wantReason, state.Reason)
+			assert.Equal(t, test.wantForced, state.Forced)
+			assert.Equal(t, test.wantFailCount, state.FailCount)
+			assert.Equal(t, test.wantTransitions, state.Transitions)
+		})
+	}
+}
+
+func TestCircuitBreakerClient_StatusMetrics(t *testing.T) {
+	t.Parallel()
+
+	cli := NewClient(
+		&CircuitBreakerClientConfig{
+			MaxConcurrencyThreads: 10,
+			RecoverThreads:        []int64{1},
+		},
+		nil,
+	).WithName("test-status-metrics")
+
+	statusValue := func(status CircuitBreakerStatus) float64 {
+		return testutil.ToFloat64(metrics.CircuitBreakerStatus.WithLabelValues("test-status-metrics", string(status)))
+	}
+
+	assert.Equal(t, 1.0, statusValue(StatusClosed))
+	assert.Equal(t, 0.0, statusValue(StatusOpen))
+
+	cli.ForceOpen("vendor outage")
+	assert.Equal(t, 0.0, statusValue(StatusClosed))
+	assert.Equal(t, 1.0, statusValue(StatusOpen))
+	assert.Equal(t, 0.0, statusValue(StatusHalfOpen))
+	assert.Equal(t, 1.0, testutil.ToFloat64(
+		metrics.CircuitBreakerTransitions.WithLabelValues("test-status-metrics", string(StatusOpen)),
+	))
+}
+
 func TestCircuitBreakClient_reqchOpenThreshold(t *testing.T) {
 	t.Parallel()
 
@@ -727,3 +944,245 @@ func TestCircuitBreakerClient_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestCircuitBreakerClient_Get_ShareFailCheck(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var gotCheck client.FailCheck
+	bookClient := mockclient.NewMockBookClient(ctrl)
+	bookClient.EXPECT().Get(gomock.Any(), "https://test.com").DoAndReturn(
+		func(ctx context.Context, _ string) (string, error) {
+			gotCheck = client.FailCheckFromContext(ctx)
+
+			return "hello", nil
+		},
+	)
+
+	cli := NewClient(
+		&CircuitBreakerClientConfig{
+			OpenThreshold:         10,
+			AcquireTimeout:        10 * time.Millisecond,
+			MaxConcurrencyThreads: 2,
+			CheckConfigs: []CheckConfig{
+				{Type: CheckTypeStatusCodes, Value: []interface{}{502}},
+			},
+		},
+		bookClient,
+	)
+
+	got, err := cli.Get(context.Background(), "https://test.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got)
+	if assert.NotNil(t, gotCheck) {
+		assert.True(t, gotCheck("", client.StatusCodeError{StatusCode: 502}))
+		assert.False(t, gotCheck("", client.StatusCodeError{StatusCode: 404}))
+	}
+}
+
+func TestCircuitBreakerClient_Get_Window(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		responses     []error
+		window        WindowConfig
+		wantStatus    CircuitBreakerStatus
+		wantReason    string
+		wantRequests  int64
+		wantFailures  int64
+		wantFailCount uint32
+	}{
+		{
+			name:          "open when error rate reach threshold",
+			responses:     []error{nil, client.ErrTimeout, nil, client.ErrTimeout},
+			window:        WindowConfig{Duration: time.Minute, MinRequests: 4, ErrorRate: 0.5},
+			wantStatus:    StatusOpen,
+			wantReason:    ReasonErrorRateExceeded,
+			wantRequests:  4,
+			wantFailures:  2,
+			wantFailCount: 0,
+		},
+		{
+			name:          "not open with non consecutive failures below error rate",
+			responses:     []error{client.ErrTimeout, nil, nil, client.ErrTimeout, nil},
+			window:        WindowConfig{Duration: time.Minute, MinRequests: 4, ErrorRate: 0.6},
+			wantStatus:    StatusClosed,
+			wantReason:    "",
+			wantRequests:  5,
+			wantFailures:  2,
+			wantFailCount: 0,
+		},
+		{
+			name:          "not open before reaching min requests",
+			responses:     []error{client.ErrTimeout, client.ErrTimeout, client.ErrTimeout},
+			window:        WindowConfig{Duration: time.Minute, MinRequests: 4, ErrorRate: 0.5},
+			wantStatus:    StatusClosed,
+			wantReason:    "",
+			wantRequests:  3,
+			wantFailures:  3,
+			wantFailCount: 3,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookClient := mockclient.NewMockBookClient(ctrl)
+			for _, err := range test.responses {
+				bookClient.EXPECT().Get(gomock.Any(), "https://test.com").Return("", err)
+			}
+
+			cli := NewClient(
+				&CircuitBreakerClientConfig{
+					AcquireTimeout:        10 * time.Millisecond,
+					MaxConcurrencyThreads: 2,
+					RecoverThreads:        []int64{1},
+					OpenDuration:          time.Minute,
+					RecoverDuration:       time.Minute,
+					CheckConfigs:          []CheckConfig{{Type: CheckTypeTimeout}},
+					Window:                test.window,
+				},
+				bookClient,
+			)
+
+			for range test.responses {
+				cli.Get(context.Background(), "https://test.com")
+			}
+
+			state := cli.State()
+			assert.Equal(t, test.wantStatus, state.Status)
+			assert.Equal(t, test.wantReason, state.Reason)
+			assert.Equal(t, test.wantRequests, state.WindowRequests)
+			assert.Equal(t, test.wantFailures, state.WindowFailures)
+			assert.Equal(t, test.wantFailCount, state.FailCount)
+		})
+	}
+}
+
+func TestCircuitBreakerClient_ReportParseResult(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		class         client.URLClass
+		res           *client.Response
+		err           error
+		wantStatus    CircuitBreakerStatus
+		wantFailures  int64
+		wantFailCount uint32
+	}{
+		{
+			name:          "count parse failure of checked class",
+			class:         client.URLClassChapter,
+			err:           errors.New("chapter content not found"),
+			wantStatus:    StatusOpen,
+			wantFailures:  1,
+			wantFailCount: 0,
+		},
+		{
+			name:          "ignore parse failure of response served by cache",
+			class:         client.URLClassChapter,
+			res:           &client.Response{FromCache: true},
+			err:           errors.New("chapter content not found"),
+			wantStatus:    StatusClosed,
+			wantFailures:  0,
+			wantFailCount: 0,
+		},
+		{
+			name:          "ignore parse failure of unchecked class",
+			class:         client.URLClassBook,
+			err:           errors.New("title not found"),
+			wantStatus:    StatusClosed,
+			wantFailures:  0,
+			wantFailCount: 0,
+		},
+		{
+			name:          "ignore parse success",
+			class:         client.URLClassChapter,
+			err:           nil,
+			wantStatus:    StatusClosed,
+			wantFailures:  0,
+			wantFailCount: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookClient := mockclient.NewMockBookClient(ctrl)
+			bookClient.EXPECT().Get(gomock.Any(), "https://test.com").Return("body", nil)
+
+			cli := NewClient(
+				&CircuitBreakerClientConfig{
+					AcquireTimeout:        10 * time.Millisecond,
+					MaxConcurrencyThreads: 2,
+					RecoverThreads:        []int64{1},
+					OpenDuration:          time.Minute,
+					RecoverDuration:       time.Minute,
+					CheckConfigs: []CheckConfig{
+						{Type: CheckTypeParseFailure, Value: []interface{}{"chapter"}},
+					},
+					Window: WindowConfig{Duration: time.Minute, MinRequests: 1, ErrorRate: 0.5},
+				},
+				bookClient,
+			)
+
+			_, err := cli.Get(context.Background(), "https://test.com")
+			assert.NoError(t, err)
+			cli.ReportParseResult(test.class, test.res, test.err)
+
+			state := cli.State()
+			assert.Equal(t, test.wantStatus, state.Status)
+			assert.Equal(t, test.wantFailCount, state.FailCount)
+			if test.wantStatus == StatusClosed {
+				assert.Equal(t, test.wantFailures, state.WindowFailures)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerClient_GetResponse(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookClient := mockclient.NewMockBookClient(ctrl)
+	bookClient.EXPECT().Get(gomock.Any(), "https://test.com").DoAndReturn(
+		func(ctx context.Context, _ string) (string, error) {
+			info := client.ResponseInfoFromContext(ctx)
+			info.StatusCode = http.StatusOK
+			info.URL = "https://test.com/final"
+
+			return "hello", nil
+		},
+	)
+
+	cli := NewClient(
+		&CircuitBreakerClientConfig{
+			OpenThreshold:         10,
+			AcquireTimeout:        10 * time.Millisecond,
+			MaxConcurrencyThreads: 2,
+		},
+		bookClient,
+	)
+
+	got, err := cli.GetResponse(context.Background(), "https://test.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got.Body)
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+	assert.Equal(t, "https://test.com/final", got.URL)
+}
@@ -1,8 +1,22 @@
 package circuitbreaker
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+)
+
+var (
+	ErrInvalidCheckConfig        = errors.New("invalid check config")
+	ErrParseFailureWithoutWindow = errors.New("parse-failure check requires window")
+)
 
 type CircuitBreakerClientConfig struct {
+	// OpenThreshold is the consecutive fail count to open the circuit, it is
+	// only used if Window is disabled
 	OpenThreshold         uint32        `yaml:"open_threshold" validate:"min=10"`
 	AcquireTimeout        time.Duration `yaml:"acquire_timeout" validate:"min=100ms"`
 	MaxConcurrencyThreads int64         `yaml:"max_concurrency_threads" validate:"min=2"`
@@ -10,15 +24,138 @@ type CircuitBreakerClientConfig struct {
 	OpenDuration          time.Duration `yaml:"open_duration" validate:"min=1s"`
 	RecoverDuration       time.Duration `yaml:"recover_duration" validate:"min=1s"`
 	CheckConfigs          []CheckConfig `yaml:"check_configs" validate:"dive"`
+	Window                WindowConfig  `yaml:"window"`
+}
+
+// WindowConfig open the circuit once the error rate of requests within the
+// last Duration reach ErrorRate. the window is disabled if Duration is 0
+type WindowConfig struct {
+	Duration    time.Duration `yaml:"duration" validate:"omitempty,min=1s"`
+	MinRequests int64         `yaml:"min_requests" validate:"min=0"`
+	ErrorRate   float64       `yaml:"error_rate" validate:"required_with=Duration,omitempty,gt=0,max=1"`
 }
 
 type CheckType string
 
 const (
-	CheckTypeStatusCodes CheckType = "status-codes"
+	CheckTypeStatusCodes     CheckType = "status-codes"
+	CheckTypeTimeout         CheckType = "timeout"
+	CheckTypeConnectionError CheckType = "connection-error"
+	CheckTypeBodyRegex       CheckType = "body-regex"
+	// CheckTypeParseFailure count the pages failed to be parsed by vendor
+	// service as failed requests. its value is the url classes to check. it
+	// requires window, as the consecutive fail count is reset by the request
+	// before its page is parsed
+	CheckTypeParseFailure CheckType = "parse-failure"
 )
 
+// CheckConfig is a condition to count a request as failed. the Value of
+// status-codes is a list of status codes, of body-regex is a regular
+// expression and of parse-failure is a list of url classes. timeout and
+// connection-error do not have value
 type CheckConfig struct {
-	Type  CheckType `yaml:"type" validate:"oneof=status-codes"`
+	Type  CheckType `yaml:"type" validate:"oneof=status-codes timeout connection-error body-regex parse-failure"`
 	Value any       `yaml:"value"`
 }
+
+// Validate check if the check configs have values of the expected types and
+// are supported by the window config, which cannot be expressed by struct tags
+func (conf CircuitBreakerClientConfig) Validate() error {
+	for i, checkConf := range conf.CheckConfigs {
+		if err := checkConf.Validate(); err != nil {
+			return fmt.Errorf("check config %d: %w", i, err)
+		}
+
+		if checkConf.Type == CheckTypeParseFailure && conf.Window.Duration == 0 {
+			return fmt.Errorf("check config %d: %w", i, ErrParseFailureWithoutWindow)
+		}
+	}
+
+	return nil
+}
+
+func (conf CheckConfig) Validate() error {
+	var err error
+
+	switch conf.Type {
+	case CheckTypeStatusCodes:
+		_, err = conf.statusCodes()
+	case CheckTypeBodyRegex:
+		_, err = conf.bodyRegex()
+	case CheckTypeParseFailure:
+		_, err = conf.urlClasses()
+	}
+
+	return err
+}
+
+// list return nil without error if value is not set
+func (conf CheckConfig) list() ([]interface{}, error) {
+	if conf.Value == nil {
+		return nil, nil
+	}
+
+	values, ok := conf.Value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: value of %s must be a list but got %T", ErrInvalidCheckConfig, conf.Type, conf.Value)
+	}
+
+	return values, nil
+}
+
+func (conf CheckConfig) statusCodes() ([]int, error) {
+	values, err := conf.list()
+	if err != nil {
+		return nil, err
+	}
+
+	statusCodes := make([]int, len(values))
+	for i, value := range values {
+		code, ok := value.(int)
+		if !ok {
+			return nil, fmt.Errorf("%w: status code must be int but got %T", ErrInvalidCheckConfig, value)
+		}
+
+		statusCodes[i] = code
+	}
+
+	return statusCodes, nil
+}
+
+func (conf CheckConfig) bodyRegex() (*regexp.Regexp, error) {
+	expr, ok := conf.Value.(string)
+	if !ok || expr == "" {
+		return nil, fmt.Errorf("%w: value of %s must be a non empty string", ErrInvalidCheckConfig, conf.Type)
+	}
+
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCheckConfig, err)
+	}
+
+	return regex, nil
+}
+
+func (conf CheckConfig) urlClasses() ([]client.URLClass, error) {
+	values, err := conf.list()
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make([]client.URLClass, len(values))
+	for i, value := range values {
+		class, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: url class must be string but got %T", ErrInvalidCheckConfig, value)
+		}
+
+		switch urlClass := client.URLClass(class); urlClass {
+		case client.URLClassBook, client.URLClassChapterList, client.URLClassChapter:
+			classes[i] = urlClass
+		default:
+			return nil, fmt.Errorf("%w: url class must be one of book, chapter_list and chapter but got %q", ErrInvalidCheckConfig, class)
+		}
+	}
+
+	return classes, nil
+}
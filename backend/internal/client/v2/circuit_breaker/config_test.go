@@ -24,6 +24,22 @@ func Test_validate_CheckConfig(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			name: "valid body regex",
+			conf: CheckConfig{
+				Type:  CheckTypeBodyRegex,
+				Value: "请稍后再试",
+			},
+			valid: true,
+		},
+		{
+			name: "valid parse failure",
+			conf: CheckConfig{
+				Type:  CheckTypeParseFailure,
+				Value: []interface{}{"chapter"},
+			},
+			valid: true,
+		},
 		{
 			name: "invalid type",
 			conf: CheckConfig{
@@ -44,6 +60,147 @@ func Test_validate_CheckConfig(t *testing.T) {
 	}
 }
 
+func TestCheckConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		conf      CheckConfig
+		wantError error
+	}{
+		{
+			name:      "valid status codes",
+			conf:      CheckConfig{Type: CheckTypeStatusCodes, Value: []interface{}{502, 503}},
+			wantError: nil,
+		},
+		{
+			name:      "valid status codes without value",
+			conf:      CheckConfig{Type: CheckTypeStatusCodes},
+			wantError: nil,
+		},
+		{
+			name:      "invalid status codes/not a list",
+			conf:      CheckConfig{Type: CheckTypeStatusCodes, Value: 502},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "invalid status codes/not int",
+			conf:      CheckConfig{Type: CheckTypeStatusCodes, Value: []interface{}{"502"}},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "valid body regex",
+			conf:      CheckConfig{Type: CheckTypeBodyRegex, Value: "请稍后再试"},
+			wantError: nil,
+		},
+		{
+			name:      "invalid body regex/not a string",
+			conf:      CheckConfig{Type: CheckTypeBodyRegex, Value: []interface{}{"请稍后再试"}},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "invalid body regex/empty",
+			conf:      CheckConfig{Type: CheckTypeBodyRegex},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "invalid body regex/not compiled",
+			conf:      CheckConfig{Type: CheckTypeBodyRegex, Value: "("},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "valid parse failure",
+			conf:      CheckConfig{Type: CheckTypeParseFailure, Value: []interface{}{"chapter"}},
+			wantError: nil,
+		},
+		{
+			name:      "valid parse failure/all url classes",
+			conf:      CheckConfig{Type: CheckTypeParseFailure, Value: []interface{}{"book", "chapter_list", "chapter"}},
+			wantError: nil,
+		},
+		{
+			name:      "invalid parse failure/unknown url class",
+			conf:      CheckConfig{Type: CheckTypeParseFailure, Value: []interface{}{"chapters"}},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "invalid parse failure/not string",
+			conf:      CheckConfig{Type: CheckTypeParseFailure, Value: []interface{}{1}},
+			wantError: ErrInvalidCheckConfig,
+		},
+		{
+			name:      "ignore value of timeout",
+			conf:      CheckConfig{Type: CheckTypeTimeout, Value: 1},
+			wantError: nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, test.conf.Validate(), test.wantError)
+
+			// invalid config is skipped instead of panic
+			assert.NotPanics(t, func() {
+				newFailCheck(test.conf)
+				newParseFailureClasses([]CheckConfig{test.conf})
+			})
+		})
+	}
+}
+
+func TestCircuitBreakerClientConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	conf := CircuitBreakerClientConfig{
+		CheckConfigs: []CheckConfig{
+			{Type: CheckTypeStatusCodes, Value: []interface{}{502}},
+			{Type: CheckTypeBodyRegex, Value: "("},
+		},
+	}
+
+	err := conf.Validate()
+	assert.ErrorIs(t, err, ErrInvalidCheckConfig)
+	assert.ErrorContains(t, err, "check config 1")
+}
+
+func TestCircuitBreakerClientConfig_Validate_ParseFailure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		conf      CircuitBreakerClientConfig
+		wantError error
+	}{
+		{
+			name: "parse failure with window",
+			conf: CircuitBreakerClientConfig{
+				CheckConfigs: []CheckConfig{{Type: CheckTypeParseFailure, Value: []interface{}{"chapter"}}},
+				Window:       WindowConfig{Duration: time.Minute, ErrorRate: 0.5},
+			},
+			wantError: nil,
+		},
+		{
+			name: "parse failure without window",
+			conf: CircuitBreakerClientConfig{
+				CheckConfigs: []CheckConfig{{Type: CheckTypeParseFailure, Value: []interface{}{"chapter"}}},
+			},
+			wantError: ErrParseFailureWithoutWindow,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, test.conf.Validate(), test.wantError)
+		})
+	}
+}
+
 func Test_validate_CircuitBreakerConfig(t *testing.T) {
 	t.Parallel()
 
@@ -187,6 +344,71 @@ func Test_validate_CircuitBreakerConfig(t *testing.T) {
 			},
 			valid: false,
 		},
+		{
+			name: "valid window",
+			conf: CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Second,
+				RecoverDuration:       time.Second,
+				Window:                WindowConfig{Duration: time.Minute, MinRequests: 10, ErrorRate: 0.5},
+			},
+			valid: true,
+		},
+		{
+			name: "invalid window/duration too short",
+			conf: CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Second,
+				RecoverDuration:       time.Second,
+				Window:                WindowConfig{Duration: time.Millisecond, ErrorRate: 0.5},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid window/missing error rate",
+			conf: CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Second,
+				RecoverDuration:       time.Second,
+				Window:                WindowConfig{Duration: time.Minute},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid window/error rate above 1",
+			conf: CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Second,
+				RecoverDuration:       time.Second,
+				Window:                WindowConfig{Duration: time.Minute, ErrorRate: 1.5},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid window/negative min requests",
+			conf: CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Second,
+				RecoverDuration:       time.Second,
+				Window:                WindowConfig{Duration: time.Minute, MinRequests: -1, ErrorRate: 0.5},
+			},
+			valid: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -2,20 +2,36 @@ package circuitbreaker
 
 import (
 	"errors"
+	"net"
+	"regexp"
+	"syscall"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
 )
 
 type FailCheck func(res string, err error) bool
 
+// newFailCheck return nil for the check types not applied on response and the
+// invalid configs, which are rejected by CheckConfig.Validate
 func newFailCheck(conf CheckConfig) FailCheck {
 	switch conf.Type {
-	default:
-		values := conf.Value.([]interface{})
+	case CheckTypeTimeout:
+		return newErrorFailCheck(client.ErrTimeout)
+	case CheckTypeConnectionError:
+		return newConnectionFailCheck()
+	case CheckTypeBodyRegex:
+		regex, err := conf.bodyRegex()
+		if err != nil {
+			return nil
+		}
 
-		statusCodes := make([]int, len(values))
-		for i := range values {
-			statusCodes[i] = values[i].(int)
+		return newBodyRegexFailCheck(regex)
+	case CheckTypeParseFailure:
+		return nil
+	default:
+		statusCodes, err := conf.statusCodes()
+		if err != nil {
+			return nil
 		}
 
 		return newStatusFailCheck(statusCodes)
@@ -36,3 +52,44 @@ func newStatusFailCheck(targetStatusCodes []int) FailCheck {
 		return false
 	}
 }
+
+func newErrorFailCheck(targetErr error) FailCheck {
+	return func(res string, err error) bool {
+		return errors.Is(err, targetErr)
+	}
+}
+
+func newConnectionFailCheck() FailCheck {
+	return func(res string, err error) bool {
+		var opErr *net.OpError
+
+		return errors.As(err, &opErr) ||
+			errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNREFUSED)
+	}
+}
+
+func newBodyRegexFailCheck(regex *regexp.Regexp) FailCheck {
+	return func(res string, err error) bool {
+		return err == nil && regex.MatchString(res)
+	}
+}
+
+// newParseFailureClasses return the url classes whose parse failures are
+// counted by the circuit breaker
+func newParseFailureClasses(confs []CheckConfig) map[client.URLClass]bool {
+	classes := make(map[client.URLClass]bool)
+
+	for _, conf := range confs {
+		if conf.Type != CheckTypeParseFailure {
+			continue
+		}
+
+		urlClasses, _ := conf.urlClasses()
+		for _, class := range urlClasses {
+			classes[class] = true
+		}
+	}
+
+	return classes
+}
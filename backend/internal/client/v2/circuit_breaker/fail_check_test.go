@@ -1,6 +1,9 @@
 package circuitbreaker
 
 import (
+	"errors"
+	"net"
+	"syscall"
 	"testing"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
@@ -14,6 +17,12 @@ func Test_newFailCheck(t *testing.T) {
 		Type:  CheckTypeStatusCodes,
 		Value: []interface{}{502},
 	}
+	timeoutConf := CheckConfig{Type: CheckTypeTimeout}
+	connectionConf := CheckConfig{Type: CheckTypeConnectionError}
+	bodyRegexConf := CheckConfig{
+		Type:  CheckTypeBodyRegex,
+		Value: "请稍后再试",
+	}
 
 	type args struct {
 		body string
@@ -44,6 +53,48 @@ func Test_newFailCheck(t *testing.T) {
 			conf: statusCodeConf,
 			want: true,
 		},
+		{
+			name: "timeout check/timeout error",
+			args: args{body: "", err: client.ErrTimeout},
+			conf: timeoutConf,
+			want: true,
+		},
+		{
+			name: "timeout check/other error",
+			args: args{body: "", err: errors.New("some error")},
+			conf: timeoutConf,
+			want: false,
+		},
+		{
+			name: "connection error check/connection reset",
+			args: args{body: "", err: syscall.ECONNRESET},
+			conf: connectionConf,
+			want: true,
+		},
+		{
+			name: "connection error check/dial error",
+			args: args{body: "", err: &net.OpError{Op: "dial", Err: errors.New("refused")}},
+			conf: connectionConf,
+			want: true,
+		},
+		{
+			name: "connection error check/status code error",
+			args: args{body: "", err: client.StatusCodeError{StatusCode: 502}},
+			conf: connectionConf,
+			want: false,
+		},
+		{
+			name: "body regex check/match body",
+			args: args{body: "<html>访问过于频繁，请稍后再试</html>", err: nil},
+			conf: bodyRegexConf,
+			want: true,
+		},
+		{
+			name: "body regex check/not match body",
+			args: args{body: "<html>content</html>", err: nil},
+			conf: bodyRegexConf,
+			want: false,
+		},
 	}
 
 	for _, test := range tests {
@@ -57,3 +108,49 @@ func Test_newFailCheck(t *testing.T) {
 		})
 	}
 }
+
+func Test_newFailCheck_ParseFailure(t *testing.T) {
+	t.Parallel()
+
+	check := newFailCheck(CheckConfig{Type: CheckTypeParseFailure, Value: []interface{}{"chapter"}})
+	assert.Nil(t, check)
+}
+
+func Test_newParseFailureClasses(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		confs []CheckConfig
+		want  map[client.URLClass]bool
+	}{
+		{
+			name: "collect classes of parse failure checks",
+			confs: []CheckConfig{
+				{Type: CheckTypeStatusCodes, Value: []interface{}{502}},
+				{Type: CheckTypeParseFailure, Value: []interface{}{"chapter_list", "chapter"}},
+			},
+			want: map[client.URLClass]bool{
+				client.URLClassChapterList: true,
+				client.URLClassChapter:     true,
+			},
+		},
+		{
+			name: "no parse failure checks",
+			confs: []CheckConfig{
+				{Type: CheckTypeStatusCodes, Value: []interface{}{502}},
+			},
+			want: map[client.URLClass]bool{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := newParseFailureClasses(test.confs)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+const windowBucketCount = 10
+
+type windowBucket struct {
+	start        time.Time
+	total, fails int64
+}
+
+// slidingWindow count the requests and failures in the last duration. the
+// duration is split into buckets, so the counts of expired buckets are
+// dropped as time goes
+type slidingWindow struct {
+	lock       sync.Mutex
+	bucketSize time.Duration
+	buckets    [windowBucketCount]windowBucket
+}
+
+func newSlidingWindow(duration time.Duration) *slidingWindow {
+	if duration <= 0 {
+		return nil
+	}
+
+	return &slidingWindow{bucketSize: duration / windowBucketCount}
+}
+
+// bucket return the bucket of now, resetting it if it was used by an
+// earlier round of the window. caller must hold the lock
+func (w *slidingWindow) bucket(now time.Time) *windowBucket {
+	start := now.Truncate(w.bucketSize)
+	b := &w.buckets[(start.UnixNano()/int64(w.bucketSize))%windowBucketCount]
+	if !b.start.Equal(start) {
+		*b = windowBucket{start: start}
+	}
+
+	return b
+}
+
+func (w *slidingWindow) add(now time.Time, failed bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	b := w.bucket(now)
+	b.total++
+	if failed {
+		b.fails++
+	}
+}
+
+// markFail count a request already added as success as failed
+func (w *slidingWindow) markFail(now time.Time) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.bucket(now).fails++
+}
+
+func (w *slidingWindow) counts(now time.Time) (total, fails int64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	earliest := now.Truncate(w.bucketSize).Add(-w.bucketSize * (windowBucketCount - 1))
+	for _, b := range w.buckets {
+		if b.start.Before(earliest) || b.start.After(now) {
+			continue
+		}
+
+		total += b.total
+		fails += b.fails
+	}
+
+	return total, min(fails, total)
+}
+
+func (w *slidingWindow) reset() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buckets = [windowBucketCount]windowBucket{}
+}
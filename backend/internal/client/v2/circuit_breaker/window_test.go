@@ -0,0 +1,113 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newSlidingWindow(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, newSlidingWindow(0))
+	assert.Equal(t, time.Second, newSlidingWindow(10*time.Second).bucketSize)
+}
+
+func Test_slidingWindow_counts(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		prepare     func(w *slidingWindow)
+		now         time.Time
+		wantTotal   int64
+		wantFailure int64
+	}{
+		{
+			name:        "empty window",
+			prepare:     func(w *slidingWindow) {},
+			now:         start,
+			wantTotal:   0,
+			wantFailure: 0,
+		},
+		{
+			name: "count requests in window",
+			prepare: func(w *slidingWindow) {
+				w.add(start, true)
+				w.add(start.Add(3*time.Second), false)
+				w.add(start.Add(9*time.Second), true)
+			},
+			now:         start.Add(9 * time.Second),
+			wantTotal:   3,
+			wantFailure: 2,
+		},
+		{
+			name: "drop requests out of window",
+			prepare: func(w *slidingWindow) {
+				w.add(start, true)
+				w.add(start.Add(3*time.Second), false)
+				w.add(start.Add(12*time.Second), true)
+			},
+			now:         start.Add(12 * time.Second),
+			wantTotal:   2,
+			wantFailure: 1,
+		},
+		{
+			name: "reuse bucket of earlier round",
+			prepare: func(w *slidingWindow) {
+				w.add(start, true)
+				w.add(start.Add(10*time.Second), false)
+			},
+			now:         start.Add(10 * time.Second),
+			wantTotal:   1,
+			wantFailure: 0,
+		},
+		{
+			name: "mark request as failed",
+			prepare: func(w *slidingWindow) {
+				w.add(start, false)
+				w.add(start, false)
+				w.markFail(start)
+			},
+			now:         start,
+			wantTotal:   2,
+			wantFailure: 1,
+		},
+		{
+			name: "failures never exceed total",
+			prepare: func(w *slidingWindow) {
+				w.markFail(start)
+			},
+			now:         start,
+			wantTotal:   0,
+			wantFailure: 0,
+		},
+		{
+			name: "reset window",
+			prepare: func(w *slidingWindow) {
+				w.add(start, true)
+				w.reset()
+			},
+			now:         start,
+			wantTotal:   0,
+			wantFailure: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := newSlidingWindow(10 * time.Second)
+			test.prepare(w)
+
+			total, fails := w.counts(test.now)
+			assert.Equal(t, test.wantTotal, total)
+			assert.Equal(t, test.wantFailure, fails)
+		})
+	}
+}
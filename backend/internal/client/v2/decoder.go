@@ -1,7 +1,12 @@
 package client
 
 import (
+	"mime"
+	"regexp"
+	"unicode/utf8"
+
 	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/encoding/traditionalchinese"
 	"golang.org/x/text/transform"
@@ -13,10 +18,24 @@ const (
 	DecodeMethodGBK  DecodeMethod = "gbk"
 	DecodeMethodBig5 DecodeMethod = "big5"
 	DecodeMethodUTF8 DecodeMethod = "utf8"
+	DecodeMethodAuto DecodeMethod = "auto"
 )
 
+// charsets compatible with each decode method, using the names of htmlindex
+var compatibleCharsets = map[DecodeMethod][]string{
+	DecodeMethodGBK:  {"gbk", "gb18030"},
+	DecodeMethodBig5: {"big5"},
+	DecodeMethodUTF8: {"utf-8"},
+}
+
+// only the head of html is searched for meta charset, as browsers do
+const metaCharsetSearchLength = 1024
+
+var metaCharsetRegex = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([\w-]+)`)
+
 type Decoder struct {
 	decoder *encoding.Decoder
+	method  DecodeMethod
 }
 
 func NewDecoder(decodeMethod DecodeMethod) Decoder {
@@ -30,13 +49,94 @@ func NewDecoder(decodeMethod DecodeMethod) Decoder {
 		decoder = nil
 	}
 
-	return Decoder{decoder: decoder}
+	return Decoder{decoder: decoder, method: decodeMethod}
+}
+
+// DetectCharset return the charset declared by the content type header or
+// the meta tag of html, or empty string if it is not declared or unknown
+func DetectCharset(contentType string, body string) string {
+	label := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		label = params["charset"]
+	}
+
+	if label == "" {
+		head := body
+		if len(head) > metaCharsetSearchLength {
+			head = head[:metaCharsetSearchLength]
+		}
+
+		if match := metaCharsetRegex.FindStringSubmatch(head); match != nil {
+			label = match[1]
+		}
+	}
+
+	if label == "" {
+		return ""
+	}
+
+	enc, err := htmlindex.Get(label)
+	if err != nil {
+		return ""
+	}
+
+	name, _ := htmlindex.Name(enc)
+
+	return name
+}
+
+func (decoder Decoder) Method() DecodeMethod {
+	return decoder.method
+}
+
+// Compatible report if the detected charset can be decoded by the decode
+// method. undetected charset is always compatible
+func (decoder Decoder) Compatible(charset string) bool {
+	charsets, ok := compatibleCharsets[decoder.method]
+	if !ok || charset == "" {
+		return true
+	}
+
+	for _, compatibleCharset := range charsets {
+		if compatibleCharset == charset {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (decoder Decoder) Decode(str string) (string, error) {
+	return decoder.DecodeResponse(str, "")
+}
+
+// DecodeResponse decode the body with the configured decode method. auto
+// method decode the body with the detected charset, and fallback to GB18030
+// for undetected non utf8 body
+func (decoder Decoder) DecodeResponse(str string, contentType string) (string, error) {
+	if decoder.method == DecodeMethodAuto {
+		return decodeAuto(str, DetectCharset(contentType, str))
+	}
+
 	if decoder.decoder == nil {
 		return str, nil
 	}
 	str, _, err := transform.String(decoder.decoder, str)
 	return str, err
 }
+
+func decodeAuto(str string, charset string) (string, error) {
+	var enc encoding.Encoding
+	if charset != "" {
+		enc, _ = htmlindex.Get(charset)
+	} else if !utf8.ValidString(str) {
+		enc = simplifiedchinese.GB18030
+	}
+
+	if enc == nil || charset == "utf-8" {
+		return str, nil
+	}
+
+	str, _, err := transform.String(enc.NewDecoder(), str)
+	return str, err
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/hex"
+	"strings"
 	"testing"
 
 	"golang.org/x/text/encoding/simplifiedchinese"
@@ -78,6 +79,20 @@ func TestDecoder_Decode(t *testing.T) {
 			want:       "一",
 			wantErr:    false,
 		},
+		{
+			name:       "decode utf8 string with auto decoder",
+			decoder:    NewDecoder("auto"),
+			inputBytes: "e4b880",
+			want:       "一",
+			wantErr:    false,
+		},
+		{
+			name:       "decode undeclared gbk string with auto decoder",
+			decoder:    NewDecoder("auto"),
+			inputBytes: "d2bbb6fe",
+			want:       "一二",
+			wantErr:    false,
+		},
 	}
 
 	for _, test := range tests {
@@ -96,3 +111,140 @@ func TestDecoder_Decode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_DecodeResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		decoder     Decoder
+		inputBytes  string
+		contentType string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "decode with charset of content type",
+			decoder:     NewDecoder("auto"),
+			inputBytes:  "a440",
+			contentType: "text/html; charset=big5",
+			want:        "一",
+			wantErr:     false,
+		},
+		{
+			name:        "decode with charset of html meta",
+			decoder:     NewDecoder("auto"),
+			inputBytes:  hex.EncodeToString([]byte(`<meta charset="gbk">`)) + "d2bb",
+			contentType: "text/html",
+			want:        `<meta charset="gbk">一`,
+			wantErr:     false,
+		},
+		{
+			name:        "decode with configured method regardless of content type",
+			decoder:     NewDecoder("gbk"),
+			inputBytes:  "d2bb",
+			contentType: "text/html; charset=big5",
+			want:        "一",
+			wantErr:     false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			hexByte, _ := hex.DecodeString(test.inputBytes)
+			got, err := test.decoder.DecodeResponse(string(hexByte), test.contentType)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Decoder.DecodeResponse() return err %v, wantErr %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Decoder.DecodeResponse() return %v; want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDetectCharset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "detect charset from content type",
+			contentType: "text/html; charset=GB2312",
+			body:        `<meta charset="big5">`,
+			want:        "gbk",
+		},
+		{
+			name:        "detect charset from html5 meta",
+			contentType: "text/html",
+			body:        `<html><head><meta charset="big5"></head>`,
+			want:        "big5",
+		},
+		{
+			name:        "detect charset from http-equiv meta",
+			contentType: "",
+			body:        `<meta http-equiv="Content-Type" content="text/html; charset=utf-8" />`,
+			want:        "utf-8",
+		},
+		{
+			name:        "ignore meta charset after the head of html",
+			contentType: "",
+			body:        strings.Repeat(" ", metaCharsetSearchLength) + `<meta charset="big5">`,
+			want:        "",
+		},
+		{
+			name:        "return empty string for unknown charset",
+			contentType: "text/html; charset=unknown",
+			body:        "",
+			want:        "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := DetectCharset(test.contentType, test.body)
+			if got != test.want {
+				t.Errorf("DetectCharset() return %v; want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecoder_Compatible(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		decoder Decoder
+		charset string
+		want    bool
+	}{
+		{name: "gbk decoder with gb18030", decoder: NewDecoder("gbk"), charset: "gb18030", want: true},
+		{name: "gbk decoder with big5", decoder: NewDecoder("gbk"), charset: "big5", want: false},
+		{name: "utf8 decoder with utf-8", decoder: NewDecoder("utf8"), charset: "utf-8", want: true},
+		{name: "big5 decoder with undetected charset", decoder: NewDecoder("big5"), charset: "", want: true},
+		{name: "auto decoder with any charset", decoder: NewDecoder("auto"), charset: "big5", want: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := test.decoder.Compatible(test.charset)
+			if got != test.want {
+				t.Errorf("Decoder.Compatible() return %v; want: %v", got, test.want)
+			}
+		})
+	}
+}
@@ -16,5 +16,6 @@ func (err StatusCodeError) Error() string {
 }
 
 var (
-	ErrTimeout = errors.New("request timeout")
+	ErrTimeout     = errors.New("request timeout")
+	ErrNotModified = errors.New("not modified")
 )
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/rs/zerolog"
+)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type RateLimitClient struct {
+	conf    *RateLimitClientConfig
+	client  client.BookClient
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+var _ client.BookClient = (*RateLimitClient)(nil)
+
+func NewClient(conf *RateLimitClientConfig, bookClient client.BookClient) *RateLimitClient {
+	return &RateLimitClient{
+		conf:    conf,
+		client:  bookClient,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (c *RateLimitClient) burst() float64 {
+	if c.conf.Burst < 1 {
+		return 1
+	}
+
+	return float64(c.conf.Burst)
+}
+
+// reserve take a token from the bucket of host and return the duration to
+// wait until the token is available. tokens can go below zero, so concurrent
+// requests to the same host queue up behind each other
+func (c *RateLimitClient) reserve(host string, now time.Time) time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	bucket, ok := c.buckets[host]
+	if !ok {
+		bucket = &tokenBucket{tokens: c.burst(), updatedAt: now}
+		c.buckets[host] = bucket
+	}
+
+	refilled := now.Sub(bucket.updatedAt).Seconds() * c.conf.RequestsPerSecond
+	bucket.tokens = math.Min(c.burst(), bucket.tokens+refilled)
+	bucket.updatedAt = now
+	bucket.tokens--
+
+	if bucket.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-bucket.tokens / c.conf.RequestsPerSecond * float64(time.Second))
+}
+
+// cancel return the token of a request which is not sent to the bucket
+func (c *RateLimitClient) cancel(host string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if bucket, ok := c.buckets[host]; ok {
+		bucket.tokens = math.Min(c.burst(), bucket.tokens+1)
+	}
+}
+
+func (c *RateLimitClient) jitter() time.Duration {
+	if c.conf.Jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(c.conf.Jitter)))
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	return u.Host
+}
+
+func (c *RateLimitClient) Get(ctx context.Context, url string) (string, error) {
+	if c.conf.RequestsPerSecond <= 0 {
+		return c.client.Get(ctx, url)
+	}
+
+	host := hostOf(url)
+	waitDuration := c.reserve(host, time.Now()) + c.jitter()
+	if waitDuration > 0 {
+		zerolog.Ctx(ctx).Debug().
+			Str("client", "RateLimitClient").
+			Str("host", host).
+			Str("wait_duration", waitDuration.String()).
+			Msg("wait for rate limit")
+
+		select {
+		case <-ctx.Done():
+			c.cancel(host)
+
+			return "", ctx.Err()
+		case <-time.After(waitDuration):
+		}
+	}
+
+	return c.client.Get(ctx, url)
+}
@@ -0,0 +1,214 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	conf := &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 5}
+
+	got := NewClient(conf, nil)
+	assert.Equal(t, &RateLimitClient{
+		conf:    conf,
+		buckets: map[string]*tokenBucket{},
+	}, got)
+}
+
+func TestRateLimitClient_reserve(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		conf          *RateLimitClientConfig
+		prepareBucket func(*RateLimitClient)
+		host          string
+		now           time.Time
+		want          time.Duration
+		wantTokens    float64
+	}{
+		{
+			name:       "new host start with full bucket",
+			conf:       &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 3},
+			host:       "test.com",
+			now:        now,
+			want:       0,
+			wantTokens: 2,
+		},
+		{
+			name: "wait for token if bucket is empty",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 3},
+			prepareBucket: func(c *RateLimitClient) {
+				c.buckets["test.com"] = &tokenBucket{tokens: 0, updatedAt: now}
+			},
+			host:       "test.com",
+			now:        now,
+			want:       100 * time.Millisecond,
+			wantTokens: -1,
+		},
+		{
+			name: "queue up behind other waiting requests",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 3},
+			prepareBucket: func(c *RateLimitClient) {
+				c.buckets["test.com"] = &tokenBucket{tokens: -2, updatedAt: now}
+			},
+			host:       "test.com",
+			now:        now,
+			want:       300 * time.Millisecond,
+			wantTokens: -3,
+		},
+		{
+			name: "refill tokens by elapsed time",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 3},
+			prepareBucket: func(c *RateLimitClient) {
+				c.buckets["test.com"] = &tokenBucket{tokens: 0, updatedAt: now}
+			},
+			host:       "test.com",
+			now:        now.Add(150 * time.Millisecond),
+			want:       0,
+			wantTokens: 0.5,
+		},
+		{
+			name: "refilled tokens not exceed burst",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 3},
+			prepareBucket: func(c *RateLimitClient) {
+				c.buckets["test.com"] = &tokenBucket{tokens: 0, updatedAt: now}
+			},
+			host:       "test.com",
+			now:        now.Add(time.Hour),
+			want:       0,
+			wantTokens: 2,
+		},
+		{
+			name: "hosts do not share bucket",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 1},
+			prepareBucket: func(c *RateLimitClient) {
+				c.buckets["other.com"] = &tokenBucket{tokens: -5, updatedAt: now}
+			},
+			host:       "test.com",
+			now:        now,
+			want:       0,
+			wantTokens: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewClient(test.conf, nil)
+			if test.prepareBucket != nil {
+				test.prepareBucket(c)
+			}
+
+			got := c.reserve(test.host, test.now)
+			assert.Equal(t, test.want, got)
+			assert.InDelta(t, test.wantTokens, c.buckets[test.host].tokens, 1e-9)
+		})
+	}
+}
+
+func TestRateLimitClient_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		conf                  *RateLimitClientConfig
+		setupClient           func(*mockclient.MockBookClient)
+		getContext            func() context.Context
+		urls                  []string
+		wantError             error
+		expectedDurationTaken time.Duration
+	}{
+		{
+			name: "not limit request if requests per second is 0",
+			conf: &RateLimitClientConfig{},
+			setupClient: func(cli *mockclient.MockBookClient) {
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/1").Return("hello", nil).Times(3)
+			},
+			urls:                  []string{"https://test.com/1", "https://test.com/1", "https://test.com/1"},
+			wantError:             nil,
+			expectedDurationTaken: 0,
+		},
+		{
+			name: "wait for token after burst is used",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 50, Burst: 2},
+			setupClient: func(cli *mockclient.MockBookClient) {
+				cli.EXPECT().Get(gomock.Any(), gomock.Any()).Return("hello", nil).Times(4)
+			},
+			urls: []string{
+				"https://test.com/1", "https://test.com/2", "https://test.com/3", "https://test.com/4",
+			},
+			wantError:             nil,
+			expectedDurationTaken: 40 * time.Millisecond,
+		},
+		{
+			name: "limit each host separately",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 50, Burst: 1},
+			setupClient: func(cli *mockclient.MockBookClient) {
+				cli.EXPECT().Get(gomock.Any(), gomock.Any()).Return("hello", nil).Times(3)
+			},
+			urls:                  []string{"https://a.com/1", "https://b.com/1", "https://c.com/1"},
+			wantError:             nil,
+			expectedDurationTaken: 0,
+		},
+		{
+			name: "stop waiting if context is cancelled",
+			conf: &RateLimitClientConfig{RequestsPerSecond: 10, Burst: 1},
+			setupClient: func(cli *mockclient.MockBookClient) {
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/1").Return("hello", nil)
+			},
+			getContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(20*time.Millisecond, cancel)
+
+				return ctx
+			},
+			urls:                  []string{"https://test.com/1", "https://test.com/2"},
+			wantError:             context.Canceled,
+			expectedDurationTaken: 20 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			bookClient := mockclient.NewMockBookClient(ctrl)
+			test.setupClient(bookClient)
+			c := NewClient(test.conf, bookClient)
+
+			ctx := context.Background()
+			if test.getContext != nil {
+				ctx = test.getContext()
+			}
+
+			var err error
+			start := time.Now()
+			for _, url := range test.urls {
+				_, err = c.Get(ctx, url)
+				if err != nil {
+					break
+				}
+			}
+			timeTaken := time.Since(start).Truncate(5 * time.Millisecond)
+
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.expectedDurationTaken, timeTaken)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package ratelimit
+
+import "time"
+
+// RateLimitClientConfig limit the requests sent to each host. the client does
+// not limit any request if RequestsPerSecond is 0
+type RateLimitClientConfig struct {
+	RequestsPerSecond float64       `yaml:"requests_per_second" validate:"min=0"`
+	Burst             int           `yaml:"burst" validate:"min=0"`
+	Jitter            time.Duration `yaml:"jitter" validate:"min=0"`
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validate_RateLimitClientConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  RateLimitClientConfig
+		valid bool
+	}{
+		{
+			name: "valid",
+			conf: RateLimitClientConfig{
+				RequestsPerSecond: 10,
+				Burst:             5,
+				Jitter:            100 * time.Millisecond,
+			},
+			valid: true,
+		},
+		{
+			name:  "valid with rate limit disabled",
+			conf:  RateLimitClientConfig{},
+			valid: true,
+		},
+		{
+			name: "invalid requests per second",
+			conf: RateLimitClientConfig{
+				RequestsPerSecond: -1,
+			},
+			valid: false,
+		},
+		{
+			name: "invalid burst",
+			conf: RateLimitClientConfig{
+				RequestsPerSecond: 10,
+				Burst:             -1,
+			},
+			valid: false,
+		},
+		{
+			name: "invalid jitter",
+			conf: RateLimitClientConfig{
+				RequestsPerSecond: 10,
+				Jitter:            -1 * time.Second,
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := validator.New().Struct(test.conf)
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Fixture is a recorded response. Body is decoded to utf8 already.
+// FinalURL is only set if the request is redirected
+type Fixture struct {
+	URL        string      `json:"url"`
+	FinalURL   string      `json:"final_url,omitempty"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Archive keep the fixtures sorted by url, so the saved file has stable diff
+// after re-recording
+type Archive struct {
+	Fixtures []Fixture `json:"fixtures"`
+}
+
+func LoadArchive(path string) (*Archive, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("decode archive: %w", err)
+	}
+
+	sort.Slice(archive.Fixtures, func(i, j int) bool {
+		return archive.Fixtures[i].URL < archive.Fixtures[j].URL
+	})
+
+	return &archive, nil
+}
+
+func (a *Archive) search(url string) int {
+	return sort.Search(len(a.Fixtures), func(i int) bool {
+		return a.Fixtures[i].URL >= url
+	})
+}
+
+func (a *Archive) Find(url string) (Fixture, bool) {
+	i := a.search(url)
+	if i < len(a.Fixtures) && a.Fixtures[i].URL == url {
+		return a.Fixtures[i], true
+	}
+
+	return Fixture{}, false
+}
+
+// Put add the fixture to archive or replace the fixture of same url
+func (a *Archive) Put(fixture Fixture) {
+	i := a.search(fixture.URL)
+	if i < len(a.Fixtures) && a.Fixtures[i].URL == fixture.URL {
+		a.Fixtures[i] = fixture
+
+		return
+	}
+
+	a.Fixtures = append(a.Fixtures, Fixture{})
+	copy(a.Fixtures[i+1:], a.Fixtures[i:])
+	a.Fixtures[i] = fixture
+}
+
+func (a *Archive) Save(path string) error {
+	// keep html in body readable, so fixture changes can be reviewed in diff
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(a); err != nil {
+		return fmt.Errorf("encode archive: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename archive: %w", err)
+	}
+
+	return nil
+}
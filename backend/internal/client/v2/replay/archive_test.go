@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive_Put(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		archive *Archive
+		fixture Fixture
+		want    *Archive
+	}{
+		{
+			name:    "add fixture to empty archive",
+			archive: &Archive{},
+			fixture: Fixture{URL: "https://test.com/b", Body: "b"},
+			want:    &Archive{Fixtures: []Fixture{{URL: "https://test.com/b", Body: "b"}}},
+		},
+		{
+			name: "keep fixtures sorted by url",
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/a", Body: "a"},
+				{URL: "https://test.com/c", Body: "c"},
+			}},
+			fixture: Fixture{URL: "https://test.com/b", Body: "b"},
+			want: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/a", Body: "a"},
+				{URL: "https://test.com/b", Body: "b"},
+				{URL: "https://test.com/c", Body: "c"},
+			}},
+		},
+		{
+			name: "replace fixture of same url",
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/a", Body: "old"},
+			}},
+			fixture: Fixture{URL: "https://test.com/a", Body: "new"},
+			want: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/a", Body: "new"},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.archive.Put(test.fixture)
+			assert.Equal(t, test.want, test.archive)
+		})
+	}
+}
+
+func TestArchive_Find(t *testing.T) {
+	t.Parallel()
+
+	archive := &Archive{Fixtures: []Fixture{
+		{URL: "https://test.com/a", Body: "a"},
+		{URL: "https://test.com/c", Body: "c"},
+	}}
+
+	got, ok := archive.Find("https://test.com/c")
+	assert.True(t, ok)
+	assert.Equal(t, Fixture{URL: "https://test.com/c", Body: "c"}, got)
+
+	got, ok = archive.Find("https://test.com/b")
+	assert.False(t, ok)
+	assert.Equal(t, Fixture{}, got)
+}
+
+func TestArchive_SaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fixtures", "test.json")
+	archive := &Archive{Fixtures: []Fixture{
+		{
+			URL:        "https://test.com/a",
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Body:       "a",
+		},
+		{URL: "https://test.com/b", StatusCode: http.StatusNotFound},
+	}}
+
+	assert.NoError(t, archive.Save(path))
+
+	got, err := LoadArchive(path)
+	assert.NoError(t, err)
+	assert.Equal(t, archive, got)
+
+	_, err = LoadArchive(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,131 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/rs/zerolog"
+)
+
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+type ReplayClient struct {
+	conf   *ReplayClientConfig
+	client client.BookClient
+
+	lock    sync.Mutex
+	archive *Archive
+	loadErr error
+}
+
+var _ client.BookClient = (*ReplayClient)(nil)
+
+func NewClient(conf *ReplayClientConfig, bookClient client.BookClient) *ReplayClient {
+	return &ReplayClient{
+		conf:   conf,
+		client: bookClient,
+	}
+}
+
+// load read the archive on first call. record mode start with an empty
+// archive if the archive file does not exist yet
+func (c *ReplayClient) load() (*Archive, error) {
+	if c.archive != nil || c.loadErr != nil {
+		return c.archive, c.loadErr
+	}
+
+	c.archive, c.loadErr = LoadArchive(c.conf.Archive)
+	if c.conf.Mode == ModeRecord && errors.Is(c.loadErr, os.ErrNotExist) {
+		c.archive, c.loadErr = &Archive{}, nil
+	}
+
+	return c.archive, c.loadErr
+}
+
+func (c *ReplayClient) replay(ctx context.Context, url string) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	archive, err := c.load()
+	if err != nil {
+		return "", err
+	}
+
+	fixture, ok := archive.Find(url)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrFixtureNotFound, url)
+	}
+
+	if info := client.ResponseInfoFromContext(ctx); info != nil {
+		info.StatusCode, info.Header = fixture.StatusCode, fixture.Header.Clone()
+		info.URL = fixture.URL
+		if fixture.FinalURL != "" {
+			info.URL = fixture.FinalURL
+		}
+	}
+
+	if fixture.StatusCode < 200 || fixture.StatusCode >= 300 {
+		return "", client.StatusCodeError{StatusCode: fixture.StatusCode}
+	}
+
+	return fixture.Body, nil
+}
+
+func (c *ReplayClient) record(ctx context.Context, url string) (string, error) {
+	info := &client.ResponseInfo{}
+	body, err := c.client.Get(client.WithResponseInfo(ctx, info), url)
+
+	// outer clients also need the response info
+	if outerInfo := client.ResponseInfoFromContext(ctx); outerInfo != nil {
+		*outerInfo = *info
+	}
+
+	// requests without response (e.g. timeout) are not recorded
+	if info.StatusCode == 0 {
+		return body, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	archive, loadErr := c.load()
+	if loadErr == nil {
+		var finalURL string
+		if info.URL != url {
+			finalURL = info.URL
+		}
+
+		archive.Put(Fixture{
+			URL:        url,
+			FinalURL:   finalURL,
+			StatusCode: info.StatusCode,
+			Header:     info.Header,
+			Body:       body,
+		})
+		loadErr = archive.Save(c.conf.Archive)
+	}
+
+	if loadErr != nil {
+		zerolog.Ctx(ctx).Warn().Err(loadErr).
+			Str("client", "ReplayClient").
+			Str("url", url).
+			Msg("record fixture failed")
+	}
+
+	return body, err
+}
+
+func (c *ReplayClient) Get(ctx context.Context, url string) (string, error) {
+	switch c.conf.Mode {
+	case ModeReplay:
+		return c.replay(ctx, url)
+	case ModeRecord:
+		return c.record(ctx, url)
+	default:
+		return c.client.Get(ctx, url)
+	}
+}
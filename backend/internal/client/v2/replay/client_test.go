@@ -0,0 +1,203 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	conf := &ReplayClientConfig{Mode: ModeReplay, Archive: "fixtures.json"}
+
+	got := NewClient(conf, nil)
+	assert.Equal(t, &ReplayClient{conf: conf}, got)
+}
+
+func TestReplayClient_Get(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://test.com/book/1"
+
+	tests := []struct {
+		name        string
+		mode        Mode
+		archive     *Archive
+		setupClient func(*gomock.Controller) client.BookClient
+		want        string
+		wantError   error
+		wantArchive *Archive
+		wantInfo    client.ResponseInfo
+	}{
+		{
+			name: "pass through if replay is disabled",
+			mode: ModeOff,
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("body", nil)
+
+				return cli
+			},
+			want: "body",
+		},
+		{
+			name: "replay recorded body",
+			mode: ModeReplay,
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: url, StatusCode: http.StatusOK, Body: "recorded"},
+			}},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				return mockclient.NewMockBookClient(ctrl)
+			},
+			want:     "recorded",
+			wantInfo: client.ResponseInfo{StatusCode: http.StatusOK, URL: url},
+		},
+		{
+			name: "replay redirected response",
+			mode: ModeReplay,
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: url, FinalURL: "https://test.com/removed", StatusCode: http.StatusOK, Body: "removed"},
+			}},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				return mockclient.NewMockBookClient(ctrl)
+			},
+			want:     "removed",
+			wantInfo: client.ResponseInfo{StatusCode: http.StatusOK, URL: "https://test.com/removed"},
+		},
+		{
+			name: "replay recorded status code error",
+			mode: ModeReplay,
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: url, StatusCode: http.StatusBadGateway, Header: http.Header{"Retry-After": []string{"10"}}},
+			}},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				return mockclient.NewMockBookClient(ctrl)
+			},
+			want:      "",
+			wantError: client.StatusCodeError{StatusCode: http.StatusBadGateway},
+			wantInfo: client.ResponseInfo{
+				StatusCode: http.StatusBadGateway,
+				Header:     http.Header{"Retry-After": []string{"10"}},
+				URL:        url,
+			},
+		},
+		{
+			name:    "return error if fixture not found",
+			mode:    ModeReplay,
+			archive: &Archive{},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				return mockclient.NewMockBookClient(ctrl)
+			},
+			want:      "",
+			wantError: ErrFixtureNotFound,
+		},
+		{
+			name: "record response to new archive",
+			mode: ModeRecord,
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).DoAndReturn(
+					func(ctx context.Context, _ string) (string, error) {
+						info := client.ResponseInfoFromContext(ctx)
+						info.StatusCode = http.StatusOK
+						info.Header = http.Header{"Etag": []string{`"etag"`}}
+						info.URL = "https://test.com/book/1/"
+
+						return "body", nil
+					},
+				)
+
+				return cli
+			},
+			want: "body",
+			wantArchive: &Archive{Fixtures: []Fixture{
+				{
+					URL:        url,
+					FinalURL:   "https://test.com/book/1/",
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Etag": []string{`"etag"`}},
+					Body:       "body",
+				},
+			}},
+			wantInfo: client.ResponseInfo{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Etag": []string{`"etag"`}},
+				URL:        "https://test.com/book/1/",
+			},
+		},
+		{
+			name: "record status code error to existing archive",
+			mode: ModeRecord,
+			archive: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/book/0", StatusCode: http.StatusOK, Body: "other"},
+			}},
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).DoAndReturn(
+					func(ctx context.Context, _ string) (string, error) {
+						client.ResponseInfoFromContext(ctx).StatusCode = http.StatusNotFound
+
+						return "", client.StatusCodeError{StatusCode: http.StatusNotFound}
+					},
+				)
+
+				return cli
+			},
+			want:      "",
+			wantError: client.StatusCodeError{StatusCode: http.StatusNotFound},
+			wantArchive: &Archive{Fixtures: []Fixture{
+				{URL: "https://test.com/book/0", StatusCode: http.StatusOK, Body: "other"},
+				{URL: url, StatusCode: http.StatusNotFound},
+			}},
+			wantInfo: client.ResponseInfo{StatusCode: http.StatusNotFound},
+		},
+		{
+			name: "not record request without response",
+			mode: ModeRecord,
+			setupClient: func(ctrl *gomock.Controller) client.BookClient {
+				cli := mockclient.NewMockBookClient(ctrl)
+				cli.EXPECT().Get(gomock.Any(), url).Return("", client.ErrTimeout)
+
+				return cli
+			},
+			want:      "",
+			wantError: client.ErrTimeout,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			if test.archive != nil {
+				assert.NoError(t, test.archive.Save(path))
+			}
+
+			cli := NewClient(&ReplayClientConfig{Mode: test.mode, Archive: path}, test.setupClient(ctrl))
+
+			var info client.ResponseInfo
+			got, err := cli.Get(client.WithResponseInfo(context.Background(), &info), url)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantInfo, info)
+
+			if test.wantArchive != nil {
+				archive, err := LoadArchive(path)
+				assert.NoError(t, err)
+				assert.Equal(t, test.wantArchive, archive)
+			}
+		})
+	}
+}
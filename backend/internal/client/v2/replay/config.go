@@ -0,0 +1,16 @@
+package replay
+
+type Mode string
+
+const (
+	ModeOff    Mode = ""
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+)
+
+// ReplayClientConfig record the responses to the Archive file in record mode
+// and serve the recorded responses without network in replay mode
+type ReplayClientConfig struct {
+	Mode    Mode   `yaml:"mode" validate:"omitempty,oneof=record replay"`
+	Archive string `yaml:"archive" validate:"required_with=Mode"`
+}
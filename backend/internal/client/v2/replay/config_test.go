@@ -0,0 +1,53 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validate_ReplayClientConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  ReplayClientConfig
+		valid bool
+	}{
+		{
+			name:  "valid record mode",
+			conf:  ReplayClientConfig{Mode: ModeRecord, Archive: "fixtures.json"},
+			valid: true,
+		},
+		{
+			name:  "valid replay mode",
+			conf:  ReplayClientConfig{Mode: ModeReplay, Archive: "fixtures.json"},
+			valid: true,
+		},
+		{
+			name:  "valid with replay disabled",
+			conf:  ReplayClientConfig{},
+			valid: true,
+		},
+		{
+			name:  "invalid mode",
+			conf:  ReplayClientConfig{Mode: "unknown", Archive: "fixtures.json"},
+			valid: false,
+		},
+		{
+			name:  "invalid missing archive",
+			conf:  ReplayClientConfig{Mode: ModeReplay},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := validator.New().Struct(test.conf)
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
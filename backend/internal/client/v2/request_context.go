@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+type URLClass string
+
+const (
+	URLClassUnknown     URLClass = ""
+	URLClassBook        URLClass = "book"
+	URLClassChapterList URLClass = "chapter_list"
+	URLClassChapter     URLClass = "chapter"
+)
+
+// Validators are the http cache validators of a response. when it is attached
+// to the request context, the http client send them as conditional request
+// headers and update them with the validators of the response
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ResponseInfo is the details of a response. when it is attached to the
+// request context, the http client fill it after receiving response
+type ResponseInfo struct {
+	StatusCode int
+	Header     http.Header
+	// URL is the final url after redirects
+	URL string
+	// Raw is the body before decoding
+	Raw []byte
+	// FromCache is true if the body is served by cache instead of the site
+	FromCache bool
+}
+
+// FailCheck report if the response is considered as failed by the layer
+// which attached it to the request context
+type FailCheck func(body string, err error) bool
+
+type urlClassKey struct{}
+type validatorsKey struct{}
+type responseInfoKey struct{}
+type failCheckKey struct{}
+type refererKey struct{}
+
+// WithURLClass mark the kind of page the request is fetching
+func WithURLClass(ctx context.Context, class URLClass) context.Context {
+	return context.WithValue(ctx, urlClassKey{}, class)
+}
+
+func URLClassFromContext(ctx context.Context) URLClass {
+	class, _ := ctx.Value(urlClassKey{}).(URLClass)
+
+	return class
+}
+
+func WithValidators(ctx context.Context, validators *Validators) context.Context {
+	return context.WithValue(ctx, validatorsKey{}, validators)
+}
+
+func ValidatorsFromContext(ctx context.Context) *Validators {
+	validators, _ := ctx.Value(validatorsKey{}).(*Validators)
+
+	return validators
+}
+
+func WithResponseInfo(ctx context.Context, info *ResponseInfo) context.Context {
+	return context.WithValue(ctx, responseInfoKey{}, info)
+}
+
+func ResponseInfoFromContext(ctx context.Context) *ResponseInfo {
+	info, _ := ctx.Value(responseInfoKey{}).(*ResponseInfo)
+
+	return info
+}
+
+func WithFailCheck(ctx context.Context, check FailCheck) context.Context {
+	return context.WithValue(ctx, failCheckKey{}, check)
+}
+
+func FailCheckFromContext(ctx context.Context) FailCheck {
+	check, _ := ctx.Value(failCheckKey{}).(FailCheck)
+
+	return check
+}
+
+// WithReferer set the page linking to the requested url, so the http client
+// can send it as referer
+func WithReferer(ctx context.Context, referer string) context.Context {
+	return context.WithValue(ctx, refererKey{}, referer)
+}
+
+func RefererFromContext(ctx context.Context) string {
+	referer, _ := ctx.Value(refererKey{}).(string)
+
+	return referer
+}
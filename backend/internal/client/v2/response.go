@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Response is the result of a request with the details discarded by Get
+type Response struct {
+	Body       string
+	Raw        []byte
+	StatusCode int
+	Header     http.Header
+	// URL is the final url after redirects
+	URL       string
+	Elapsed   time.Duration
+	FromCache bool
+}
+
+// ResponseClient is the BookClient returning the full response
+type ResponseClient interface {
+	BookClient
+	GetResponse(ctx context.Context, url string) (*Response, error)
+}
+
+// GetResponse call Get of cli and collect the response details filled by the
+// inner clients. Raw is nil if the body is not fetched from the site, e.g.
+// served by cache
+func GetResponse(ctx context.Context, cli BookClient, url string) (*Response, error) {
+	info := ResponseInfoFromContext(ctx)
+	if info == nil {
+		info = &ResponseInfo{}
+		ctx = WithResponseInfo(ctx, info)
+	}
+
+	start := time.Now()
+	body, err := cli.Get(ctx, url)
+
+	return &Response{
+		Body:       body,
+		Raw:        info.Raw,
+		StatusCode: info.StatusCode,
+		Header:     info.Header,
+		URL:        info.URL,
+		Elapsed:    time.Since(start),
+		FromCache:  info.FromCache,
+	}, err
+}
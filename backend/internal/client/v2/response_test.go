@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bookClientFunc func(ctx context.Context, url string) (string, error)
+
+func (f bookClientFunc) Get(ctx context.Context, url string) (string, error) {
+	return f(ctx, url)
+}
+
+func TestGetResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cli       BookClient
+		want      *Response
+		wantError error
+	}{
+		{
+			name: "collect response info",
+			cli: bookClientFunc(func(ctx context.Context, url string) (string, error) {
+				info := ResponseInfoFromContext(ctx)
+				info.StatusCode = http.StatusOK
+				info.Header = http.Header{"X-Test": []string{"test"}}
+				info.URL = "https://test.com/final"
+				info.Raw = []byte("raw")
+
+				return "body", nil
+			}),
+			want: &Response{
+				Body:       "body",
+				Raw:        []byte("raw"),
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Test": []string{"test"}},
+				URL:        "https://test.com/final",
+			},
+		},
+		{
+			name: "return response with error",
+			cli: bookClientFunc(func(ctx context.Context, url string) (string, error) {
+				ResponseInfoFromContext(ctx).StatusCode = http.StatusNotFound
+
+				return "", StatusCodeError{StatusCode: http.StatusNotFound}
+			}),
+			want:      &Response{StatusCode: http.StatusNotFound},
+			wantError: StatusCodeError{StatusCode: http.StatusNotFound},
+		},
+		{
+			name: "return empty response if no response is received",
+			cli: bookClientFunc(func(ctx context.Context, url string) (string, error) {
+				return "", errors.New("some error")
+			}),
+			want:      &Response{},
+			wantError: errors.New("some error"),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := GetResponse(context.Background(), test.cli, "https://test.com")
+			if test.wantError != nil {
+				assert.EqualError(t, err, test.wantError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			got.Elapsed = 0
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGetResponse_ReuseResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	cli := bookClientFunc(func(ctx context.Context, url string) (string, error) {
+		ResponseInfoFromContext(ctx).StatusCode = http.StatusOK
+
+		return "body", nil
+	})
+
+	info := &ResponseInfo{}
+	_, err := GetResponse(WithResponseInfo(context.Background(), info), cli, "https://test.com")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, info.StatusCode)
+}
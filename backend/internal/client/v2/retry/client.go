@@ -5,6 +5,7 @@ import (
 	"time"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
 	"github.com/rs/zerolog"
 )
 
@@ -12,9 +13,10 @@ type RetryClient struct {
 	c           client.BookClient
 	RetryChecks []RetryCheck
 	conf        *RetryClientConfig
+	name        string
 }
 
-var _ client.BookClient = (*RetryClient)(nil)
+var _ client.ResponseClient = (*RetryClient)(nil)
 
 func NewClient(conf *RetryClientConfig, bookClient client.BookClient) *RetryClient {
 	c := &RetryClient{
@@ -30,15 +32,38 @@ func NewClient(conf *RetryClientConfig, bookClient client.BookClient) *RetryClie
 	return c
 }
 
+// WithName set the site name, retry weight is reported to metrics only if
+// name is set
+func (c *RetryClient) WithName(name string) *RetryClient {
+	c.name = name
+
+	return c
+}
+
 func (c *RetryClient) Get(ctx context.Context, url string) (string, error) {
 	var (
 		retryWeight = 0
 		body        string
 		err         error
+		start       = time.Now()
+		prevPause   time.Duration
 		logger      = zerolog.Ctx(ctx).With().Str("url", url).Str("client", "RetryClient").Logger()
 	)
+	defer func() {
+		if c.name != "" {
+			metrics.RetryWeight.WithLabelValues(c.name).Observe(float64(retryWeight))
+		}
+	}()
+
+	// response info is needed to respect Retry-After header
+	info := client.ResponseInfoFromContext(ctx)
+	if info == nil {
+		info = &client.ResponseInfo{}
+		ctx = client.WithResponseInfo(ctx, info)
+	}
 
 	for i := 0; retryWeight < c.conf.MaxRetryWeight; i++ {
+		*info = client.ResponseInfo{}
 		body, err = c.c.Get(ctx, url)
 
 		var (
@@ -48,6 +73,15 @@ func (c *RetryClient) Get(ctx context.Context, url string) (string, error) {
 		)
 		for _, check := range c.RetryChecks {
 			shouldRetry, weight, pauseDuration = check(i, body, err)
+			if shouldRetry {
+				pauseDuration = applyJitter(c.conf.Jitter, pauseDuration, prevPause, c.conf.jitterMaxPause())
+				// the site knows better when it is ready to serve again, but
+				// the wait is still capped as Retry-After can be arbitrary long
+				if retryAfter, ok := parseRetryAfter(info.Header, time.Now()); ok && retryAfter > pauseDuration {
+					pauseDuration = max(min(retryAfter, c.conf.maxPause()), pauseDuration)
+				}
+			}
+
 			if err != nil || shouldRetry {
 				logger.Debug().Int("count", i).
 					Err(err).Bool("should_retry", shouldRetry).
@@ -57,8 +91,22 @@ func (c *RetryClient) Get(ctx context.Context, url string) (string, error) {
 			}
 
 			if shouldRetry {
+				if c.conf.MaxRetryDuration > 0 && time.Since(start)+pauseDuration > c.conf.MaxRetryDuration {
+					logger.Debug().Int("count", i).
+						Str("max_retry_duration", c.conf.MaxRetryDuration.String()).
+						Msg("stop retrying as max retry duration is reached")
+
+					return body, err
+				}
+
 				retryWeight += weight
-				time.Sleep(pauseDuration)
+				prevPause = pauseDuration
+
+				select {
+				case <-ctx.Done():
+					return "", ctx.Err()
+				case <-time.After(pauseDuration):
+				}
 
 				break
 			}
@@ -72,3 +120,7 @@ func (c *RetryClient) Get(ctx context.Context, url string) (string, error) {
 
 	return body, err
 }
+
+func (c *RetryClient) GetResponse(ctx context.Context, url string) (*client.Response, error) {
+	return client.GetResponse(ctx, c, url)
+}
@@ -8,8 +8,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
+	"github.com/htchan/BookSpider/internal/metrics"
+	mockclient "github.com/htchan/BookSpider/internal/mock/client/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -174,6 +179,39 @@ func TestRetryClient_Get(t *testing.T) {
 			wantError:             nil,
 			expectedDurationTaken: 75 * time.Millisecond,
 		},
+		{
+			name: "stop retrying if context is cancelled during pause",
+			client: NewClient(
+				&RetryClientConfig{
+					RetryConditions: []RetryCondition{
+						{
+							Type:              RetryConditionTypeTimeout,
+							Weight:            1,
+							PauseInterval:     100 * time.Millisecond,
+							PauseIntervalType: PauseIntervalTypeConst,
+						},
+					},
+					MaxRetryWeight: 5,
+				},
+				simpleClient,
+			),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(10 * time.Millisecond)
+				w.Write([]byte("hello"))
+			},
+			args: args{
+				getContext: func() context.Context {
+					ctx, cancel := context.WithCancel(context.Background())
+					time.AfterFunc(30*time.Millisecond, cancel)
+
+					return ctx
+				},
+				url: "/test",
+			},
+			want:                  "",
+			wantError:             context.Canceled,
+			expectedDurationTaken: 30 * time.Millisecond,
+		},
 	}
 
 	for _, test := range tests {
@@ -194,3 +232,186 @@ func TestRetryClient_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryClient_Get_MaxRetryDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		pauseInterval    time.Duration
+		maxRetryDuration time.Duration
+		retryAfter       string
+		wantAttempts     int32
+		wantMaxDuration  time.Duration
+	}{
+		{
+			name:             "stop retrying if retry after exceeds max retry duration",
+			pauseInterval:    5 * time.Millisecond,
+			maxRetryDuration: 100 * time.Millisecond,
+			retryAfter:       "1",
+			wantAttempts:     1,
+			wantMaxDuration:  50 * time.Millisecond,
+		},
+		{
+			name:             "stop retrying if max retry duration is reached",
+			pauseInterval:    30 * time.Millisecond,
+			maxRetryDuration: 50 * time.Millisecond,
+			wantAttempts:     2,
+			wantMaxDuration:  80 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			cli := NewClient(
+				&RetryClientConfig{
+					RetryConditions: []RetryCondition{
+						{
+							Type:              RetryConditionTypeStatusCode,
+							Value:             []interface{}{503},
+							Weight:            1,
+							PauseInterval:     test.pauseInterval,
+							PauseIntervalType: PauseIntervalTypeConst,
+						},
+					},
+					MaxRetryWeight:   5,
+					MaxRetryDuration: test.maxRetryDuration,
+				},
+				simple.NewClient(&simple.SimpleClientConfig{RequestTimeout: time.Second}),
+			)
+
+			start := time.Now()
+			_, err := cli.Get(context.Background(), server.URL)
+			assert.ErrorIs(t, err, client.StatusCodeError{StatusCode: http.StatusServiceUnavailable})
+			assert.Equal(t, test.wantAttempts, attempts.Load())
+			assert.Less(t, time.Since(start), test.wantMaxDuration)
+		})
+	}
+}
+
+func TestRetryClient_Get_RetryAfterCappedByMaxPause(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		&RetryClientConfig{
+			RetryConditions: []RetryCondition{
+				{
+					Type:              RetryConditionTypeStatusCode,
+					Value:             []interface{}{503},
+					Weight:            1,
+					PauseInterval:     5 * time.Millisecond,
+					PauseIntervalType: PauseIntervalTypeConst,
+				},
+			},
+			MaxRetryWeight: 2,
+			MaxPause:       20 * time.Millisecond,
+		},
+		simple.NewClient(&simple.SimpleClientConfig{RequestTimeout: time.Second}),
+	)
+
+	start := time.Now()
+	_, err := cli.Get(context.Background(), server.URL)
+	assert.ErrorIs(t, err, client.StatusCodeError{StatusCode: http.StatusServiceUnavailable})
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestRetryClient_Get_RetryWeightMetrics(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookClient := mockclient.NewMockBookClient(ctrl)
+	gomock.InOrder(
+		bookClient.EXPECT().Get(gomock.Any(), "https://test.com").
+			Return("", client.StatusCodeError{StatusCode: http.StatusServiceUnavailable}),
+		bookClient.EXPECT().Get(gomock.Any(), "https://test.com").Return("body", nil),
+	)
+
+	cli := NewClient(
+		&RetryClientConfig{
+			RetryConditions: []RetryCondition{
+				{
+					Type:              RetryConditionTypeStatusCode,
+					Value:             []interface{}{503},
+					Weight:            2,
+					PauseInterval:     time.Millisecond,
+					PauseIntervalType: PauseIntervalTypeConst,
+				},
+			},
+			MaxRetryWeight: 5,
+		},
+		bookClient,
+	).WithName("test-retry-weight")
+
+	body, err := cli.Get(context.Background(), "https://test.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "body", body)
+
+	var m dto.Metric
+	metrics.RetryWeight.WithLabelValues("test-retry-weight").(prometheus.Histogram).Write(&m)
+	assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+	assert.Equal(t, 2.0, m.GetHistogram().GetSampleSum())
+}
+
+func TestRetryClient_GetResponse(t *testing.T) {
+	t.Parallel()
+
+	var i atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if i.Add(1) == 1 {
+			w.Header().Set("X-Attempt", "first")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	cli := NewClient(
+		&RetryClientConfig{
+			RetryConditions: []RetryCondition{
+				{
+					Type:              RetryConditionTypeStatusCode,
+					Value:             []interface{}{503},
+					Weight:            1,
+					PauseInterval:     time.Millisecond,
+					PauseIntervalType: PauseIntervalTypeConst,
+				},
+			},
+			MaxRetryWeight: 5,
+		},
+		simple.NewClient(&simple.SimpleClientConfig{RequestTimeout: time.Second}),
+	)
+
+	got, err := cli.GetResponse(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got.Body)
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+	assert.Empty(t, got.Header.Get("X-Attempt"))
+	assert.Equal(t, server.URL, got.URL)
+}
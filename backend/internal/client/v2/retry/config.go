@@ -29,7 +29,44 @@ const (
 	PauseIntervalTypeExponential PauseIntervalType = "exponential"
 )
 
+type JitterType string
+
+const (
+	JitterTypeNone         JitterType = "none"
+	JitterTypeFull         JitterType = "full"
+	JitterTypeDecorrelated JitterType = "decorrelated"
+)
+
 type RetryClientConfig struct {
 	RetryConditions []RetryCondition `yaml:"retry_conditions" validate:"dive"`
 	MaxRetryWeight  int              `yaml:"max_retry_weight" validate:"min=1"`
+	Jitter          JitterType       `yaml:"jitter" validate:"omitempty,oneof=none full decorrelated"`
+	// MaxRetryDuration stop retrying if the next attempt starts after the
+	// duration since the first attempt. it is not limited if it is 0
+	MaxRetryDuration time.Duration `yaml:"max_retry_duration" validate:"min=0"`
+	// MaxPause cap the pause between retries, it is 1m if it is 0. pause of
+	// retry conditions is only capped if jitter or MaxPause is configured,
+	// pause extended to Retry-After of the response is always capped
+	MaxPause time.Duration `yaml:"max_pause" validate:"min=0"`
+}
+
+const defaultMaxPause = time.Minute
+
+func (conf *RetryClientConfig) maxPause() time.Duration {
+	if conf.MaxPause > 0 {
+		return conf.MaxPause
+	}
+
+	return defaultMaxPause
+}
+
+// jitterMaxPause return the cap of pause calculated by retry conditions, it
+// is 0 if neither jitter nor MaxPause is configured, so the configured pause
+// interval is kept as is
+func (conf *RetryClientConfig) jitterMaxPause() time.Duration {
+	if conf.MaxPause <= 0 && (conf.Jitter == "" || conf.Jitter == JitterTypeNone) {
+		return 0
+	}
+
+	return conf.maxPause()
 }
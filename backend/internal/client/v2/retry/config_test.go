@@ -107,6 +107,39 @@ func Test_validate_RetryClientConfig(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			name: "valid jitter and max retry duration",
+			conf: RetryClientConfig{
+				MaxRetryWeight:   10,
+				Jitter:           JitterTypeDecorrelated,
+				MaxRetryDuration: time.Minute,
+			},
+			valid: true,
+		},
+		{
+			name: "invalid jitter",
+			conf: RetryClientConfig{
+				MaxRetryWeight: 10,
+				Jitter:         "unknown",
+			},
+			valid: false,
+		},
+		{
+			name: "invalid max retry duration",
+			conf: RetryClientConfig{
+				MaxRetryWeight:   10,
+				MaxRetryDuration: -time.Second,
+			},
+			valid: false,
+		},
+		{
+			name: "invalid max pause",
+			conf: RetryClientConfig{
+				MaxRetryWeight: 10,
+				MaxPause:       -time.Second,
+			},
+			valid: false,
+		},
 		{
 			name: "invalid retry condition",
 			conf: RetryClientConfig{
@@ -150,3 +183,43 @@ func Test_validate_RetryClientConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryClientConfig_jitterMaxPause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		conf RetryClientConfig
+		want time.Duration
+	}{
+		{
+			name: "not capped without jitter and max pause",
+			conf: RetryClientConfig{},
+			want: 0,
+		},
+		{
+			name: "not capped with none jitter",
+			conf: RetryClientConfig{Jitter: JitterTypeNone},
+			want: 0,
+		},
+		{
+			name: "default max pause with jitter",
+			conf: RetryClientConfig{Jitter: JitterTypeFull},
+			want: defaultMaxPause,
+		},
+		{
+			name: "configured max pause",
+			conf: RetryClientConfig{MaxPause: 10 * time.Second},
+			want: 10 * time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.conf.jitterMaxPause())
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package retry
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// applyJitter randomize the pause duration, so the requests failed at the
+// same time are not retried at the same time. prev is the pause duration
+// of last retry, which is used by decorrelated jitter. the result never
+// exceed maxPause unless maxPause is 0
+func applyJitter(jitter JitterType, pause, prev, maxPause time.Duration) time.Duration {
+	if pause <= 0 {
+		return pause
+	}
+
+	switch jitter {
+	case JitterTypeFull:
+		pause = time.Duration(rand.Int63n(int64(pause) + 1))
+	case JitterTypeDecorrelated:
+		upper := 3 * max(prev, pause)
+		pause += time.Duration(rand.Int63n(int64(upper-pause) + 1))
+	}
+
+	if maxPause <= 0 {
+		return pause
+	}
+
+	return min(pause, maxPause)
+}
+
+// parseRetryAfter return the duration to wait according to Retry-After
+// header, which is either delay seconds or a http date
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
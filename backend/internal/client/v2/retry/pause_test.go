@@ -0,0 +1,166 @@
+package retry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyJitter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		jitter   JitterType
+		pause    time.Duration
+		prev     time.Duration
+		maxPause time.Duration
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{
+			name:    "no jitter",
+			jitter:  JitterTypeNone,
+			pause:   time.Second,
+			wantMin: time.Second,
+			wantMax: time.Second,
+		},
+		{
+			name:    "jitter not set",
+			jitter:  "",
+			pause:   time.Second,
+			wantMin: time.Second,
+			wantMax: time.Second,
+		},
+		{
+			name:    "full jitter",
+			jitter:  JitterTypeFull,
+			pause:   time.Second,
+			wantMin: 0,
+			wantMax: time.Second,
+		},
+		{
+			name:    "decorrelated jitter for first retry",
+			jitter:  JitterTypeDecorrelated,
+			pause:   time.Second,
+			prev:    0,
+			wantMin: time.Second,
+			wantMax: 3 * time.Second,
+		},
+		{
+			name:    "decorrelated jitter grow with previous pause",
+			jitter:  JitterTypeDecorrelated,
+			pause:   time.Second,
+			prev:    2 * time.Second,
+			wantMin: time.Second,
+			wantMax: 6 * time.Second,
+		},
+		{
+			name:     "decorrelated jitter capped by max pause",
+			jitter:   JitterTypeDecorrelated,
+			pause:    time.Second,
+			prev:     time.Hour,
+			maxPause: 10 * time.Second,
+			wantMin:  time.Second,
+			wantMax:  10 * time.Second,
+		},
+		{
+			name:     "pause without jitter capped by max pause",
+			jitter:   JitterTypeNone,
+			pause:    time.Hour,
+			maxPause: 10 * time.Second,
+			wantMin:  10 * time.Second,
+			wantMax:  10 * time.Second,
+		},
+		{
+			name:    "pause not capped if max pause is 0",
+			jitter:  JitterTypeNone,
+			pause:   time.Hour,
+			wantMin: time.Hour,
+			wantMax: time.Hour,
+		},
+		{
+			name:    "zero pause",
+			jitter:  JitterTypeFull,
+			pause:   0,
+			wantMin: 0,
+			wantMax: 0,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := 0; i < 100; i++ {
+				got := applyJitter(test.jitter, test.pause, test.prev, test.maxPause)
+				assert.GreaterOrEqual(t, got, test.wantMin)
+				assert.LessOrEqual(t, got, test.wantMax)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{
+			name:   "delay seconds",
+			header: http.Header{"Retry-After": []string{"120"}},
+			want:   2 * time.Minute,
+			wantOK: true,
+		},
+		{
+			name:   "http date",
+			header: http.Header{"Retry-After": []string{"Wed, 01 Jan 2020 00:00:30 GMT"}},
+			want:   30 * time.Second,
+			wantOK: true,
+		},
+		{
+			name:   "http date in the past",
+			header: http.Header{"Retry-After": []string{"Tue, 31 Dec 2019 00:00:00 GMT"}},
+			want:   0,
+			wantOK: true,
+		},
+		{
+			name:   "negative delay seconds",
+			header: http.Header{"Retry-After": []string{"-1"}},
+			want:   0,
+			wantOK: false,
+		},
+		{
+			name:   "invalid value",
+			header: http.Header{"Retry-After": []string{"later"}},
+			want:   0,
+			wantOK: false,
+		},
+		{
+			name:   "no header",
+			header: nil,
+			want:   0,
+			wantOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRetryAfter(test.header, now)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantOK, ok)
+		})
+	}
+}
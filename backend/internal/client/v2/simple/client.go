@@ -6,46 +6,173 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/rs/zerolog"
 )
 
 type SimpleClient struct {
 	decoder client.Decoder
 	client  http.Client
+	proxies *proxyPool
+	header  *HeaderConfig
+
+	userAgentIndex atomic.Uint64
 }
 
-var _ client.BookClient = (*SimpleClient)(nil)
+var _ client.ResponseClient = (*SimpleClient)(nil)
 
 func NewClient(conf *SimpleClientConfig) *SimpleClient {
-	return &SimpleClient{
+	c := &SimpleClient{
 		decoder: client.NewDecoder(conf.DecodeMethod),
 		client:  http.Client{Timeout: conf.RequestTimeout},
+		proxies: newProxyPool(&conf.Proxy),
+		header:  &conf.Header,
+	}
+
+	if jar := newCookieJar(&conf.Cookie); jar != nil {
+		c.client.Jar = jar
+	}
+
+	if c.proxies != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = proxyFromRequest
+		c.client.Transport = transport
+	}
+
+	return c
+}
+
+func (c *SimpleClient) setHeaders(ctx context.Context, req *http.Request) {
+	for key, value := range c.header.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if len(c.header.UserAgents) > 0 {
+		index := (c.userAgentIndex.Add(1) - 1) % uint64(len(c.header.UserAgents))
+		req.Header.Set("User-Agent", c.header.UserAgents[index])
+	}
+
+	if referer := client.RefererFromContext(ctx); c.header.RefererChaining && referer != "" {
+		req.Header.Set("Referer", referer)
 	}
 }
 
 func (c *SimpleClient) Get(ctx context.Context, url string) (string, error) {
-	res, reqErr := c.client.Get(url)
+	if c.proxies == nil {
+		return c.get(ctx, url)
+	}
+
+	p, err := c.proxies.pick(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.get(context.WithValue(ctx, proxyKey{}, p), url)
+	// request stopped by caller tells nothing about the health of the proxy
+	if ctx.Err() != nil {
+		return result, err
+	}
+
+	// a proxy is unhealthy if it cannot be connected or the response is
+	// considered as failed by the outer clients, e.g. blocked by the site
+	var statusCodeErr client.StatusCodeError
+	failed := err != nil && !errors.As(err, &statusCodeErr) && !errors.Is(err, client.ErrNotModified)
+	if check := client.FailCheckFromContext(ctx); check != nil && check(result, err) {
+		failed = true
+	}
+	c.proxies.report(p, failed, time.Now())
+
+	return result, err
+}
+
+func (c *SimpleClient) GetResponse(ctx context.Context, url string) (*client.Response, error) {
+	return client.GetResponse(ctx, c, url)
+}
+
+func (c *SimpleClient) get(ctx context.Context, url string) (string, error) {
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if reqErr != nil {
+		return "", reqErr
+	}
+
+	c.setHeaders(ctx, req)
+
+	validators := client.ValidatorsFromContext(ctx)
+	if validators != nil {
+		if validators.ETag != "" {
+			req.Header.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	res, reqErr := c.client.Do(req)
+	if reqErr != nil {
+		// return the context error if caller cancelled the request, so it is
+		// not mistaken as a request timeout and retried
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+
 		var timeoutError net.Error
-		if errors.As(reqErr, &timeoutError); timeoutError.Timeout() || errors.Is(reqErr, context.DeadlineExceeded) {
+		if (errors.As(reqErr, &timeoutError) && timeoutError.Timeout()) || errors.Is(reqErr, context.DeadlineExceeded) {
 			return "", client.ErrTimeout
 		}
 
 		return "", reqErr
-	} else if res.StatusCode < 200 || res.StatusCode >= 300 {
+	}
+	defer res.Body.Close()
+
+	info := client.ResponseInfoFromContext(ctx)
+	if info != nil {
+		info.StatusCode, info.Header = res.StatusCode, res.Header.Clone()
+		info.URL = res.Request.URL.String()
+	}
+
+	if res.StatusCode == http.StatusNotModified && validators != nil {
+		return "", client.ErrNotModified
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		return "", client.StatusCodeError{StatusCode: res.StatusCode}
 	}
 
 	html, readErr := io.ReadAll(res.Body)
 	if readErr != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+
 		return "", readErr
 	}
 
-	result, decodeErr := c.decoder.Decode(string(html))
+	if info != nil {
+		info.Raw = html
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if charset := client.DetectCharset(contentType, string(html)); !c.decoder.Compatible(charset) {
+		zerolog.Ctx(ctx).Warn().
+			Str("client", "SimpleClient").
+			Str("url", url).
+			Str("decode_method", string(c.decoder.Method())).
+			Str("detected_charset", charset).
+			Msg("detected charset disagrees with decode method")
+	}
+
+	result, decodeErr := c.decoder.DecodeResponse(string(html), contentType)
 	if decodeErr != nil {
 		return "", decodeErr
 	}
 
+	if validators != nil {
+		validators.ETag = res.Header.Get("ETag")
+		validators.LastModified = res.Header.Get("Last-Modified")
+	}
+
 	return result, nil
 }
@@ -3,8 +3,10 @@ package simple
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -31,6 +33,7 @@ func TestNewClient(t *testing.T) {
 					Timeout: 1 * time.Second,
 				},
 				decoder: client.NewDecoder(client.DecodeMethodBig5),
+				header:  &HeaderConfig{},
 			},
 		},
 	}
@@ -87,6 +90,22 @@ func TestSimpleClient_Get(t *testing.T) {
 			want:       "一",
 			wantError:  nil,
 		},
+		{
+			name: "happy path/auto decode method",
+			client: NewClient(&SimpleClientConfig{
+				RequestTimeout: 1 * time.Second,
+				DecodeMethod:   client.DecodeMethodAuto,
+			}),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=big5")
+				hexByte, _ := hex.DecodeString("a440")
+				w.Write(hexByte)
+			},
+			getContext: func() context.Context { return context.Background() },
+			url:        "/test",
+			want:       "一",
+			wantError:  nil,
+		},
 		{
 			name: "return status code error",
 			client: NewClient(&SimpleClientConfig{
@@ -116,6 +135,79 @@ func TestSimpleClient_Get(t *testing.T) {
 			want:       "",
 			wantError:  client.ErrTimeout,
 		},
+		{
+			name: "return context error if context is cancelled",
+			client: NewClient(&SimpleClientConfig{
+				RequestTimeout: 1 * time.Second,
+				DecodeMethod:   client.DecodeMethodUTF8,
+			}),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("hello"))
+			},
+			getContext: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				return ctx
+			},
+			url:       "/test",
+			want:      "",
+			wantError: context.Canceled,
+		},
+		{
+			name: "return context error if context deadline exceeded",
+			client: NewClient(&SimpleClientConfig{
+				RequestTimeout: 1 * time.Second,
+				DecodeMethod:   client.DecodeMethodUTF8,
+			}),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(20 * time.Millisecond)
+				w.Write([]byte("hello"))
+			},
+			getContext: func() context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+				time.AfterFunc(time.Second, cancel)
+
+				return ctx
+			},
+			url:       "/test",
+			want:      "",
+			wantError: context.DeadlineExceeded,
+		},
+		{
+			name: "return not modified error if validators match",
+			client: NewClient(&SimpleClientConfig{
+				RequestTimeout: 1 * time.Second,
+				DecodeMethod:   client.DecodeMethodUTF8,
+			}),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("If-None-Match") == `"etag"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Write([]byte("hello"))
+			},
+			getContext: func() context.Context {
+				return client.WithValidators(context.Background(), &client.Validators{ETag: `"etag"`})
+			},
+			url:       "/test",
+			want:      "",
+			wantError: client.ErrNotModified,
+		},
+		{
+			name: "return status code error for not modified without validators",
+			client: NewClient(&SimpleClientConfig{
+				RequestTimeout: 1 * time.Second,
+				DecodeMethod:   client.DecodeMethodUTF8,
+			}),
+			serverHandler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotModified)
+			},
+			getContext: func() context.Context { return context.Background() },
+			url:        "/test",
+			want:       "",
+			wantError:  client.StatusCodeError{StatusCode: http.StatusNotModified},
+		},
 	}
 
 	for _, test := range tests {
@@ -132,3 +224,274 @@ func TestSimpleClient_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestSimpleClient_Get_Validators(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `"old etag"`, r.Header.Get("If-None-Match"))
+		assert.Equal(t, "Sat, 17 Oct 2026 00:00:00 GMT", r.Header.Get("If-Modified-Since"))
+
+		w.Header().Set("ETag", `"new etag"`)
+		w.Header().Set("Last-Modified", "Sun, 18 Oct 2026 00:00:00 GMT")
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	validators := &client.Validators{
+		ETag:         `"old etag"`,
+		LastModified: "Sat, 17 Oct 2026 00:00:00 GMT",
+	}
+	cli := NewClient(&SimpleClientConfig{
+		RequestTimeout: 1 * time.Second,
+		DecodeMethod:   client.DecodeMethodUTF8,
+	})
+
+	got, err := cli.Get(client.WithValidators(context.Background(), validators), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got)
+	assert.Equal(t, &client.Validators{
+		ETag:         `"new etag"`,
+		LastModified: "Sun, 18 Oct 2026 00:00:00 GMT",
+	}, validators)
+}
+
+func TestSimpleClient_Get_ResponseInfo(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "test")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	info := &client.ResponseInfo{}
+	cli := NewClient(&SimpleClientConfig{
+		RequestTimeout: 1 * time.Second,
+		DecodeMethod:   client.DecodeMethodUTF8,
+	})
+
+	_, err := cli.Get(client.WithResponseInfo(context.Background(), info), server.URL)
+	assert.ErrorIs(t, err, client.StatusCodeError{StatusCode: http.StatusNotFound})
+	assert.Equal(t, http.StatusNotFound, info.StatusCode)
+	assert.Equal(t, "test", info.Header.Get("X-Test"))
+}
+
+func TestSimpleClient_GetResponse(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/book/1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/removed", http.StatusFound)
+	})
+	mux.HandleFunc("/removed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "test")
+		hexByte, _ := hex.DecodeString("a440")
+		w.Write(hexByte)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cli := NewClient(&SimpleClientConfig{
+		RequestTimeout: 1 * time.Second,
+		DecodeMethod:   client.DecodeMethodBig5,
+	})
+
+	got, err := cli.GetResponse(context.Background(), server.URL+"/book/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "一", got.Body)
+	assert.Equal(t, []byte{0xa4, 0x40}, got.Raw)
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+	assert.Equal(t, "test", got.Header.Get("X-Test"))
+	assert.Equal(t, server.URL+"/removed", got.URL)
+	assert.Greater(t, got.Elapsed, time.Duration(0))
+}
+
+func TestSimpleClient_Get_Proxy(t *testing.T) {
+	t.Parallel()
+
+	// the proxy receive the request of target url and response on its behalf
+	newProxy := func(statusCode int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statusCode)
+			w.Write([]byte("from " + r.URL.String()))
+		}))
+	}
+
+	t.Run("send request through proxy", func(t *testing.T) {
+		t.Parallel()
+
+		proxyServer := newProxy(http.StatusOK)
+		defer proxyServer.Close()
+
+		cli := NewClient(&SimpleClientConfig{
+			RequestTimeout: time.Second,
+			DecodeMethod:   client.DecodeMethodUTF8,
+			Proxy:          ProxyConfig{URLs: []string{proxyServer.URL}},
+		})
+
+		got, err := cli.Get(context.Background(), "http://vendor.test/book/1")
+		assert.NoError(t, err)
+		assert.Equal(t, "from http://vendor.test/book/1", got)
+		assert.Equal(t, 0, cli.proxies.proxies[0].totalFail)
+	})
+
+	t.Run("evict proxy failed by fail check", func(t *testing.T) {
+		t.Parallel()
+
+		proxyServer := newProxy(http.StatusForbidden)
+		defer proxyServer.Close()
+
+		cli := NewClient(&SimpleClientConfig{
+			RequestTimeout: time.Second,
+			DecodeMethod:   client.DecodeMethodUTF8,
+			Proxy:          ProxyConfig{URLs: []string{proxyServer.URL}, MaxFailCount: 1},
+		})
+		ctx := client.WithFailCheck(context.Background(), func(_ string, err error) bool {
+			return errors.Is(err, client.StatusCodeError{StatusCode: http.StatusForbidden})
+		})
+
+		_, err := cli.Get(ctx, "http://vendor.test/book/1")
+		assert.ErrorIs(t, err, client.StatusCodeError{StatusCode: http.StatusForbidden})
+
+		_, err = cli.Get(ctx, "http://vendor.test/book/1")
+		assert.ErrorIs(t, err, ErrNoProxyAvailable)
+	})
+
+	t.Run("count unreachable proxy as failure", func(t *testing.T) {
+		t.Parallel()
+
+		proxyServer := newProxy(http.StatusOK)
+		proxyServer.Close()
+
+		cli := NewClient(&SimpleClientConfig{
+			RequestTimeout: time.Second,
+			DecodeMethod:   client.DecodeMethodUTF8,
+			Proxy:          ProxyConfig{URLs: []string{proxyServer.URL}},
+		})
+
+		_, err := cli.Get(context.Background(), "http://vendor.test/book/1")
+		assert.Error(t, err)
+		assert.Equal(t, 1, cli.proxies.proxies[0].totalFail)
+	})
+}
+
+func TestSimpleClient_Get_Headers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		header      HeaderConfig
+		getContext  func() context.Context
+		wantHeaders []http.Header
+	}{
+		{
+			name: "set configured headers",
+			header: HeaderConfig{
+				Headers: map[string]string{"Accept-Language": "zh-TW", "User-Agent": "default agent"},
+			},
+			getContext: func() context.Context { return context.Background() },
+			wantHeaders: []http.Header{
+				{"Accept-Language": []string{"zh-TW"}, "User-Agent": []string{"default agent"}},
+				{"Accept-Language": []string{"zh-TW"}, "User-Agent": []string{"default agent"}},
+			},
+		},
+		{
+			name: "rotate user agents",
+			header: HeaderConfig{
+				Headers:    map[string]string{"User-Agent": "default agent"},
+				UserAgents: []string{"agent 1", "agent 2"},
+			},
+			getContext: func() context.Context { return context.Background() },
+			wantHeaders: []http.Header{
+				{"User-Agent": []string{"agent 1"}},
+				{"User-Agent": []string{"agent 2"}},
+				{"User-Agent": []string{"agent 1"}},
+			},
+		},
+		{
+			name:   "send referer from context if referer chaining is enabled",
+			header: HeaderConfig{RefererChaining: true},
+			getContext: func() context.Context {
+				return client.WithReferer(context.Background(), "https://test.com/chapter-list")
+			},
+			wantHeaders: []http.Header{
+				{"Referer": []string{"https://test.com/chapter-list"}},
+			},
+		},
+		{
+			name:   "not send referer if referer chaining is disabled",
+			header: HeaderConfig{},
+			getContext: func() context.Context {
+				return client.WithReferer(context.Background(), "https://test.com/chapter-list")
+			},
+			wantHeaders: []http.Header{
+				{"Referer": nil},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			headers := make(chan http.Header, len(test.wantHeaders))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers <- r.Header.Clone()
+			}))
+			defer server.Close()
+
+			cli := NewClient(&SimpleClientConfig{
+				RequestTimeout: time.Second,
+				DecodeMethod:   client.DecodeMethodUTF8,
+				Header:         test.header,
+			})
+
+			for _, want := range test.wantHeaders {
+				_, err := cli.Get(test.getContext(), server.URL)
+				assert.NoError(t, err)
+
+				got := <-headers
+				for key, value := range want {
+					assert.Equal(t, value, got.Values(key), key)
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleClient_Get_Cookie(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err == nil {
+			w.Write([]byte(cookie.Value))
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "test-session", MaxAge: 3600})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	conf := &SimpleClientConfig{
+		RequestTimeout: time.Second,
+		DecodeMethod:   client.DecodeMethodUTF8,
+		Cookie:         CookieConfig{Enabled: true, Path: path},
+	}
+
+	cli := NewClient(conf)
+	got, err := cli.Get(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "", got)
+
+	got, err = cli.Get(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-session", got)
+
+	// new client load the cookies saved by previous client
+	got, err = NewClient(conf).Get(context.Background(), server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-session", got)
+}
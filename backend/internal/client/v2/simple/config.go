@@ -8,5 +8,42 @@ import (
 
 type SimpleClientConfig struct {
 	RequestTimeout time.Duration       `yaml:"request_timeout" validate:"min=1s"`
-	DecodeMethod   client.DecodeMethod `yaml:"decode_method" validate:"oneof=gbk big5 utf8"`
+	DecodeMethod   client.DecodeMethod `yaml:"decode_method" validate:"oneof=gbk big5 utf8 auto"`
+	Proxy          ProxyConfig         `yaml:"proxy"`
+	Header         HeaderConfig        `yaml:"header"`
+	Cookie         CookieConfig        `yaml:"cookie"`
+}
+
+// HeaderConfig set Headers to every request. UserAgents are rotated across
+// requests and override the User-Agent in Headers. RefererChaining send the
+// referer from request context, e.g. chapter list url for chapter requests
+type HeaderConfig struct {
+	Headers         map[string]string `yaml:"headers"`
+	UserAgents      []string          `yaml:"user_agents" validate:"dive,min=1"`
+	RefererChaining bool              `yaml:"referer_chaining"`
+}
+
+// CookieConfig keep the cookies set by the sites across requests. cookies
+// are also saved to Path if it is not empty, so they survive restart
+type CookieConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+type ProxyStrategy string
+
+const (
+	ProxyStrategyRoundRobin   ProxyStrategy = "round-robin"
+	ProxyStrategyLeastFailure ProxyStrategy = "least-failure"
+)
+
+// ProxyConfig send requests through the proxies in URLs. a proxy is evicted
+// for EvictDuration after MaxFailCount consecutive failed requests, or until
+// restart if EvictDuration is 0. proxies are never evicted if MaxFailCount
+// is 0
+type ProxyConfig struct {
+	URLs          []string      `yaml:"urls" validate:"dive,url,startswith=http://|startswith=https://|startswith=socks5://"`
+	Strategy      ProxyStrategy `yaml:"strategy" validate:"omitempty,oneof=round-robin least-failure"`
+	MaxFailCount  int           `yaml:"max_fail_count" validate:"min=0"`
+	EvictDuration time.Duration `yaml:"evict_duration" validate:"min=0"`
 }
@@ -0,0 +1,156 @@
+package simple
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// cookieJar keep cookies in memory with the standard jar, and save the
+// cookies of each site to file so they can be loaded after restart
+type cookieJar struct {
+	jar     *cookiejar.Jar
+	path    string
+	lock    sync.Mutex
+	cookies map[string][]*http.Cookie
+}
+
+var _ http.CookieJar = (*cookieJar)(nil)
+
+func newCookieJar(conf *CookieConfig) *cookieJar {
+	if !conf.Enabled {
+		return nil
+	}
+
+	jar, _ := cookiejar.New(nil)
+	c := &cookieJar{
+		jar:     jar,
+		path:    conf.Path,
+		cookies: make(map[string][]*http.Cookie),
+	}
+
+	if err := c.load(); err != nil {
+		log.Error().Err(err).Str("path", c.path).Msg("load cookies failed")
+	}
+
+	return c
+}
+
+func siteKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+func (c *cookieJar) load() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read cookies: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &c.cookies); err != nil {
+		return fmt.Errorf("decode cookies: %w", err)
+	}
+
+	for key, cookies := range c.cookies {
+		u, err := url.Parse(key)
+		if err != nil {
+			continue
+		}
+
+		c.jar.SetCookies(u, cookies)
+	}
+
+	return nil
+}
+
+func (c *cookieJar) save() error {
+	data, err := json.Marshal(c.cookies)
+	if err != nil {
+		return fmt.Errorf("encode cookies: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return fmt.Errorf("create cookies dir: %w", err)
+	}
+
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("write cookies: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("rename cookies: %w", err)
+	}
+
+	return nil
+}
+
+// merge replace the stored cookies with the new cookies of same name, and
+// drop the expired ones. max age is converted to expiry time, so it is still
+// correct after loading from file
+func merge(stored, cookies []*http.Cookie, now time.Time) []*http.Cookie {
+	for _, cookie := range cookies {
+		cookie := *cookie
+		if cookie.MaxAge > 0 {
+			cookie.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+			cookie.MaxAge = 0
+		}
+
+		replaced := false
+		for i := range stored {
+			if stored[i].Name == cookie.Name && stored[i].Path == cookie.Path && stored[i].Domain == cookie.Domain {
+				stored[i], replaced = &cookie, true
+				break
+			}
+		}
+		if !replaced {
+			stored = append(stored, &cookie)
+		}
+	}
+
+	result := stored[:0]
+	for _, cookie := range stored {
+		if cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && !cookie.Expires.After(now)) {
+			continue
+		}
+		result = append(result, cookie)
+	}
+
+	return result
+}
+
+func (c *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	c.jar.SetCookies(u, cookies)
+
+	if c.path == "" {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := siteKey(u)
+	c.cookies[key] = merge(c.cookies[key], cookies, time.Now())
+
+	if err := c.save(); err != nil {
+		log.Warn().Err(err).Str("path", c.path).Msg("save cookies failed")
+	}
+}
+
+func (c *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return c.jar.Cookies(u)
+}
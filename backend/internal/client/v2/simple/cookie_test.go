@@ -0,0 +1,62 @@
+package simple
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_merge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		stored  []*http.Cookie
+		cookies []*http.Cookie
+		want    []*http.Cookie
+	}{
+		{
+			name:    "add new cookie",
+			stored:  []*http.Cookie{{Name: "a", Value: "1"}},
+			cookies: []*http.Cookie{{Name: "b", Value: "2"}},
+			want:    []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+		},
+		{
+			name:    "replace cookie of same name",
+			stored:  []*http.Cookie{{Name: "a", Value: "1"}},
+			cookies: []*http.Cookie{{Name: "a", Value: "2"}},
+			want:    []*http.Cookie{{Name: "a", Value: "2"}},
+		},
+		{
+			name:    "convert max age to expiry time",
+			cookies: []*http.Cookie{{Name: "a", Value: "1", MaxAge: 60}},
+			want:    []*http.Cookie{{Name: "a", Value: "1", Expires: now.Add(time.Minute)}},
+		},
+		{
+			name:    "drop deleted cookie",
+			stored:  []*http.Cookie{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+			cookies: []*http.Cookie{{Name: "a", MaxAge: -1}},
+			want:    []*http.Cookie{{Name: "b", Value: "2"}},
+		},
+		{
+			name:    "drop expired cookie",
+			stored:  []*http.Cookie{{Name: "a", Value: "1", Expires: now.Add(-time.Minute)}},
+			cookies: []*http.Cookie{},
+			want:    []*http.Cookie{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := merge(test.stored, test.cookies, now)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
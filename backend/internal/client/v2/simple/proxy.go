@@ -0,0 +1,132 @@
+package simple
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrNoProxyAvailable = errors.New("no proxy available")
+
+type proxy struct {
+	url          *url.URL
+	failCount    int
+	totalFail    int
+	evicted      bool
+	evictedUntil time.Time
+}
+
+type proxyPool struct {
+	conf    *ProxyConfig
+	lock    sync.Mutex
+	proxies []*proxy
+	next    int
+}
+
+func newProxyPool(conf *ProxyConfig) *proxyPool {
+	if len(conf.URLs) == 0 {
+		return nil
+	}
+
+	pool := &proxyPool{conf: conf}
+	for _, rawURL := range conf.URLs {
+		proxyURL, err := url.Parse(rawURL)
+		if err != nil {
+			log.Error().Err(err).Str("proxy", rawURL).Msg("skip invalid proxy")
+			continue
+		}
+
+		pool.proxies = append(pool.proxies, &proxy{url: proxyURL})
+	}
+
+	return pool
+}
+
+// available report if the proxy can be picked, and bring the proxy back to
+// the pool once the evict duration passed
+func (p *proxy) available(now time.Time) bool {
+	if !p.evicted {
+		return true
+	}
+
+	if !p.evictedUntil.IsZero() && !now.Before(p.evictedUntil) {
+		p.evicted, p.failCount = false, 0
+
+		return true
+	}
+
+	return false
+}
+
+func (pool *proxyPool) pick(now time.Time) (*proxy, error) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	var picked *proxy
+	for i := range pool.proxies {
+		index := (pool.next + i) % len(pool.proxies)
+		p := pool.proxies[index]
+		if !p.available(now) {
+			continue
+		}
+
+		if pool.conf.Strategy != ProxyStrategyLeastFailure {
+			pool.next = index + 1
+
+			return p, nil
+		}
+
+		if picked == nil || p.totalFail < picked.totalFail {
+			picked = p
+		}
+	}
+
+	if picked == nil {
+		return nil, ErrNoProxyAvailable
+	}
+
+	return picked, nil
+}
+
+func (pool *proxyPool) report(p *proxy, failed bool, now time.Time) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if !failed {
+		p.failCount = 0
+
+		return
+	}
+
+	p.failCount++
+	p.totalFail++
+
+	if pool.conf.MaxFailCount > 0 && p.failCount >= pool.conf.MaxFailCount && !p.evicted {
+		p.evicted = true
+		if pool.conf.EvictDuration > 0 {
+			p.evictedUntil = now.Add(pool.conf.EvictDuration)
+		}
+
+		log.Warn().
+			Str("proxy", p.url.Redacted()).
+			Int("fail_count", p.failCount).
+			Str("evict_duration", pool.conf.EvictDuration.String()).
+			Msg("evict proxy")
+	}
+}
+
+type proxyKey struct{}
+
+// proxyFromRequest is used as the proxy function of transport, the proxy
+// picked for the request is attached to the request context
+func proxyFromRequest(req *http.Request) (*url.URL, error) {
+	if p, ok := req.Context().Value(proxyKey{}).(*proxy); ok {
+		return p.url, nil
+	}
+
+	return nil, nil
+}
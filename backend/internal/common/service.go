@@ -2,6 +2,7 @@ package common
 
 import (
 	"database/sql"
+	"fmt"
 	"slices"
 
 	"github.com/htchan/BookSpider/internal/config/v2"
@@ -10,6 +11,7 @@ import (
 	"github.com/htchan/BookSpider/internal/vendorservice/baling"
 	"github.com/htchan/BookSpider/internal/vendorservice/bestory"
 	"github.com/htchan/BookSpider/internal/vendorservice/ck101"
+	"github.com/htchan/BookSpider/internal/vendorservice/generic"
 	"github.com/htchan/BookSpider/internal/vendorservice/hjwzw"
 	"github.com/htchan/BookSpider/internal/vendorservice/uukanshu"
 	"github.com/htchan/BookSpider/internal/vendorservice/xbiquge"
@@ -17,7 +19,7 @@ import (
 	"golang.org/x/sync/semaphore"
 )
 
-func LoadServices(vendors []string, db *sql.DB, siteConf map[string]config.SiteConfig, maxThreads int64) map[string]service.Service {
+func LoadServices(vendors []string, db *sql.DB, siteConf map[string]config.SiteConfig, maxThreads int64) (map[string]service.Service, error) {
 	result := make(map[string]service.Service)
 
 	publicSema := semaphore.NewWeighted(maxThreads)
@@ -64,5 +66,26 @@ func LoadServices(vendors []string, db *sql.DB, siteConf map[string]config.SiteC
 		result[uukanshu.Host] = uukanshu.NewService(rpo, publicSema, siteConf[uukanshu.Host])
 	}
 
-	return result
+	// sites without dedicated vendor package are served by the config driven vendor service
+	for _, vendorName := range vendors {
+		if _, ok := result[vendorName]; ok {
+			continue
+		}
+
+		conf, ok := siteConf[vendorName]
+		if !ok {
+			return nil, fmt.Errorf("load %s service: site config not found", vendorName)
+		}
+
+		rpo := repo.NewRepo(vendorName, db)
+
+		serv, err := generic.NewService(vendorName, rpo, publicSema, conf)
+		if err != nil {
+			return nil, fmt.Errorf("load %s service: %w", vendorName, err)
+		}
+
+		result[vendorName] = serv
+	}
+
+	return result, nil
 }
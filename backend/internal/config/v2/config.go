@@ -1,7 +1,6 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -21,6 +20,10 @@ type APIConfig struct {
 	SiteConfigs        map[string]SiteConfig `yaml:"sites" validate:"dive"`
 	DatabaseConfig     DatabaseConfig        `yaml:"database"`
 	ConfigDirectory    string                `env:"CONFIG_DIRECTORY,required" validate:"dir"`
+	ShutdownTimeout    time.Duration         `env:"SHUTDOWN_TIMEOUT"`
+	// token required to enqueue and poll jobs, jobs api rejects all requests
+	// if it is empty
+	AdminToken string `env:"ADMIN_TOKEN"`
 }
 
 type WorkerConfig struct {
@@ -28,10 +31,22 @@ type WorkerConfig struct {
 	AvailableSiteNames []string              `env:"BATCH_AVAILABLE_SITES,required" validate:"min=1,dive,min=1"`
 	SiteConfigs        map[string]SiteConfig `yaml:"sites" validate:"dive"`
 	DatabaseConfig     DatabaseConfig        `yaml:"database"`
-	ScheduleConfig     ScheduleConfig        `yaml:"schedule"`
 	ConfigDirectory    string                `env:"CONFIG_DIRECTORY,required" validate:"dir"`
+	ShutdownTimeout    time.Duration         `env:"SHUTDOWN_TIMEOUT"`
+	// admin server is not started if AdminAddr is empty
+	AdminAddr  string `env:"ADMIN_ADDR"`
+	AdminToken string `env:"ADMIN_TOKEN"`
+	// interval to check for jobs enqueued through api
+	JobPollInterval time.Duration `env:"JOB_POLL_INTERVAL"`
 }
 
+// DefaultShutdownTimeout is used when SHUTDOWN_TIMEOUT is not set, it is
+// shorter than the default termination grace period of kubernetes
+const DefaultShutdownTimeout = 25 * time.Second
+
+// DefaultJobPollInterval is used when JOB_POLL_INTERVAL is not set
+const DefaultJobPollInterval = 10 * time.Second
+
 type DatabaseConfig struct {
 	Host            string        `env:"PSQL_HOST,required" validate:"min=1"`
 	Port            string        `env:"PSQL_PORT,required" validate:"min=1"`
@@ -92,7 +107,18 @@ func LoadAPIConfig() (*APIConfig, error) {
 }
 
 func (conf *APIConfig) Validate() error {
-	return validator.New().Struct(conf)
+	validStruct := validator.New().Struct(conf)
+	if validStruct != nil {
+		return validStruct
+	}
+
+	for name, siteConf := range conf.SiteConfigs {
+		if err := siteConf.Validate(); err != nil {
+			return fmt.Errorf("site %s: %w", name, err)
+		}
+	}
+
+	return nil
 }
 
 func LoadWorkerConfig() (*WorkerConfig, error) {
@@ -101,7 +127,6 @@ func LoadWorkerConfig() (*WorkerConfig, error) {
 	loadConfigFuncs := []func() error{
 		func() error { return env.Parse(&conf) },
 		func() error { return env.Parse(&conf.DatabaseConfig) },
-		func() error { return env.Parse(&conf.ScheduleConfig) },
 		func() error {
 			var referenceData []byte
 
@@ -150,8 +175,10 @@ func (conf *WorkerConfig) Validate() error {
 		return validStruct
 	}
 
-	if conf.ScheduleConfig.IntervalMonth+conf.ScheduleConfig.IntervalMonth <= 0 {
-		return errors.New("interval is zero")
+	for name, siteConf := range conf.SiteConfigs {
+		if err := siteConf.Validate(); err != nil {
+			return fmt.Errorf("site %s: %w", name, err)
+		}
 	}
 
 	return nil
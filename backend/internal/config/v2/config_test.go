@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/htchan/BookSpider/internal/client/v2/cache"
 	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	ratelimit "github.com/htchan/BookSpider/internal/client/v2/rate_limit"
 	"github.com/htchan/BookSpider/internal/client/v2/retry"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
 	"github.com/stretchr/testify/assert"
@@ -160,14 +162,6 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					InitHour:      0,
-					InitMinute:    0,
-					MatchWeekday:  0,
-					IntervalDay:   0,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			valid: true,
@@ -185,10 +179,6 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			valid: false,
@@ -206,10 +196,6 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			valid: false,
@@ -227,10 +213,6 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			valid: false,
@@ -248,45 +230,35 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: "./not-exist/",
 			},
 			valid: false,
 		},
 		{
-			name: "invalid ScheduleConfig/violate validate rule",
+			name: "invalid ScheduleConfig",
 			conf: WorkerConfig{
 				MaxWorkingThreads:  10,
 				AvailableSiteNames: []string{"data"},
-				SiteConfigs:        map[string]SiteConfig{},
-				DatabaseConfig: DatabaseConfig{
-					Host:     "host",
-					Port:     "port",
-					User:     "user",
-					Password: "pwd",
-					Name:     "name",
-				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      100,
-					InitHour:      0,
-					InitMinute:    0,
-					MatchWeekday:  0,
-					IntervalDay:   0,
-					IntervalMonth: 1,
+				SiteConfigs: map[string]SiteConfig{
+					"data": {
+						DecodeMethod:         "gbk",
+						MaxThreads:           1,
+						ClientConfig:         standardClientConf,
+						CircuitBreakerConfig: standardCircuitBreakerConf,
+						RequestTimeout:       1 * time.Second,
+						RetryConfig:          map[string]int{"default": 1},
+
+						Storage:         ".",
+						BackupDirectory: ".",
+
+						URL:                    standardURLConf,
+						MaxExploreError:        1,
+						MaxDownloadConcurrency: 1,
+						GoquerySelectorsConfig: standardGoquerySelectorsConf,
+						AvailabilityConfig:     standardAvailabilityConf,
+						ScheduleConfig:         ScheduleConfig{"update": "* * *"},
+					},
 				},
-				ConfigDirectory: ".",
-			},
-			valid: false,
-		},
-		{
-			name: "invalid ScheduleConfig/all intervals are zero",
-			conf: WorkerConfig{
-				MaxWorkingThreads:  10,
-				AvailableSiteNames: []string{"data"},
-				SiteConfigs:        map[string]SiteConfig{},
 				DatabaseConfig: DatabaseConfig{
 					Host:     "host",
 					Port:     "port",
@@ -294,9 +266,6 @@ func Test_validate_BatchConfig(t *testing.T) {
 					Password: "pwd",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			valid: false,
@@ -529,10 +498,26 @@ xbiquge_client: &xbiquge_client
     <<: *default_circuit_breaker_config
     open_threshold: 10
     max_concurrency_threads: 200
+  # rate limit client
+  rate_limit:
+    requests_per_second: 5
+    burst: 10
+    jitter: 200ms
+  # cache client
+  cache:
+    dir: /cache/xqishu
+    book_ttl: 1h
+    chapter_list_ttl: 1h
+    chapter_ttl: 720h
   # simple client
   simple:
     request_timeout: 30s
     decode_method: utf8
+    proxy:
+      urls: [http://proxy-1:8080, socks5://proxy-2:1080]
+      strategy: least-failure
+      max_fail_count: 5
+      evict_duration: 10m
 `
 
 	tests := []struct {
@@ -705,6 +690,12 @@ xbiquge_client: &xbiquge_client
 							Simple: simple.SimpleClientConfig{
 								RequestTimeout: 30 * time.Second,
 								DecodeMethod:   "utf8",
+								Proxy: simple.ProxyConfig{
+									URLs:          []string{"http://proxy-1:8080", "socks5://proxy-2:1080"},
+									Strategy:      simple.ProxyStrategyLeastFailure,
+									MaxFailCount:  5,
+									EvictDuration: 10 * time.Minute,
+								},
 							},
 							Retry: retry.RetryClientConfig{
 								RetryConditions: []retry.RetryCondition{
@@ -742,6 +733,17 @@ xbiquge_client: &xbiquge_client
 									{Type: "status-codes", Value: []any{502}},
 								},
 							},
+							RateLimit: ratelimit.RateLimitClientConfig{
+								RequestsPerSecond: 5,
+								Burst:             10,
+								Jitter:            200 * time.Millisecond,
+							},
+							Cache: cache.CacheClientConfig{
+								Dir:            "/cache/xqishu",
+								BookTTL:        time.Hour,
+								ChapterListTTL: time.Hour,
+								ChapterTTL:     720 * time.Hour,
+							},
 						},
 						CircuitBreakerConfig: CircuitBreakerClientConfig{
 							MaxFailCount:      10,
@@ -1099,10 +1101,26 @@ xbiquge_client: &xbiquge_client
     <<: *default_circuit_breaker_config
     open_threshold: 10
     max_concurrency_threads: 200
+  # rate limit client
+  rate_limit:
+    requests_per_second: 5
+    burst: 10
+    jitter: 200ms
+  # cache client
+  cache:
+    dir: /cache/xqishu
+    book_ttl: 1h
+    chapter_list_ttl: 1h
+    chapter_ttl: 720h
   # simple client
   simple:
     request_timeout: 30s
     decode_method: utf8
+    proxy:
+      urls: [http://proxy-1:8080, socks5://proxy-2:1080]
+      strategy: least-failure
+      max_fail_count: 5
+      evict_duration: 10m
 `
 
 	tests := []struct {
@@ -1116,18 +1134,14 @@ xbiquge_client: &xbiquge_client
 		{
 			name: "happy flow with default",
 			envMap: map[string]string{
-				"CONFIG_DIRECTORY":       ".",
-				"MAX_WORKING_THREADS":    "1000",
-				"BATCH_AVAILABLE_SITES":  "xbiquge,xqishu",
-				"PSQL_HOST":              "host",
-				"PSQL_PORT":              "12345",
-				"PSQL_USER":              "user",
-				"PSQL_PASSWORD":          "password",
-				"PSQL_NAME":              "name",
-				"SCHEDULE_INIT_DATE":     "1",
-				"SCHEDULE_INIT_HOUR":     "20",
-				"SCHEDULE_INIT_MINUTE":   "1",
-				"SCHEDULE_MATCH_WEEKDAY": "5",
+				"CONFIG_DIRECTORY":      ".",
+				"MAX_WORKING_THREADS":   "1000",
+				"BATCH_AVAILABLE_SITES": "xbiquge,xqishu",
+				"PSQL_HOST":             "host",
+				"PSQL_PORT":             "12345",
+				"PSQL_USER":             "user",
+				"PSQL_PASSWORD":         "password",
+				"PSQL_NAME":             "name",
 			},
 			stubConfFileFunc: func() {
 				os.Mkdir("./selectors", os.ModePerm)
@@ -1277,6 +1291,12 @@ xbiquge_client: &xbiquge_client
 							Simple: simple.SimpleClientConfig{
 								RequestTimeout: 30 * time.Second,
 								DecodeMethod:   "utf8",
+								Proxy: simple.ProxyConfig{
+									URLs:          []string{"http://proxy-1:8080", "socks5://proxy-2:1080"},
+									Strategy:      simple.ProxyStrategyLeastFailure,
+									MaxFailCount:  5,
+									EvictDuration: 10 * time.Minute,
+								},
 							},
 							Retry: retry.RetryClientConfig{
 								RetryConditions: []retry.RetryCondition{
@@ -1314,6 +1334,17 @@ xbiquge_client: &xbiquge_client
 									{Type: "status-codes", Value: []any{502}},
 								},
 							},
+							RateLimit: ratelimit.RateLimitClientConfig{
+								RequestsPerSecond: 5,
+								Burst:             10,
+								Jitter:            200 * time.Millisecond,
+							},
+							Cache: cache.CacheClientConfig{
+								Dir:            "/cache/xqishu",
+								BookTTL:        time.Hour,
+								ChapterListTTL: time.Hour,
+								ChapterTTL:     720 * time.Hour,
+							},
 						},
 						CircuitBreakerConfig: CircuitBreakerClientConfig{
 							MaxFailCount:      10,
@@ -1358,12 +1389,6 @@ xbiquge_client: &xbiquge_client
 					Password: "password",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:     1,
-					InitHour:     20,
-					InitMinute:   1,
-					MatchWeekday: 5,
-				},
 				ConfigDirectory: ".",
 			},
 			expectError: false,
@@ -1371,20 +1396,14 @@ xbiquge_client: &xbiquge_client
 		{
 			name: "happy flow without default",
 			envMap: map[string]string{
-				"CONFIG_DIRECTORY":        ".",
-				"MAX_WORKING_THREADS":     "1000",
-				"BATCH_AVAILABLE_SITES":   "xbiquge",
-				"PSQL_HOST":               "host",
-				"PSQL_PORT":               "12345",
-				"PSQL_USER":               "user",
-				"PSQL_PASSWORD":           "password",
-				"PSQL_NAME":               "name",
-				"SCHEDULE_INIT_DATE":      "1",
-				"SCHEDULE_INIT_HOUR":      "20",
-				"SCHEDULE_INIT_MINUTE":    "0",
-				"SCHEDULE_MATCH_WEEKDAY":  "5",
-				"SCHEDULE_INTERVAL_DAY":   "1",
-				"SCHEDULE_INTERVAL_MONTH": "1",
+				"CONFIG_DIRECTORY":      ".",
+				"MAX_WORKING_THREADS":   "1000",
+				"BATCH_AVAILABLE_SITES": "xbiquge",
+				"PSQL_HOST":             "host",
+				"PSQL_PORT":             "12345",
+				"PSQL_USER":             "user",
+				"PSQL_PASSWORD":         "password",
+				"PSQL_NAME":             "name",
 			},
 			stubConfFileFunc: func() {
 				confData := `sites:
@@ -1459,14 +1478,6 @@ xbiquge_client: &xbiquge_client
 					Password: "password",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:      1,
-					InitHour:      20,
-					InitMinute:    0,
-					MatchWeekday:  5,
-					IntervalDay:   1,
-					IntervalMonth: 1,
-				},
 				ConfigDirectory: ".",
 			},
 			expectError: false,
@@ -1474,18 +1485,14 @@ xbiquge_client: &xbiquge_client
 		{
 			name: "empty sites",
 			envMap: map[string]string{
-				"CONFIG_DIRECTORY":       ".",
-				"MAX_WORKING_THREADS":    "1000",
-				"BATCH_AVAILABLE_SITES":  "xbiquge",
-				"PSQL_HOST":              "host",
-				"PSQL_PORT":              "12345",
-				"PSQL_USER":              "user",
-				"PSQL_PASSWORD":          "password",
-				"PSQL_NAME":              "name",
-				"SCHEDULE_INIT_DATE":     "1",
-				"SCHEDULE_INIT_HOUR":     "20",
-				"SCHEDULE_INIT_MINUTE":   "1",
-				"SCHEDULE_MATCH_WEEKDAY": "5",
+				"CONFIG_DIRECTORY":      ".",
+				"MAX_WORKING_THREADS":   "1000",
+				"BATCH_AVAILABLE_SITES": "xbiquge",
+				"PSQL_HOST":             "host",
+				"PSQL_PORT":             "12345",
+				"PSQL_USER":             "user",
+				"PSQL_PASSWORD":         "password",
+				"PSQL_NAME":             "name",
 			},
 			stubConfFileFunc: func() {
 				os.WriteFile("./main.yaml", []byte(""), 0644)
@@ -1503,12 +1510,6 @@ xbiquge_client: &xbiquge_client
 					Password: "password",
 					Name:     "name",
 				},
-				ScheduleConfig: ScheduleConfig{
-					InitDate:     1,
-					InitHour:     20,
-					InitMinute:   1,
-					MatchWeekday: 5,
-				},
 				SiteConfigs:     nil,
 				ConfigDirectory: ".",
 			},
@@ -1517,18 +1518,14 @@ xbiquge_client: &xbiquge_client
 		{
 			name: "sites config file not exist",
 			envMap: map[string]string{
-				"CONFIG_DIRECTORY":       "./not-exist/",
-				"MAX_WORKING_THREADS":    "1000",
-				"BATCH_AVAILABLE_SITES":  "xbiquge",
-				"PSQL_HOST":              "host",
-				"PSQL_PORT":              "12345",
-				"PSQL_USER":              "user",
-				"PSQL_PASSWORD":          "password",
-				"PSQL_NAME":              "name",
-				"SCHEDULE_INIT_DATE":     "1",
-				"SCHEDULE_INIT_HOUR":     "20",
-				"SCHEDULE_INIT_MINUTE":   "1",
-				"SCHEDULE_MATCH_WEEKDAY": "5",
+				"CONFIG_DIRECTORY":      "./not-exist/",
+				"MAX_WORKING_THREADS":   "1000",
+				"BATCH_AVAILABLE_SITES": "xbiquge",
+				"PSQL_HOST":             "host",
+				"PSQL_PORT":             "12345",
+				"PSQL_USER":             "user",
+				"PSQL_PASSWORD":         "password",
+				"PSQL_NAME":             "name",
 			},
 			stubConfFileFunc: func() {
 				os.WriteFile("./main.yaml", []byte(""), 0644)
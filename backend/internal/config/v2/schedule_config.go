@@ -1,12 +1,62 @@
 package config
 
-import "time"
-
-type ScheduleConfig struct {
-	InitDate      int          `env:"SCHEDULE_INIT_DATE,required" validate:"min=1,max=31"`
-	InitHour      int          `env:"SCHEDULE_INIT_HOUR,required" validate:"min=0,max=23"`
-	InitMinute    int          `env:"SCHEDULE_INIT_MINUTE,required" validate:"min=0,max=59"`
-	MatchWeekday  time.Weekday `env:"SCHEDULE_MATCH_WEEKDAY,required" validate:"min=0,max=6"`
-	IntervalDay   int          `env:"SCHEDULE_INTERVAL_DAY" validate:"min=0,max=31"`
-	IntervalMonth int          `env:"SCHEDULE_INTERVAL_MONTH" validate:"min=0,max=11"`
+import (
+	"fmt"
+	"os"
+
+	"github.com/htchan/BookSpider/internal/schedule"
+)
+
+// ScheduleConfig map the operations of site to the cron expressions they are
+// run on, e.g. `update: "0 3 * * *"`. schedules are evaluated in UTC
+type ScheduleConfig map[string]string
+
+// DefaultProcessSchedule is used for site without schedules configured, it
+// runs the whole process weekly (00:00 of sunday in UTC)
+const DefaultProcessSchedule = "@weekly"
+
+// RemovedScheduleEnvs scheduled the process of all sites before schedules
+// are configured per site. they are ignored now, and sites without schedules
+// run on DefaultProcessSchedule instead
+var RemovedScheduleEnvs = []string{
+	"SCHEDULE_INIT_DATE",
+	"SCHEDULE_INIT_HOUR",
+	"SCHEDULE_INIT_MINUTE",
+	"SCHEDULE_MATCH_WEEKDAY",
+	"SCHEDULE_INTERVAL_DAY",
+	"SCHEDULE_INTERVAL_MONTH",
+}
+
+// RemovedScheduleEnvsSet return the removed schedule envs still set, so the
+// worker can warn that they are ignored
+func RemovedScheduleEnvsSet() []string {
+	var envs []string
+	for _, env := range RemovedScheduleEnvs {
+		if _, ok := os.LookupEnv(env); ok {
+			envs = append(envs, env)
+		}
+	}
+
+	return envs
+}
+
+func (conf ScheduleConfig) WithDefaults() ScheduleConfig {
+	if len(conf) == 0 {
+		return ScheduleConfig{"process": DefaultProcessSchedule}
+	}
+
+	return conf
+}
+
+// Validate check if all the cron expressions can be parsed, the operations
+// are validated by the struct tag of SiteConfig
+func (conf ScheduleConfig) Validate() error {
+	for operation, expr := range conf {
+		_, err := schedule.Parse(expr)
+		if err != nil {
+			return fmt.Errorf("schedule of %s: %w", operation, err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		conf      ScheduleConfig
+		wantError error
+	}{
+		{
+			name: "valid conf",
+			conf: ScheduleConfig{"update": "0 3 * * *", "explore": "@weekly"},
+		},
+		{
+			name: "valid conf - empty",
+			conf: nil,
+		},
+		{
+			name:      "invalid cron expression",
+			conf:      ScheduleConfig{"download": "every hour"},
+			wantError: schedule.ErrInvalidCron,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, test.conf.Validate(), test.wantError)
+		})
+	}
+}
+
+func TestScheduleConfig_WithDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		conf   ScheduleConfig
+		expect ScheduleConfig
+	}{
+		{
+			name:   "run process by default",
+			conf:   nil,
+			expect: ScheduleConfig{"process": DefaultProcessSchedule},
+		},
+		{
+			name:   "keep configured schedules",
+			conf:   ScheduleConfig{"update": "@daily"},
+			expect: ScheduleConfig{"update": "@daily"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.conf.WithDefaults())
+		})
+	}
+}
+
+// envs are shared by the tests, so it is not run in parallel
+func TestRemovedScheduleEnvsSet(t *testing.T) {
+	assert.Empty(t, RemovedScheduleEnvsSet())
+
+	t.Setenv("SCHEDULE_INIT_HOUR", "3")
+	t.Setenv("SCHEDULE_MATCH_WEEKDAY", "")
+
+	assert.Equal(t, []string{"SCHEDULE_INIT_HOUR", "SCHEDULE_MATCH_WEEKDAY"}, RemovedScheduleEnvsSet())
+}
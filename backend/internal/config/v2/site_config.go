@@ -1,9 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/htchan/BookSpider/internal/client/v2/cache"
 	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	ratelimit "github.com/htchan/BookSpider/internal/client/v2/rate_limit"
+	"github.com/htchan/BookSpider/internal/client/v2/replay"
 	"github.com/htchan/BookSpider/internal/client/v2/retry"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
 )
@@ -22,15 +26,102 @@ type SiteConfig struct {
 	URL                    URLConfig              `yaml:"urls"`
 	MaxExploreError        int                    `yaml:"max_explore_error" validate:"min=1"`
 	MaxDownloadConcurrency int                    `yaml:"max_download_concurrency" validate:"min=1"`
+	DownloadInProgress     bool                   `yaml:"download_in_progress"`
+	BookTaskConfig         BookTaskConfig         `yaml:"book_task"`
+	JobConfig              JobConfig              `yaml:"job"`
+	ScheduleConfig         ScheduleConfig         `yaml:"schedules" validate:"dive,keys,oneof=check-availability update explore validate download patch-status patch-missing-records patch-chapters process,endkeys,min=1"`
 	GoquerySelectorsConfig GoquerySelectorsConfig `yaml:"goquery_selectors"`
 	AvailabilityConfig     AvailabilityConfig     `yaml:"availability"`
+	VendorConfig           VendorConfig           `yaml:"vendor"`
 	// UpdateDateLayour string    `yaml:"update_date_layout"`
 }
 
+// Validate check the fields cannot be validated by struct tags
+func (conf SiteConfig) Validate() error {
+	if err := conf.ScheduleConfig.Validate(); err != nil {
+		return err
+	}
+
+	if err := conf.ClientConfig.CircuitBreaker.Validate(); err != nil {
+		return fmt.Errorf("circuit breaker: %w", err)
+	}
+
+	return nil
+}
+
 type ClientConfig struct {
 	Simple         simple.SimpleClientConfig                 `yaml:"simple" validate:"dive"`
 	Retry          retry.RetryClientConfig                   `yaml:"retry" validate:"dive"`
 	CircuitBreaker circuitbreaker.CircuitBreakerClientConfig `yaml:"circuit_breaker" validate:"dive"`
+	RateLimit      ratelimit.RateLimitClientConfig           `yaml:"rate_limit"`
+	Cache          cache.CacheClientConfig                   `yaml:"cache"`
+	Replay         replay.ReplayClientConfig                 `yaml:"replay"`
+}
+
+// BookTaskConfig control how update and download tasks of books are leased
+// from the queue shared by all workers. zero values are replaced by defaults
+type BookTaskConfig struct {
+	// lease is renewed while the task is running, a task is taken over by
+	// another worker only if the lease is not renewed in time
+	LeaseDuration time.Duration `yaml:"lease_duration" validate:"min=0"`
+	MaxAttempts   int           `yaml:"max_attempts" validate:"min=0"`
+	// failed task is retried after RetryInterval * attempts
+	RetryInterval time.Duration `yaml:"retry_interval" validate:"min=0"`
+	// done task is deleted once it is not updated for Retention
+	Retention time.Duration `yaml:"retention" validate:"min=0"`
+}
+
+const (
+	DefaultBookTaskLeaseDuration = 5 * time.Minute
+	DefaultBookTaskMaxAttempts   = 3
+	DefaultBookTaskRetryInterval = 10 * time.Minute
+	DefaultBookTaskRetention     = 7 * 24 * time.Hour
+)
+
+func (conf BookTaskConfig) WithDefaults() BookTaskConfig {
+	if conf.LeaseDuration <= 0 {
+		conf.LeaseDuration = DefaultBookTaskLeaseDuration
+	}
+
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = DefaultBookTaskMaxAttempts
+	}
+
+	if conf.RetryInterval <= 0 {
+		conf.RetryInterval = DefaultBookTaskRetryInterval
+	}
+
+	if conf.Retention <= 0 {
+		conf.Retention = DefaultBookTaskRetention
+	}
+
+	return conf
+}
+
+// JobConfig control how jobs enqueued through api are leased by workers.
+// zero values are replaced by defaults
+type JobConfig struct {
+	// lease is renewed while the job is running, a job is claimed by another
+	// worker only if the lease is not renewed in time
+	LeaseDuration time.Duration `yaml:"lease_duration" validate:"min=0"`
+	MaxAttempts   int           `yaml:"max_attempts" validate:"min=0"`
+}
+
+const (
+	DefaultJobLeaseDuration = 5 * time.Minute
+	DefaultJobMaxAttempts   = 3
+)
+
+func (conf JobConfig) WithDefaults() JobConfig {
+	if conf.LeaseDuration <= 0 {
+		conf.LeaseDuration = DefaultJobLeaseDuration
+	}
+
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = DefaultJobMaxAttempts
+	}
+
+	return conf
 }
 
 type CircuitBreakerClientConfig struct {
@@ -67,3 +158,15 @@ type GoquerySelectorConfig struct {
 	Attr            string   `yaml:"attr"`
 	UnwantedContent []string `yaml:"unwanted_content" validate:"dive,min=1"`
 }
+
+type MissingIDStrategy string
+
+const (
+	MissingIDStrategySequential MissingIDStrategy = "sequential"
+	MissingIDStrategyNone       MissingIDStrategy = "none"
+)
+
+type VendorConfig struct {
+	MissingIDStrategy            MissingIDStrategy `yaml:"missing_id_strategy" validate:"omitempty,oneof=sequential none"`
+	MaxLeadingDuplicatedChapters int               `yaml:"max_leading_duplicated_chapters" validate:"min=0"`
+}
@@ -9,6 +9,7 @@ import (
 	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
 	"github.com/htchan/BookSpider/internal/client/v2/retry"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
+	"github.com/htchan/BookSpider/internal/schedule"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -102,6 +103,76 @@ func Test_validate_SiteConfig(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			name: "valid conf with schedules",
+			conf: SiteConfig{
+				DecodeMethod:         "gbk",
+				MaxThreads:           1,
+				ClientConfig:         standardClientConf,
+				CircuitBreakerConfig: standardCircuitBreakerConf,
+				RequestTimeout:       1 * time.Second,
+				RetryConfig:          map[string]int{"default": 1},
+
+				Storage:         ".",
+				BackupDirectory: ".",
+
+				URL:                    standardURLConf,
+				MaxExploreError:        1,
+				MaxDownloadConcurrency: 1,
+				GoquerySelectorsConfig: standardGoquerySelectorsConf,
+				AvailabilityConfig:     standardAvailabilityConf,
+				ScheduleConfig:         ScheduleConfig{"update": "0 3 * * *", "download": "@hourly"},
+			},
+			valid: true,
+		},
+		{
+			name: "valid conf with schedules of operations only run in process",
+			conf: SiteConfig{
+				DecodeMethod:         "gbk",
+				MaxThreads:           1,
+				ClientConfig:         standardClientConf,
+				CircuitBreakerConfig: standardCircuitBreakerConf,
+				RequestTimeout:       1 * time.Second,
+				RetryConfig:          map[string]int{"default": 1},
+
+				Storage:         ".",
+				BackupDirectory: ".",
+
+				URL:                    standardURLConf,
+				MaxExploreError:        1,
+				MaxDownloadConcurrency: 1,
+				GoquerySelectorsConfig: standardGoquerySelectorsConf,
+				AvailabilityConfig:     standardAvailabilityConf,
+				ScheduleConfig: ScheduleConfig{
+					"check-availability":    "*/30 * * * *",
+					"patch-status":          "@daily",
+					"patch-missing-records": "@weekly",
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "invalid ScheduleConfig operation",
+			conf: SiteConfig{
+				DecodeMethod:         "gbk",
+				MaxThreads:           1,
+				ClientConfig:         standardClientConf,
+				CircuitBreakerConfig: standardCircuitBreakerConf,
+				RequestTimeout:       1 * time.Second,
+				RetryConfig:          map[string]int{"default": 1},
+
+				Storage:         ".",
+				BackupDirectory: ".",
+
+				URL:                    standardURLConf,
+				MaxExploreError:        1,
+				MaxDownloadConcurrency: 1,
+				GoquerySelectorsConfig: standardGoquerySelectorsConf,
+				AvailabilityConfig:     standardAvailabilityConf,
+				ScheduleConfig:         ScheduleConfig{"backup": "0 3 * * *"},
+			},
+			valid: false,
+		},
 		{
 			name: "invalid DecodeMethod",
 			conf: SiteConfig{
@@ -423,6 +494,57 @@ func Test_validate_SiteConfig(t *testing.T) {
 	}
 }
 
+func TestSiteConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		conf      SiteConfig
+		wantError error
+	}{
+		{
+			name: "valid conf",
+			conf: SiteConfig{
+				ScheduleConfig: ScheduleConfig{"update": "@daily"},
+				ClientConfig: ClientConfig{
+					CircuitBreaker: circuitbreaker.CircuitBreakerClientConfig{
+						CheckConfigs: []circuitbreaker.CheckConfig{
+							{Type: circuitbreaker.CheckTypeBodyRegex, Value: "请稍后再试"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "invalid schedule",
+			conf:      SiteConfig{ScheduleConfig: ScheduleConfig{"update": "daily"}},
+			wantError: schedule.ErrInvalidCron,
+		},
+		{
+			name: "invalid circuit breaker check config",
+			conf: SiteConfig{
+				ClientConfig: ClientConfig{
+					CircuitBreaker: circuitbreaker.CircuitBreakerClientConfig{
+						CheckConfigs: []circuitbreaker.CheckConfig{
+							{Type: circuitbreaker.CheckTypeStatusCodes, Value: "502"},
+						},
+					},
+				},
+			},
+			wantError: circuitbreaker.ErrInvalidCheckConfig,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.ErrorIs(t, test.conf.Validate(), test.wantError)
+		})
+	}
+}
+
 func Test_validate_CircuitBreakerClientConfig(t *testing.T) {
 	t.Parallel()
 
@@ -908,3 +1030,173 @@ func Test_validate_GoquerySelectorConfig(t *testing.T) {
 		})
 	}
 }
+
+func Test_validate_VendorConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  VendorConfig
+		valid bool
+	}{
+		{
+			name: "valid conf",
+			conf: VendorConfig{
+				MissingIDStrategy:            MissingIDStrategySequential,
+				MaxLeadingDuplicatedChapters: 12,
+			},
+			valid: true,
+		},
+		{
+			name:  "valid conf - empty",
+			conf:  VendorConfig{},
+			valid: true,
+		},
+		{
+			name: "invalid MissingIDStrategy",
+			conf: VendorConfig{
+				MissingIDStrategy: "unknown",
+			},
+			valid: false,
+		},
+		{
+			name: "invalid MaxLeadingDuplicatedChapters - negative",
+			conf: VendorConfig{
+				MaxLeadingDuplicatedChapters: -1,
+			},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validator.New().Struct(test.conf)
+			if !assert.Equal(t, test.valid, err == nil) {
+				t.Errorf("getting error: %v", err)
+			}
+		})
+	}
+}
+
+func Test_validate_BookTaskConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		conf  BookTaskConfig
+		valid bool
+	}{
+		{
+			name: "valid conf",
+			conf: BookTaskConfig{
+				LeaseDuration: time.Minute,
+				MaxAttempts:   5,
+				RetryInterval: time.Minute,
+			},
+			valid: true,
+		},
+		{
+			name:  "valid conf - empty",
+			conf:  BookTaskConfig{},
+			valid: true,
+		},
+		{
+			name:  "invalid MaxAttempts - negative",
+			conf:  BookTaskConfig{MaxAttempts: -1},
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validator.New().Struct(test.conf)
+			if !assert.Equal(t, test.valid, err == nil) {
+				t.Errorf("getting error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBookTaskConfig_WithDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		conf   BookTaskConfig
+		expect BookTaskConfig
+	}{
+		{
+			name: "fill zero values",
+			conf: BookTaskConfig{MaxAttempts: 5},
+			expect: BookTaskConfig{
+				LeaseDuration: DefaultBookTaskLeaseDuration,
+				MaxAttempts:   5,
+				RetryInterval: DefaultBookTaskRetryInterval,
+				Retention:     DefaultBookTaskRetention,
+			},
+		},
+		{
+			name: "keep configured values",
+			conf: BookTaskConfig{
+				LeaseDuration: time.Minute,
+				MaxAttempts:   1,
+				RetryInterval: time.Second,
+				Retention:     time.Hour,
+			},
+			expect: BookTaskConfig{
+				LeaseDuration: time.Minute,
+				MaxAttempts:   1,
+				RetryInterval: time.Second,
+				Retention:     time.Hour,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.conf.WithDefaults())
+		})
+	}
+}
+
+func TestJobConfig_WithDefaults(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		conf   JobConfig
+		expect JobConfig
+	}{
+		{
+			name: "fill zero values",
+			conf: JobConfig{MaxAttempts: 5},
+			expect: JobConfig{
+				LeaseDuration: DefaultJobLeaseDuration,
+				MaxAttempts:   5,
+			},
+		},
+		{
+			name:   "keep configured values",
+			conf:   JobConfig{LeaseDuration: time.Minute, MaxAttempts: 1},
+			expect: JobConfig{LeaseDuration: time.Minute, MaxAttempts: 1},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expect, test.conf.WithDefaults())
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package metrics holds the prometheus collectors of book spider. It must not
+// depend on other internal packages so that clients, services and router can
+// all report to it without import cycle
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const Namespace = "book_spider"
+
+const (
+	ResultSuccess = "success"
+	ResultFail    = "fail"
+)
+
+var (
+	PagesFetched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "pages_fetched_total",
+		Help:      "Number of pages fetched from vendor site by url class and result.",
+	}, []string{"site", "class", "result"})
+
+	FetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "fetch_duration_seconds",
+		Help:      "Time spent on fetching a page from vendor site, including retries.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 180, 600},
+	}, []string{"site", "class"})
+
+	ParseFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "parse_failures_total",
+		Help:      "Number of fields failed to be parsed from fetched pages.",
+	}, []string{"site", "class", "field"})
+
+	ChaptersDownloaded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "chapters_downloaded_total",
+		Help:      "Number of chapters downloaded by result.",
+	}, []string{"site", "result"})
+
+	RetryWeight = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "retry_weight",
+		Help:      "Retry weight consumed by each request of retry client.",
+		Buckets:   []float64{0, 1, 2, 5, 10, 20, 50, 100},
+	}, []string{"site"})
+
+	CircuitBreakerStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "circuit_breaker_status",
+		Help:      "Current status of circuit breaker, 1 for the active status and 0 for others.",
+	}, []string{"site", "status"})
+
+	CircuitBreakerTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "circuit_breaker_transitions_total",
+		Help:      "Number of times circuit breaker entered each status.",
+	}, []string{"site", "status"})
+
+	BookUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "book_updates_total",
+		Help:      "Outcome of books checked by update and explore operations.",
+	}, []string{"site", "operation", "outcome"})
+
+	BookDownloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "book_downloads_total",
+		Help:      "Outcome of books processed by download operation.",
+	}, []string{"site", "outcome"})
+
+	StoragePatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "storage_patches_total",
+		Help:      "Book files checked by patch download status operation.",
+	}, []string{"site", "outcome"})
+
+	BookTasks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "book_tasks_total",
+		Help:      "Book tasks finished by the worker, by operation and outcome.",
+	}, []string{"site", "operation", "outcome"})
+
+	ScheduledRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "scheduled_runs_total",
+		Help:      "Scheduled operations started, or skipped as the previous run is not completed.",
+	}, []string{"site", "operation", "result"})
+
+	APIRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of api requests by route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+// SetCircuitBreakerStatus mark the given status as active for the site and
+// reset the others
+func SetCircuitBreakerStatus(site string, status string, allStatus ...string) {
+	for _, s := range allStatus {
+		value := 0.0
+		if s == status {
+			value = 1
+		}
+
+		CircuitBreakerStatus.WithLabelValues(site, s).Set(value)
+	}
+}
+
+// RegisterDBStats expose the connection pool stats of db. it should only be
+// called once per process
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, Namespace))
+}
@@ -7,6 +7,7 @@ package mockrepo
 import (
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/htchan/BookSpider/internal/model"
@@ -50,6 +51,21 @@ func (mr *MockRepositoryMockRecorder) Backup(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Backup", reflect.TypeOf((*MockRepository)(nil).Backup), arg0)
 }
 
+// ClaimJob mocks base method.
+func (m *MockRepository) ClaimJob(arg0 string, arg1 time.Duration) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockRepositoryMockRecorder) ClaimJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockRepository)(nil).ClaimJob), arg0, arg1)
+}
+
 // Close mocks base method.
 func (m *MockRepository) Close() error {
 	m.ctrl.T.Helper()
@@ -64,6 +80,20 @@ func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
 }
 
+// CompleteBookTask mocks base method.
+func (m *MockRepository) CompleteBookTask(arg0 *model.BookTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteBookTask", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteBookTask indicates an expected call of CompleteBookTask.
+func (mr *MockRepositoryMockRecorder) CompleteBookTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteBookTask", reflect.TypeOf((*MockRepository)(nil).CompleteBookTask), arg0)
+}
+
 // CreateBook mocks base method.
 func (m *MockRepository) CreateBook(arg0 *model.Book) error {
 	m.ctrl.T.Helper()
@@ -78,6 +108,34 @@ func (mr *MockRepositoryMockRecorder) CreateBook(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBook", reflect.TypeOf((*MockRepository)(nil).CreateBook), arg0)
 }
 
+// CreateJob mocks base method.
+func (m *MockRepository) CreateJob(arg0 *model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockRepositoryMockRecorder) CreateJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockRepository)(nil).CreateJob), arg0)
+}
+
+// CreateJobRun mocks base method.
+func (m *MockRepository) CreateJobRun(arg0 *model.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJobRun", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJobRun indicates an expected call of CreateJobRun.
+func (mr *MockRepositoryMockRecorder) CreateJobRun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJobRun", reflect.TypeOf((*MockRepository)(nil).CreateJobRun), arg0)
+}
+
 // DBStats mocks base method.
 func (m *MockRepository) DBStats() sql.DBStats {
 	m.ctrl.T.Helper()
@@ -92,6 +150,79 @@ func (mr *MockRepositoryMockRecorder) DBStats() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DBStats", reflect.TypeOf((*MockRepository)(nil).DBStats))
 }
 
+// DeleteChapters mocks base method.
+func (m *MockRepository) DeleteChapters(arg0 *model.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteChapters", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChapters indicates an expected call of DeleteChapters.
+func (mr *MockRepositoryMockRecorder) DeleteChapters(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChapters", reflect.TypeOf((*MockRepository)(nil).DeleteChapters), arg0)
+}
+
+// DeleteFinishedBookTasks mocks base method.
+func (m *MockRepository) DeleteFinishedBookTasks(arg0 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinishedBookTasks", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinishedBookTasks indicates an expected call of DeleteFinishedBookTasks.
+func (mr *MockRepositoryMockRecorder) DeleteFinishedBookTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinishedBookTasks", reflect.TypeOf((*MockRepository)(nil).DeleteFinishedBookTasks), arg0)
+}
+
+// EnqueueDownloadBookTasks mocks base method.
+func (m *MockRepository) EnqueueDownloadBookTasks(arg0 bool) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueDownloadBookTasks", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueDownloadBookTasks indicates an expected call of EnqueueDownloadBookTasks.
+func (mr *MockRepositoryMockRecorder) EnqueueDownloadBookTasks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueDownloadBookTasks", reflect.TypeOf((*MockRepository)(nil).EnqueueDownloadBookTasks), arg0)
+}
+
+// EnqueueUpdateBookTasks mocks base method.
+func (m *MockRepository) EnqueueUpdateBookTasks() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueUpdateBookTasks")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueUpdateBookTasks indicates an expected call of EnqueueUpdateBookTasks.
+func (mr *MockRepositoryMockRecorder) EnqueueUpdateBookTasks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueUpdateBookTasks", reflect.TypeOf((*MockRepository)(nil).EnqueueUpdateBookTasks))
+}
+
+// FailBookTask mocks base method.
+func (m *MockRepository) FailBookTask(arg0 *model.BookTask, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailBookTask", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailBookTask indicates an expected call of FailBookTask.
+func (mr *MockRepositoryMockRecorder) FailBookTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailBookTask", reflect.TypeOf((*MockRepository)(nil).FailBookTask), arg0, arg1)
+}
+
 // FindAllBookIDs mocks base method.
 func (m *MockRepository) FindAllBookIDs() ([]int, error) {
 	m.ctrl.T.Helper()
@@ -182,6 +313,21 @@ func (mr *MockRepositoryMockRecorder) FindBookGroupByIDHash(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookGroupByIDHash", reflect.TypeOf((*MockRepository)(nil).FindBookGroupByIDHash), arg0, arg1)
 }
 
+// FindBookTasks mocks base method.
+func (m *MockRepository) FindBookTasks(arg0 string, arg1 model.BookTaskStatus, arg2, arg3 int) ([]model.BookTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBookTasks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]model.BookTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBookTasks indicates an expected call of FindBookTasks.
+func (mr *MockRepositoryMockRecorder) FindBookTasks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBookTasks", reflect.TypeOf((*MockRepository)(nil).FindBookTasks), arg0, arg1, arg2, arg3)
+}
+
 // FindBooksByRandom mocks base method.
 func (m *MockRepository) FindBooksByRandom(arg0 int) ([]model.Book, error) {
 	m.ctrl.T.Helper()
@@ -212,21 +358,6 @@ func (mr *MockRepositoryMockRecorder) FindBooksByStatus(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByStatus", reflect.TypeOf((*MockRepository)(nil).FindBooksByStatus), arg0)
 }
 
-// FindBooksByTitleWriter mocks base method.
-func (m *MockRepository) FindBooksByTitleWriter(arg0, arg1 string, arg2, arg3 int) ([]model.Book, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindBooksByTitleWriter", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].([]model.Book)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// FindBooksByTitleWriter indicates an expected call of FindBooksByTitleWriter.
-func (mr *MockRepositoryMockRecorder) FindBooksByTitleWriter(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksByTitleWriter", reflect.TypeOf((*MockRepository)(nil).FindBooksByTitleWriter), arg0, arg1, arg2, arg3)
-}
-
 // FindBooksForDownload mocks base method.
 func (m *MockRepository) FindBooksForDownload() (<-chan model.Book, error) {
 	m.ctrl.T.Helper()
@@ -257,6 +388,226 @@ func (mr *MockRepositoryMockRecorder) FindBooksForUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBooksForUpdate", reflect.TypeOf((*MockRepository)(nil).FindBooksForUpdate))
 }
 
+// FindChapter mocks base method.
+func (m *MockRepository) FindChapter(arg0 *model.Book, arg1 int) (*model.Chapter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChapter", arg0, arg1)
+	ret0, _ := ret[0].(*model.Chapter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChapter indicates an expected call of FindChapter.
+func (mr *MockRepositoryMockRecorder) FindChapter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChapter", reflect.TypeOf((*MockRepository)(nil).FindChapter), arg0, arg1)
+}
+
+// FindChaptersByBook mocks base method.
+func (m *MockRepository) FindChaptersByBook(arg0 *model.Book) (model.Chapters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChaptersByBook", arg0)
+	ret0, _ := ret[0].(model.Chapters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChaptersByBook indicates an expected call of FindChaptersByBook.
+func (mr *MockRepositoryMockRecorder) FindChaptersByBook(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChaptersByBook", reflect.TypeOf((*MockRepository)(nil).FindChaptersByBook), arg0)
+}
+
+// FindDownloadedBooksWithoutChapters mocks base method.
+func (m *MockRepository) FindDownloadedBooksWithoutChapters() (<-chan model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDownloadedBooksWithoutChapters")
+	ret0, _ := ret[0].(<-chan model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDownloadedBooksWithoutChapters indicates an expected call of FindDownloadedBooksWithoutChapters.
+func (mr *MockRepositoryMockRecorder) FindDownloadedBooksWithoutChapters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDownloadedBooksWithoutChapters", reflect.TypeOf((*MockRepository)(nil).FindDownloadedBooksWithoutChapters))
+}
+
+// FindJob mocks base method.
+func (m *MockRepository) FindJob(arg0 int) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJob", arg0)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJob indicates an expected call of FindJob.
+func (mr *MockRepositoryMockRecorder) FindJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockRepository)(nil).FindJob), arg0)
+}
+
+// FindJobRun mocks base method.
+func (m *MockRepository) FindJobRun(arg0 int) (*model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJobRun", arg0)
+	ret0, _ := ret[0].(*model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJobRun indicates an expected call of FindJobRun.
+func (mr *MockRepositoryMockRecorder) FindJobRun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJobRun", reflect.TypeOf((*MockRepository)(nil).FindJobRun), arg0)
+}
+
+// FindJobRuns mocks base method.
+func (m *MockRepository) FindJobRuns(arg0 string, arg1, arg2 int) ([]model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJobRuns", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJobRuns indicates an expected call of FindJobRuns.
+func (mr *MockRepositoryMockRecorder) FindJobRuns(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJobRuns", reflect.TypeOf((*MockRepository)(nil).FindJobRuns), arg0, arg1, arg2)
+}
+
+// FindWorkByChecksum mocks base method.
+func (m *MockRepository) FindWorkByChecksum(arg0, arg1 string) (model.BookGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWorkByChecksum", arg0, arg1)
+	ret0, _ := ret[0].(model.BookGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWorkByChecksum indicates an expected call of FindWorkByChecksum.
+func (mr *MockRepositoryMockRecorder) FindWorkByChecksum(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWorkByChecksum", reflect.TypeOf((*MockRepository)(nil).FindWorkByChecksum), arg0, arg1)
+}
+
+// FinishJob mocks base method.
+func (m *MockRepository) FinishJob(arg0 *model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJob", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJob indicates an expected call of FinishJob.
+func (mr *MockRepositoryMockRecorder) FinishJob(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJob", reflect.TypeOf((*MockRepository)(nil).FinishJob), arg0)
+}
+
+// FinishJobRun mocks base method.
+func (m *MockRepository) FinishJobRun(arg0 *model.JobRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishJobRun", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FinishJobRun indicates an expected call of FinishJobRun.
+func (mr *MockRepositoryMockRecorder) FinishJobRun(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishJobRun", reflect.TypeOf((*MockRepository)(nil).FinishJobRun), arg0)
+}
+
+// LeaseBookTask mocks base method.
+func (m *MockRepository) LeaseBookTask(arg0, arg1 string, arg2 time.Duration) (*model.BookTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaseBookTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.BookTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaseBookTask indicates an expected call of LeaseBookTask.
+func (mr *MockRepositoryMockRecorder) LeaseBookTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaseBookTask", reflect.TypeOf((*MockRepository)(nil).LeaseBookTask), arg0, arg1, arg2)
+}
+
+// LockOperation mocks base method.
+func (m *MockRepository) LockOperation(arg0 string) (func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOperation", arg0)
+	ret0, _ := ret[0].(func() error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockOperation indicates an expected call of LockOperation.
+func (mr *MockRepositoryMockRecorder) LockOperation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOperation", reflect.TypeOf((*MockRepository)(nil).LockOperation), arg0)
+}
+
+// RenewBookTaskLease mocks base method.
+func (m *MockRepository) RenewBookTaskLease(arg0 *model.BookTask, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewBookTaskLease", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewBookTaskLease indicates an expected call of RenewBookTaskLease.
+func (mr *MockRepositoryMockRecorder) RenewBookTaskLease(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewBookTaskLease", reflect.TypeOf((*MockRepository)(nil).RenewBookTaskLease), arg0, arg1)
+}
+
+// RenewJobLease mocks base method.
+func (m *MockRepository) RenewJobLease(arg0 *model.Job, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewJobLease", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenewJobLease indicates an expected call of RenewJobLease.
+func (mr *MockRepositoryMockRecorder) RenewJobLease(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewJobLease", reflect.TypeOf((*MockRepository)(nil).RenewJobLease), arg0, arg1)
+}
+
+// RequeueBookTask mocks base method.
+func (m *MockRepository) RequeueBookTask(arg0 int) (*model.BookTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueBookTask", arg0)
+	ret0, _ := ret[0].(*model.BookTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueBookTask indicates an expected call of RequeueBookTask.
+func (mr *MockRepositoryMockRecorder) RequeueBookTask(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueBookTask", reflect.TypeOf((*MockRepository)(nil).RequeueBookTask), arg0)
+}
+
+// SaveChapters mocks base method.
+func (m *MockRepository) SaveChapters(arg0 *model.Book, arg1 model.Chapters) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChapters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveChapters indicates an expected call of SaveChapters.
+func (mr *MockRepositoryMockRecorder) SaveChapters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChapters", reflect.TypeOf((*MockRepository)(nil).SaveChapters), arg0, arg1)
+}
+
 // SaveError mocks base method.
 func (m *MockRepository) SaveError(arg0 *model.Book, arg1 error) error {
 	m.ctrl.T.Helper()
@@ -285,6 +636,36 @@ func (mr *MockRepositoryMockRecorder) SaveWriter(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWriter", reflect.TypeOf((*MockRepository)(nil).SaveWriter), arg0)
 }
 
+// SearchBooks mocks base method.
+func (m *MockRepository) SearchBooks(arg0, arg1 string, arg2, arg3 int) ([]model.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchBooks", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]model.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchBooks indicates an expected call of SearchBooks.
+func (mr *MockRepositoryMockRecorder) SearchBooks(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBooks", reflect.TypeOf((*MockRepository)(nil).SearchBooks), arg0, arg1, arg2, arg3)
+}
+
+// SearchChapterContent mocks base method.
+func (m *MockRepository) SearchChapterContent(arg0 string, arg1, arg2 int) ([]model.ContentMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchChapterContent", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.ContentMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchChapterContent indicates an expected call of SearchChapterContent.
+func (mr *MockRepositoryMockRecorder) SearchChapterContent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchChapterContent", reflect.TypeOf((*MockRepository)(nil).SearchChapterContent), arg0, arg1, arg2)
+}
+
 // Stats mocks base method.
 func (m *MockRepository) Stats() repo.Summary {
 	m.ctrl.T.Helper()
@@ -8,8 +8,10 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
 	model "github.com/htchan/BookSpider/internal/model"
 	repo "github.com/htchan/BookSpider/internal/repo"
 	service "github.com/htchan/BookSpider/internal/service"
@@ -113,6 +115,36 @@ func (mr *MockServiceMockRecorder) BookInfo(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookInfo", reflect.TypeOf((*MockService)(nil).BookInfo), arg0, arg1)
 }
 
+// BookTasks mocks base method.
+func (m *MockService) BookTasks(arg0 context.Context, arg1 string, arg2 model.BookTaskStatus, arg3, arg4 int) ([]model.BookTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookTasks", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]model.BookTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookTasks indicates an expected call of BookTasks.
+func (mr *MockServiceMockRecorder) BookTasks(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookTasks", reflect.TypeOf((*MockService)(nil).BookTasks), arg0, arg1, arg2, arg3, arg4)
+}
+
+// BookWork mocks base method.
+func (m *MockService) BookWork(arg0 context.Context, arg1 *model.Book) (*model.Work, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookWork", arg0, arg1)
+	ret0, _ := ret[0].(*model.Work)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookWork indicates an expected call of BookWork.
+func (mr *MockServiceMockRecorder) BookWork(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookWork", reflect.TypeOf((*MockService)(nil).BookWork), arg0, arg1)
+}
+
 // CheckAvailability mocks base method.
 func (m *MockService) CheckAvailability(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -127,6 +159,20 @@ func (mr *MockServiceMockRecorder) CheckAvailability(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckAvailability", reflect.TypeOf((*MockService)(nil).CheckAvailability), arg0)
 }
 
+// CircuitBreakerState mocks base method.
+func (m *MockService) CircuitBreakerState() circuitbreaker.State {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CircuitBreakerState")
+	ret0, _ := ret[0].(circuitbreaker.State)
+	return ret0
+}
+
+// CircuitBreakerState indicates an expected call of CircuitBreakerState.
+func (mr *MockServiceMockRecorder) CircuitBreakerState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CircuitBreakerState", reflect.TypeOf((*MockService)(nil).CircuitBreakerState))
+}
+
 // DBStats mocks base method.
 func (m *MockService) DBStats(arg0 context.Context) sql.DBStats {
 	m.ctrl.T.Helper()
@@ -169,6 +215,21 @@ func (mr *MockServiceMockRecorder) DownloadBook(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadBook", reflect.TypeOf((*MockService)(nil).DownloadBook), arg0, arg1, arg2)
 }
 
+// EnqueueJob mocks base method.
+func (m *MockService) EnqueueJob(arg0 context.Context, arg1 string, arg2 *model.Book) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueJob", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueJob indicates an expected call of EnqueueJob.
+func (mr *MockServiceMockRecorder) EnqueueJob(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueJob", reflect.TypeOf((*MockService)(nil).EnqueueJob), arg0, arg1, arg2)
+}
+
 // Explore mocks base method.
 func (m *MockService) Explore(arg0 context.Context, arg1 *service.UpdateStats) error {
 	m.ctrl.T.Helper()
@@ -197,6 +258,75 @@ func (mr *MockServiceMockRecorder) ExploreBook(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExploreBook", reflect.TypeOf((*MockService)(nil).ExploreBook), arg0, arg1, arg2)
 }
 
+// ForceCloseCircuitBreaker mocks base method.
+func (m *MockService) ForceCloseCircuitBreaker(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceCloseCircuitBreaker", arg0)
+}
+
+// ForceCloseCircuitBreaker indicates an expected call of ForceCloseCircuitBreaker.
+func (mr *MockServiceMockRecorder) ForceCloseCircuitBreaker(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceCloseCircuitBreaker", reflect.TypeOf((*MockService)(nil).ForceCloseCircuitBreaker), arg0)
+}
+
+// ForceOpenCircuitBreaker mocks base method.
+func (m *MockService) ForceOpenCircuitBreaker(arg0 string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceOpenCircuitBreaker", arg0)
+}
+
+// ForceOpenCircuitBreaker indicates an expected call of ForceOpenCircuitBreaker.
+func (mr *MockServiceMockRecorder) ForceOpenCircuitBreaker(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceOpenCircuitBreaker", reflect.TypeOf((*MockService)(nil).ForceOpenCircuitBreaker), arg0)
+}
+
+// Job mocks base method.
+func (m *MockService) Job(arg0 context.Context, arg1 int) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Job", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Job indicates an expected call of Job.
+func (mr *MockServiceMockRecorder) Job(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockService)(nil).Job), arg0, arg1)
+}
+
+// JobRun mocks base method.
+func (m *MockService) JobRun(arg0 context.Context, arg1 int) (*model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobRun", arg0, arg1)
+	ret0, _ := ret[0].(*model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JobRun indicates an expected call of JobRun.
+func (mr *MockServiceMockRecorder) JobRun(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobRun", reflect.TypeOf((*MockService)(nil).JobRun), arg0, arg1)
+}
+
+// JobRuns mocks base method.
+func (m *MockService) JobRuns(arg0 context.Context, arg1 string, arg2, arg3 int) ([]model.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JobRuns", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]model.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// JobRuns indicates an expected call of JobRuns.
+func (mr *MockServiceMockRecorder) JobRuns(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobRuns", reflect.TypeOf((*MockService)(nil).JobRuns), arg0, arg1, arg2, arg3)
+}
+
 // Name mocks base method.
 func (m *MockService) Name() string {
 	m.ctrl.T.Helper()
@@ -211,6 +341,34 @@ func (mr *MockServiceMockRecorder) Name() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockService)(nil).Name))
 }
 
+// PatchBookChapters mocks base method.
+func (m *MockService) PatchBookChapters(arg0 context.Context, arg1 *model.Book) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchBookChapters", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchBookChapters indicates an expected call of PatchBookChapters.
+func (mr *MockServiceMockRecorder) PatchBookChapters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchBookChapters", reflect.TypeOf((*MockService)(nil).PatchBookChapters), arg0, arg1)
+}
+
+// PatchChapters mocks base method.
+func (m *MockService) PatchChapters(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchChapters", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchChapters indicates an expected call of PatchChapters.
+func (mr *MockServiceMockRecorder) PatchChapters(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchChapters", reflect.TypeOf((*MockService)(nil).PatchChapters), arg0)
+}
+
 // PatchDownloadStatus mocks base method.
 func (m *MockService) PatchDownloadStatus(arg0 context.Context, arg1 *service.PatchStorageStats) error {
 	m.ctrl.T.Helper()
@@ -297,6 +455,79 @@ func (mr *MockServiceMockRecorder) RandomBooks(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RandomBooks", reflect.TypeOf((*MockService)(nil).RandomBooks), arg0, arg1)
 }
 
+// RequeueBookTask mocks base method.
+func (m *MockService) RequeueBookTask(arg0 context.Context, arg1 int) (*model.BookTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueBookTask", arg0, arg1)
+	ret0, _ := ret[0].(*model.BookTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueBookTask indicates an expected call of RequeueBookTask.
+func (mr *MockServiceMockRecorder) RequeueBookTask(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueBookTask", reflect.TypeOf((*MockService)(nil).RequeueBookTask), arg0, arg1)
+}
+
+// RunOperation mocks base method.
+func (m *MockService) RunOperation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunOperation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunOperation indicates an expected call of RunOperation.
+func (mr *MockServiceMockRecorder) RunOperation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunOperation", reflect.TypeOf((*MockService)(nil).RunOperation), arg0, arg1)
+}
+
+// RunQueuedJobs mocks base method.
+func (m *MockService) RunQueuedJobs(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunQueuedJobs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RunQueuedJobs indicates an expected call of RunQueuedJobs.
+func (mr *MockServiceMockRecorder) RunQueuedJobs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunQueuedJobs", reflect.TypeOf((*MockService)(nil).RunQueuedJobs), arg0)
+}
+
+// Schedules mocks base method.
+func (m *MockService) Schedules(arg0 context.Context, arg1 time.Time, arg2 int) ([]model.Schedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedules", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.Schedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Schedules indicates an expected call of Schedules.
+func (mr *MockServiceMockRecorder) Schedules(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedules", reflect.TypeOf((*MockService)(nil).Schedules), arg0, arg1, arg2)
+}
+
+// SearchContent mocks base method.
+func (m *MockService) SearchContent(arg0 context.Context, arg1 string, arg2, arg3 int) ([]model.ContentMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchContent", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]model.ContentMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchContent indicates an expected call of SearchContent.
+func (mr *MockServiceMockRecorder) SearchContent(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchContent", reflect.TypeOf((*MockService)(nil).SearchContent), arg0, arg1, arg2, arg3)
+}
+
 // Stats mocks base method.
 func (m *MockService) Stats(arg0 context.Context) repo.Summary {
 	m.ctrl.T.Helper()
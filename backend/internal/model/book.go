@@ -84,6 +84,12 @@ func simplified(s string) string {
 	return gojianfan.T2S(s)
 }
 
+// NormalizeSearchText converts s into the form stored for title / writer search,
+// so traditional and simplified chinese, spaces and cases are matched alike
+func NormalizeSearchText(s string) string {
+	return strings.ToLower(simplified(strings.ReplaceAll(s, " ", "")))
+}
+
 func strToShortHex(s string) string {
 	b := []byte(s)
 	encoded := base64.StdEncoding.EncodeToString(b)
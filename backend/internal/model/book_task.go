@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+type BookTaskStatus string
+
+const (
+	BookTaskStatusQueued BookTaskStatus = "queued"
+	BookTaskStatusLeased BookTaskStatus = "leased"
+	BookTaskStatusDone   BookTaskStatus = "done"
+	BookTaskStatusDead   BookTaskStatus = "dead"
+)
+
+// BookTask is an operation of a book in the queue shared by all workers. it is
+// leased by one worker at a time, and is taken over by another worker once
+// the lease expired, so the operation may run more than once
+type BookTask struct {
+	ID             int            `json:"id"`
+	Site           string         `json:"site"`
+	Operation      string         `json:"operation"`
+	BookID         int            `json:"book_id"`
+	HashCode       int            `json:"hash_code"`
+	Status         BookTaskStatus `json:"status"`
+	Attempts       int            `json:"attempts"`
+	AvailableAt    time.Time      `json:"available_at"`
+	LeaseOwner     string         `json:"lease_owner"`
+	LeaseExpiresAt *time.Time     `json:"lease_expires_at"`
+	Error          string         `json:"error"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// Fail set the task to be retried, or to be dead lettered once it was
+// attempted for maxAttempts times. interrupted task is always retried, and
+// the attempt counted by its lease is given back, so tasks requeued on
+// shutdown are not dead lettered as expired leases
+func (task *BookTask) Fail(err error, maxAttempts int) {
+	var outcome JobOutcome
+	outcome, task.Error = jobOutcome(err)
+
+	if outcome == JobOutcomeInterrupted {
+		task.Status = BookTaskStatusQueued
+		if task.Attempts > 0 {
+			task.Attempts--
+		}
+	} else if task.Attempts < maxAttempts {
+		task.Status = BookTaskStatusQueued
+	} else {
+		task.Status = BookTaskStatusDead
+	}
+}
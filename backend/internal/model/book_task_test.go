@@ -0,0 +1,68 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookTask_Fail(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		task         BookTask
+		err          error
+		wantStatus   BookTaskStatus
+		wantAttempts int
+		wantError    string
+	}{
+		{
+			name:         "retry task not reaching max attempts",
+			task:         BookTask{Status: BookTaskStatusLeased, Attempts: 1},
+			err:          errors.New("some error"),
+			wantStatus:   BookTaskStatusQueued,
+			wantAttempts: 1,
+			wantError:    "some error",
+		},
+		{
+			name:         "dead letter task reaching max attempts",
+			task:         BookTask{Status: BookTaskStatusLeased, Attempts: 3},
+			err:          errors.New("some error"),
+			wantStatus:   BookTaskStatusDead,
+			wantAttempts: 3,
+			wantError:    "some error",
+		},
+		{
+			name:         "retry interrupted task reaching max attempts",
+			task:         BookTask{Status: BookTaskStatusLeased, Attempts: 3},
+			err:          fmt.Errorf("download interrupted: %w", context.Canceled),
+			wantStatus:   BookTaskStatusQueued,
+			wantAttempts: 2,
+			wantError:    "download interrupted: context canceled",
+		},
+		{
+			name:         "not count attempt of interrupted task",
+			task:         BookTask{Status: BookTaskStatusLeased, Attempts: 1},
+			err:          fmt.Errorf("update interrupted: %w", context.DeadlineExceeded),
+			wantStatus:   BookTaskStatusQueued,
+			wantAttempts: 0,
+			wantError:    "update interrupted: context deadline exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.task.Fail(test.err, 3)
+			assert.Equal(t, test.wantStatus, test.task.Status)
+			assert.Equal(t, test.wantAttempts, test.task.Attempts)
+			assert.Equal(t, test.wantError, test.task.Error)
+		})
+	}
+}
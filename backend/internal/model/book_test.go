@@ -142,3 +142,40 @@ func TestBook_String(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeSearchText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		s      string
+		expect string
+	}{
+		{
+			name:   "convert traditional chinese to simplified",
+			s:      "測試書名",
+			expect: "测试书名",
+		},
+		{
+			name:   "remove spaces and lower cases",
+			s:      "Title 1 ABC",
+			expect: "title1abc",
+		},
+		{
+			name:   "empty string",
+			s:      "",
+			expect: "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := NormalizeSearchText(test.s)
+
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
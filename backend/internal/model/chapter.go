@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 var ChapterEndKeywords = []string{
@@ -22,11 +23,12 @@ var (
 )
 
 type Chapter struct {
-	Index   int
-	URL     string
-	Title   string
-	Content string
-	Error   error
+	Index     int
+	URL       string
+	Title     string
+	Content   string
+	FetchedAt time.Time
+	Error     error
 }
 
 type Chapters []Chapter
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	SnippetRadius   = 30
+	HighlightStart  = "<em>"
+	HighlightEnd    = "</em>"
+	snippetEllipsis = "..."
+)
+
+// ContentMatch is a chapter of a downloaded book which contains the searched
+// keyword, the snippet shows the keyword with its surrounding text
+type ContentMatch struct {
+	Book         Book   `json:"book"`
+	ChapterIndex int    `json:"chapter_index"`
+	ChapterTitle string `json:"chapter_title"`
+	Snippet      string `json:"snippet"`
+}
+
+var spacesRegex = regexp.MustCompile(`\s+`)
+
+func compactSpaces(s string) string {
+	return spacesRegex.ReplaceAllString(s, " ")
+}
+
+// ContentSnippet return at most radius characters before and after the first
+// keyword found in content, the keyword is wrapped by highlight tags.
+// the first 2 * radius characters are returned without highlight if content
+// does not contain keyword, so the match is never shown without snippet
+func ContentSnippet(content, keyword string, radius int) string {
+	idx := strings.Index(content, keyword)
+	if keyword == "" || idx < 0 {
+		head := []rune(strings.TrimSpace(compactSpaces(content)))
+		if len(head) > 2*radius {
+			return string(head[:2*radius]) + snippetEllipsis
+		}
+
+		return string(head)
+	}
+
+	before := []rune(compactSpaces(content[:idx]))
+	after := []rune(compactSpaces(content[idx+len(keyword):]))
+
+	prefix, suffix := "", ""
+	if len(before) > radius {
+		before = before[len(before)-radius:]
+		prefix = snippetEllipsis
+	}
+	if len(after) > radius {
+		after = after[:radius]
+		suffix = snippetEllipsis
+	}
+
+	return prefix + string(before) + HighlightStart + keyword + HighlightEnd + string(after) + suffix
+}
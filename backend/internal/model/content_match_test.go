@@ -0,0 +1,80 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContentSnippet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		keyword string
+		radius  int
+		expect  string
+	}{
+		{
+			name:    "highlight keyword in short content",
+			content: "他說了一句話",
+			keyword: "一句",
+			radius:  10,
+			expect:  "他說了<em>一句</em>話",
+		},
+		{
+			name:    "trim content longer than radius",
+			content: "一二三四五關鍵字六七八九十",
+			keyword: "關鍵字",
+			radius:  2,
+			expect:  "...四五<em>關鍵字</em>六七...",
+		},
+		{
+			name:    "compact line breaks around keyword",
+			content: "first line\n\nsecond line",
+			keyword: "second",
+			radius:  20,
+			expect:  "first line <em>second</em> line",
+		},
+		{
+			name:    "highlight the first keyword only",
+			content: "abc abc",
+			keyword: "abc",
+			radius:  10,
+			expect:  "<em>abc</em> abc",
+		},
+		{
+			name:    "return head of content if keyword not found",
+			content: "some content",
+			keyword: "not exist",
+			radius:  10,
+			expect:  "some content",
+		},
+		{
+			name:    "trim head of content if keyword not found",
+			content: "一二三四五\n六七八九十",
+			keyword: "not exist",
+			radius:  2,
+			expect:  "一二三四...",
+		},
+		{
+			name:    "return head of content if keyword is empty",
+			content: "some content",
+			keyword: "",
+			radius:  10,
+			expect:  "some content",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := ContentSnippet(test.content, test.keyword, test.radius)
+
+			assert.Equal(t, test.expect, result)
+		})
+	}
+}
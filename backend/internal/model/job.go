@@ -0,0 +1,131 @@
+package model
+
+import (
+	"time"
+)
+
+// QueueableJobOperations are the operations can be enqueued on demand, for
+// both site and book
+var QueueableJobOperations = []string{
+	JobOperationUpdate,
+	JobOperationExplore,
+	JobOperationDownload,
+	JobOperationValidate,
+	JobOperationProcess,
+	JobOperationPatchChapters,
+}
+
+func IsQueueableJobOperation(operation string) bool {
+	for _, op := range QueueableJobOperations {
+		if op == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SchedulableJobOperations are the operations can be run on whole site by
+// schedule, including the ones only run as part of process otherwise
+var SchedulableJobOperations = []string{
+	JobOperationCheckAvailability,
+	JobOperationUpdate,
+	JobOperationExplore,
+	JobOperationValidate,
+	JobOperationDownload,
+	JobOperationPatchStatus,
+	JobOperationPatchMissingRecords,
+	JobOperationPatchChapters,
+	JobOperationProcess,
+}
+
+func IsSchedulableJobOperation(operation string) bool {
+	for _, op := range SchedulableJobOperations {
+		if op == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExclusiveJobOperations are the site operations run by one worker at a time
+// across all pods. update and download are not included, as their books are
+// leased from the shared book task queue by all workers
+var ExclusiveJobOperations = []string{
+	JobOperationExplore,
+	JobOperationValidate,
+	JobOperationPatchStatus,
+	JobOperationPatchMissingRecords,
+	JobOperationPatchChapters,
+}
+
+func IsExclusiveJobOperation(operation string) bool {
+	for _, op := range ExclusiveJobOperations {
+		if op == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Job is an operation enqueued on demand and run by worker. it is a site job
+// if BookID is 0, otherwise the operation is run on the book only. running job
+// is leased by the worker claiming it, and is claimed by another worker once
+// the lease expired
+type Job struct {
+	ID             int              `json:"id"`
+	Site           string           `json:"site"`
+	Operation      string           `json:"operation"`
+	BookID         int              `json:"book_id,omitempty"`
+	HashCode       int              `json:"hash_code,omitempty"`
+	Status         JobOutcome       `json:"status"`
+	Error          string           `json:"error"`
+	Stats          map[string]int64 `json:"stats"`
+	CreatedAt      time.Time        `json:"created_at"`
+	StartedAt      *time.Time       `json:"started_at"`
+	EndedAt        *time.Time       `json:"ended_at"`
+	Attempts       int              `json:"attempts"`
+	LeaseOwner     string           `json:"lease_owner"`
+	LeaseExpiresAt *time.Time       `json:"lease_expires_at"`
+}
+
+func NewJob(site, operation string, bk *Book, createdAt time.Time) *Job {
+	job := &Job{
+		Site:      site,
+		Operation: operation,
+		Status:    JobOutcomeQueued,
+		CreatedAt: createdAt,
+	}
+
+	if bk != nil {
+		job.BookID, job.HashCode = bk.ID, bk.HashCode
+	}
+
+	return job
+}
+
+func (job Job) IsBookJob() bool {
+	return job.BookID > 0
+}
+
+// Finish set the status by the error returned by operation. interrupted job
+// is queued again, so it is run by the next worker claiming it, and the
+// attempt counted by its claim is given back, so jobs queued again on
+// shutdown are not failed as expired leases
+func (job *Job) Finish(endedAt time.Time, err error, stats map[string]int64) {
+	job.Stats = stats
+	job.Status, job.Error = jobOutcome(err)
+
+	if job.Status == JobOutcomeInterrupted {
+		job.Status = JobOutcomeQueued
+		if job.Attempts > 0 {
+			job.Attempts--
+		}
+
+		return
+	}
+
+	job.EndedAt = &endedAt
+}
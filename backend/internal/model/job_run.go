@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type JobOutcome string
+
+const (
+	JobOutcomeQueued      JobOutcome = "queued"
+	JobOutcomeRunning     JobOutcome = "running"
+	JobOutcomeSuccess     JobOutcome = "success"
+	JobOutcomeFail        JobOutcome = "fail"
+	JobOutcomeInterrupted JobOutcome = "interrupted"
+)
+
+const (
+	JobOperationCheckAvailability   = "check-availability"
+	JobOperationUpdate              = "update"
+	JobOperationExplore             = "explore"
+	JobOperationValidate            = "validate"
+	JobOperationDownload            = "download"
+	JobOperationPatchStatus         = "patch-status"
+	JobOperationPatchMissingRecords = "patch-missing-records"
+	JobOperationPatchChapters       = "patch-chapters"
+	JobOperationProcess             = "process"
+)
+
+// JobRun is a run of an operation on a site. EndedAt is nil and Outcome is
+// running until the operation returns, Stats is the snapshot of operation
+// statistics at that time
+type JobRun struct {
+	ID        int              `json:"id"`
+	Site      string           `json:"site"`
+	Operation string           `json:"operation"`
+	StartedAt time.Time        `json:"started_at"`
+	EndedAt   *time.Time       `json:"ended_at"`
+	Outcome   JobOutcome       `json:"outcome"`
+	Error     string           `json:"error"`
+	Stats     map[string]int64 `json:"stats"`
+}
+
+func NewJobRun(site, operation string, startedAt time.Time) *JobRun {
+	return &JobRun{
+		Site:      site,
+		Operation: operation,
+		StartedAt: startedAt,
+		Outcome:   JobOutcomeRunning,
+	}
+}
+
+// jobOutcome return the outcome by the error returned by operation. operation
+// stopped by cancelled context is interrupted rather than failed
+func jobOutcome(err error) (JobOutcome, string) {
+	switch {
+	case err == nil:
+		return JobOutcomeSuccess, ""
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return JobOutcomeInterrupted, err.Error()
+	default:
+		return JobOutcomeFail, err.Error()
+	}
+}
+
+// Finish set the outcome by the error returned by operation
+func (run *JobRun) Finish(endedAt time.Time, err error, stats map[string]int64) {
+	run.EndedAt = &endedAt
+	run.Stats = stats
+	run.Outcome, run.Error = jobOutcome(err)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJobRun(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, &JobRun{
+		Site:      "test",
+		Operation: JobOperationUpdate,
+		StartedAt: startedAt,
+		Outcome:   JobOutcomeRunning,
+	}, NewJobRun("test", JobOperationUpdate, startedAt))
+}
+
+func TestJobRun_Finish(t *testing.T) {
+	t.Parallel()
+
+	endedAt := time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		err    error
+		stats  map[string]int64
+		expect *JobRun
+	}{
+		{
+			name:  "success",
+			err:   nil,
+			stats: map[string]int64{"total": 1},
+			expect: &JobRun{
+				EndedAt: &endedAt,
+				Outcome: JobOutcomeSuccess,
+				Stats:   map[string]int64{"total": 1},
+			},
+		},
+		{
+			name: "fail",
+			err:  errors.New("some error"),
+			expect: &JobRun{
+				EndedAt: &endedAt,
+				Outcome: JobOutcomeFail,
+				Error:   "some error",
+			},
+		},
+		{
+			name: "interrupted by cancelled context",
+			err:  fmt.Errorf("update interrupted: %w", context.Canceled),
+			expect: &JobRun{
+				EndedAt: &endedAt,
+				Outcome: JobOutcomeInterrupted,
+				Error:   "update interrupted: context canceled",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			run := &JobRun{Outcome: JobOutcomeRunning}
+			run.Finish(endedAt, test.err, test.stats)
+			assert.Equal(t, test.expect, run)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsQueueableJobOperation(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsQueueableJobOperation(JobOperationProcess))
+	assert.True(t, IsQueueableJobOperation(JobOperationValidate))
+	assert.True(t, IsQueueableJobOperation(JobOperationPatchChapters))
+	assert.False(t, IsQueueableJobOperation(JobOperationPatchStatus))
+	assert.False(t, IsQueueableJobOperation(""))
+}
+
+func TestIsSchedulableJobOperation(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsSchedulableJobOperation(JobOperationProcess))
+	assert.True(t, IsSchedulableJobOperation(JobOperationCheckAvailability))
+	assert.True(t, IsSchedulableJobOperation(JobOperationPatchStatus))
+	assert.True(t, IsSchedulableJobOperation(JobOperationPatchMissingRecords))
+	assert.True(t, IsSchedulableJobOperation(JobOperationPatchChapters))
+	assert.False(t, IsSchedulableJobOperation(""))
+}
+
+func TestNewJob(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		bk     *Book
+		expect *Job
+	}{
+		{
+			name: "site job",
+			bk:   nil,
+			expect: &Job{
+				Site: "test", Operation: JobOperationUpdate,
+				Status: JobOutcomeQueued, CreatedAt: createdAt,
+			},
+		},
+		{
+			name: "book job",
+			bk:   &Book{Site: "test", ID: 1, HashCode: 100},
+			expect: &Job{
+				Site: "test", Operation: JobOperationUpdate, BookID: 1, HashCode: 100,
+				Status: JobOutcomeQueued, CreatedAt: createdAt,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := NewJob("test", JobOperationUpdate, test.bk, createdAt)
+			assert.Equal(t, test.expect, job)
+			assert.Equal(t, test.bk != nil, job.IsBookJob())
+		})
+	}
+}
+
+func TestJob_Finish(t *testing.T) {
+	t.Parallel()
+
+	endedAt := time.Date(2026, 10, 18, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		err    error
+		expect *Job
+	}{
+		{
+			name: "failed job",
+			err:  errors.New("some error"),
+			expect: &Job{
+				Status:   JobOutcomeFail,
+				Error:    "some error",
+				Stats:    map[string]int64{"total": 1},
+				EndedAt:  &endedAt,
+				Attempts: 1,
+			},
+		},
+		{
+			name: "interrupted job is queued again without counting its attempt",
+			err:  context.Canceled,
+			expect: &Job{
+				Status:   JobOutcomeQueued,
+				Error:    context.Canceled.Error(),
+				Stats:    map[string]int64{"total": 1},
+				Attempts: 0,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := &Job{Status: JobOutcomeRunning, Attempts: 1}
+			job.Finish(endedAt, test.err, map[string]int64{"total": 1})
+
+			assert.Equal(t, test.expect, job)
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Schedule is the cron schedule of an operation of site, with the next times
+// it is planned to run
+type Schedule struct {
+	Site      string      `json:"site"`
+	Operation string      `json:"operation"`
+	Cron      string      `json:"cron"`
+	NextRuns  []time.Time `json:"next_runs"`
+}
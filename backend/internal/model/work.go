@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWorkWithoutSource = errors.New("work without source")
+)
+
+// Work is a title written by a writer, which can be published in multiple
+// sites. Books of the same work share the same checksum and writer checksum.
+type Work struct {
+	Checksum       string    `json:"checksum"`
+	WriterChecksum string    `json:"writer_checksum"`
+	Canonical      Book      `json:"canonical"`
+	Sources        BookGroup `json:"sources"`
+}
+
+// NewWork sort the books from the most complete / latest copy to the least
+// one and pick the first one as the canonical book of the work
+func NewWork(group BookGroup) (*Work, error) {
+	if len(group) == 0 {
+		return nil, ErrWorkWithoutSource
+	}
+
+	sources := make(BookGroup, len(group))
+	copy(sources, group)
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].MoreCompleteThan(sources[j])
+	})
+
+	return &Work{
+		Checksum:       sources[0].Checksum(),
+		WriterChecksum: sources[0].Writer.Checksum(),
+		Canonical:      sources[0],
+		Sources:        sources,
+	}, nil
+}
+
+func (bk Book) completeness() int {
+	switch {
+	case bk.Status == StatusEnd && bk.IsDownloaded:
+		return 3
+	case bk.Status == StatusEnd:
+		return 2
+	case bk.Status == StatusInProgress:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// updateDateLayouts are the update date formats shown by sites, update date
+// is compared as text if it is not in any of them
+var updateDateLayouts = []string{
+	"2006-1-2 15:04:05",
+	"2006-1-2 15:04",
+	"2006-1-2",
+	"2006/1/2 15:04:05",
+	"2006/1/2 15:04",
+	"2006/1/2",
+	"2006年1月2日",
+	"06-1-2",
+	"2006",
+}
+
+func parseUpdateDate(date string) (time.Time, bool) {
+	date = strings.TrimSpace(date)
+	for _, layout := range updateDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// laterUpdateDate return whether date is later than other. parsed date is
+// later than the one cannot be parsed, as sites without update date show
+// placeholder instead
+func laterUpdateDate(date, other string) bool {
+	t, ok := parseUpdateDate(date)
+	otherT, otherOK := parseUpdateDate(other)
+
+	switch {
+	case ok && otherOK:
+		return t.After(otherT)
+	case ok != otherOK:
+		return ok
+	default:
+		return date > other
+	}
+}
+
+// MoreCompleteThan compare books by status and download state first, then
+// by parsed update date and hash code for the latest copy
+func (bk Book) MoreCompleteThan(other Book) bool {
+	if bk.completeness() != other.completeness() {
+		return bk.completeness() > other.completeness()
+	}
+
+	if laterUpdateDate(bk.UpdateDate, other.UpdateDate) {
+		return true
+	} else if laterUpdateDate(other.UpdateDate, bk.UpdateDate) {
+		return false
+	}
+
+	return bk.HashCode > other.HashCode
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewWork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		group     BookGroup
+		want      *Work
+		wantError error
+	}{
+		{
+			name: "pick downloaded book as canonical",
+			group: BookGroup{
+				{Site: "a", ID: 1, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusInProgress, UpdateDate: "2023"},
+				{Site: "b", ID: 2, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusEnd, IsDownloaded: true, UpdateDate: "2021"},
+				{Site: "c", ID: 3, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusEnd, UpdateDate: "2022"},
+				{Site: "d", ID: 4, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusError},
+			},
+			want: &Work{
+				Checksum:       "dGl0bGU=",
+				WriterChecksum: "d3JpdGVy",
+				Canonical:      Book{Site: "b", ID: 2, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusEnd, IsDownloaded: true, UpdateDate: "2021"},
+				Sources: BookGroup{
+					{Site: "b", ID: 2, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusEnd, IsDownloaded: true, UpdateDate: "2021"},
+					{Site: "c", ID: 3, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusEnd, UpdateDate: "2022"},
+					{Site: "a", ID: 1, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusInProgress, UpdateDate: "2023"},
+					{Site: "d", ID: 4, Title: "title", Writer: Writer{Name: "writer"}, Status: StatusError},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name: "pick latest book if status are the same",
+			group: BookGroup{
+				{Site: "a", ID: 1, HashCode: 1, Status: StatusInProgress, UpdateDate: "2022"},
+				{Site: "a", ID: 1, HashCode: 2, Status: StatusInProgress, UpdateDate: "2022"},
+				{Site: "b", ID: 2, Status: StatusInProgress, UpdateDate: "2023"},
+			},
+			want: &Work{
+				Canonical: Book{Site: "b", ID: 2, Status: StatusInProgress, UpdateDate: "2023"},
+				Sources: BookGroup{
+					{Site: "b", ID: 2, Status: StatusInProgress, UpdateDate: "2023"},
+					{Site: "a", ID: 1, HashCode: 2, Status: StatusInProgress, UpdateDate: "2022"},
+					{Site: "a", ID: 1, HashCode: 1, Status: StatusInProgress, UpdateDate: "2022"},
+				},
+			},
+			wantError: nil,
+		},
+		{
+			name:      "empty group",
+			group:     BookGroup{},
+			want:      nil,
+			wantError: ErrWorkWithoutSource,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewWork(test.group)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestBook_MoreCompleteThan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		bk    Book
+		other Book
+		want  bool
+	}{
+		{
+			name:  "end book is more complete than in progress one",
+			bk:    Book{Status: StatusEnd, UpdateDate: "2021-01-01"},
+			other: Book{Status: StatusInProgress, UpdateDate: "2023-01-01"},
+			want:  true,
+		},
+		{
+			name:  "compare parsed update date",
+			bk:    Book{Status: StatusInProgress, UpdateDate: "2023-10-01"},
+			other: Book{Status: StatusInProgress, UpdateDate: "2023-9-1"},
+			want:  true,
+		},
+		{
+			name:  "compare update date in different layouts",
+			bk:    Book{Status: StatusInProgress, UpdateDate: "2023/1/2 10:00"},
+			other: Book{Status: StatusInProgress, UpdateDate: "2023-01-02"},
+			want:  true,
+		},
+		{
+			name:  "parsed update date is later than unknown one",
+			bk:    Book{Status: StatusInProgress, UpdateDate: "2023-01-01"},
+			other: Book{Status: StatusInProgress, UpdateDate: "unknown"},
+			want:  true,
+		},
+		{
+			name:  "compare hash code if update date are the same",
+			bk:    Book{Status: StatusInProgress, UpdateDate: "2023-01-01", HashCode: 1},
+			other: Book{Status: StatusInProgress, UpdateDate: "2023-1-1", HashCode: 2},
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, test.bk.MoreCompleteThan(test.other))
+		})
+	}
+}
@@ -3,5 +3,13 @@ package repo
 import "errors"
 
 var (
-	ErrBookNotExist = errors.New("no records found")
+	ErrBookNotExist   = errors.New("no records found")
+	ErrJobRunNotExist = errors.New("job run not found")
+	ErrJobNotExist    = errors.New("job not found")
+	ErrJobLeaseLost   = errors.New("job lease lost")
+
+	ErrBookTaskNotExist  = errors.New("book task not found")
+	ErrBookTaskLeaseLost = errors.New("book task lease lost")
+
+	ErrOperationLocked = errors.New("operation locked")
 )
@@ -0,0 +1,11 @@
+package repo
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escape the wildcards in s, so it is matched literally by like
+// pattern with escape '\'
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
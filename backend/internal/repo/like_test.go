@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeLike(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain text", s: "title", want: "title"},
+		{name: "wildcards", s: "100%_done", want: `100\%\_done`},
+		{name: "escape character", s: `a\b`, want: `a\\b`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, EscapeLike(test.s))
+		})
+	}
+}
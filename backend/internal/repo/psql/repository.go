@@ -77,18 +77,29 @@ const (
 )
 
 func rowsToBook(rows *sql.Rows) (*model.Book, error) {
+	bk, err := rowsToBookWith(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bk, nil
+}
+
+// rowsToBookWith scan the book fields of QueryField followed by the extra
+// selected fields
+func rowsToBookWith(rows *sql.Rows, extra ...any) (model.Book, error) {
 	var (
 		errStr    string
 		statusStr string
+		bk        model.Book
 	)
-	bk := new(model.Book)
-	err := rows.Scan(
+	err := rows.Scan(append([]any{
 		&bk.Site, &bk.ID, &bk.HashCode, &bk.Title,
 		&bk.Writer.ID, &bk.Writer.Name, &bk.Type,
 		&bk.UpdateDate, &bk.UpdateChapter, &statusStr, &bk.IsDownloaded, &errStr,
-	)
+	}, extra...)...)
 	if err != nil {
-		return nil, fmt.Errorf("fail to query book by site id: %w", err)
+		return bk, fmt.Errorf("fail to query book by site id: %w", err)
 	}
 	if errStr != "" {
 		bk.Error = fmt.Errorf(errStr)
@@ -208,36 +219,14 @@ func (r *PsqlRepo) FindBooksForDownload() (<-chan model.Book, error) {
 
 	return rowsToBookChan(rows), nil
 }
-func (r *PsqlRepo) FindBooksByTitleWriter(title, writer string, limit, offset int) ([]model.Book, error) {
-	if title != "" {
-		title = fmt.Sprintf("%%%v%%", title)
-	}
-
-	if writer != "" {
-		writer = fmt.Sprintf("%%%v%%", writer)
-	}
-
-	rows, err := r.db.Query(
-		fmt.Sprintf(
-			`select %s from %s 
-			where books.site=$1 and books.status != $4 and books.title != '' and writers.name != '' and
-				(books.title like $2 or writers.name like $3)
-			order by books.update_date desc, books.id desc limit $5 offset $6`,
-			QueryField, QueryTable,
-		),
-		r.site, title, writer, model.StatusCode(model.StatusError).String(), limit, offset,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("fail to query book by title writer: %w", err)
-	}
-
-	bks := make([]model.Book, 0)
-	for bk := range rowsToBookChan(rows) {
-		bks = append(bks, bk)
-	}
+func (r *PsqlRepo) FindDownloadedBooksWithoutChapters() (<-chan model.Book, error) {
+	return nil, errors.New("not implemented")
+}
 
-	return bks, nil
+func (r *PsqlRepo) SearchBooks(title, writer string, limit, offset int) ([]model.Book, error) {
+	return nil, errors.New("not implemented")
 }
+
 func (r *PsqlRepo) FindBooksByRandom(limit int) ([]model.Book, error) {
 	rows, err := r.db.Query(
 		fmt.Sprintf(
@@ -270,6 +259,29 @@ func (r *PsqlRepo) FindBookGroupByIDHash(id, hashCode int) (model.BookGroup, err
 	return nil, errors.New("Not implemented error")
 }
 
+func (r *PsqlRepo) FindWorkByChecksum(checksum, writerChecksum string) (model.BookGroup, error) {
+	rows, err := r.db.Query(
+		fmt.Sprintf(
+			`select %s from %s
+			where books.checksum=$1 and books.writer_checksum=$2
+				and books.checksum != '' and books.writer_checksum != ''
+			order by books.site, books.id, books.hash_code desc`,
+			QueryField, QueryTable,
+		),
+		checksum, writerChecksum,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query books by checksum: %w", err)
+	}
+
+	group := make(model.BookGroup, 0)
+	for bk := range rowsToBookChan(rows) {
+		group = append(group, bk)
+	}
+
+	return group, nil
+}
+
 func generateUpdateStatusCondition(length int) string {
 	sqlStmt := "(update_date < '" + strconv.Itoa(time.Now().Year()-1) + "'"
 
@@ -329,6 +341,154 @@ func (r *PsqlRepo) SaveWriter(writer *model.Writer) error {
 	return nil
 }
 
+// chapter related
+func scanChapter(scanner interface{ Scan(...any) error }) (model.Chapter, error) {
+	var (
+		ch        model.Chapter
+		fetchedAt sql.NullTime
+		chErr     string
+	)
+
+	err := scanner.Scan(&ch.Index, &ch.URL, &ch.Title, &ch.Content, &fetchedAt, &chErr)
+	if err != nil {
+		return ch, err
+	}
+
+	ch.FetchedAt = fetchedAt.Time
+	if chErr != "" {
+		ch.Error = errors.New(chErr)
+	}
+
+	return ch, nil
+}
+
+func (r *PsqlRepo) SaveChapters(bk *model.Book, chapters model.Chapters) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("fail to begin save chapters transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i := range chapters {
+		if chapters[i].FetchedAt.IsZero() {
+			chapters[i].FetchedAt = time.Now().UTC().Truncate(time.Microsecond)
+		}
+
+		var chErr string
+		if chapters[i].Error != nil {
+			chErr = chapters[i].Error.Error()
+		}
+
+		_, err := tx.Exec(
+			`insert into chapters
+			(site, book_id, hash_code, chapter_index, url, title, content, fetched_at, error)
+			values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			on conflict (site, book_id, hash_code, chapter_index)
+			do update set url=$5, title=$6, content=$7, fetched_at=$8, error=$9`,
+			bk.Site, bk.ID, bk.HashCode, chapters[i].Index, chapters[i].URL,
+			chapters[i].Title, chapters[i].Content, chapters[i].FetchedAt, chErr,
+		)
+		if err != nil {
+			return fmt.Errorf("fail to save chapter %d: %w", chapters[i].Index, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("fail to commit save chapters transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PsqlRepo) FindChaptersByBook(bk *model.Book) (model.Chapters, error) {
+	rows, err := r.db.Query(
+		`select chapter_index, url, title, content, fetched_at, error from chapters
+		where site=$1 and book_id=$2 and hash_code=$3
+		order by chapter_index`,
+		bk.Site, bk.ID, bk.HashCode,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fail to list chapters by book: %w", err)
+	}
+	defer rows.Close()
+
+	chapters := make(model.Chapters, 0)
+	for rows.Next() {
+		ch, err := scanChapter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("fail to list chapters by book: %w", err)
+		}
+
+		chapters = append(chapters, ch)
+	}
+
+	return chapters, rows.Err()
+}
+
+func (r *PsqlRepo) FindChapter(bk *model.Book, index int) (*model.Chapter, error) {
+	ch, err := scanChapter(r.db.QueryRow(
+		`select chapter_index, url, title, content, fetched_at, error from chapters
+		where site=$1 and book_id=$2 and hash_code=$3 and chapter_index=$4`,
+		bk.Site, bk.ID, bk.HashCode, index,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("fail to get chapter: %w", err)
+	}
+
+	return &ch, nil
+}
+
+func (r *PsqlRepo) DeleteChapters(bk *model.Book) error {
+	_, err := r.db.Exec(
+		"delete from chapters where site=$1 and book_id=$2 and hash_code=$3",
+		bk.Site, bk.ID, bk.HashCode,
+	)
+	if err != nil {
+		return fmt.Errorf("fail to delete chapters: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PsqlRepo) SearchChapterContent(keyword string, limit, offset int) ([]model.ContentMatch, error) {
+	rows, err := r.db.Query(
+		fmt.Sprintf(
+			`select %s, chapters.chapter_index, chapters.title, chapters.content
+			from chapters join %s on chapters.site=books.site
+				and chapters.book_id=books.id and chapters.hash_code=books.hash_code
+			where chapters.site=$1 and chapters.error=''
+				and chapters.content like '%%' || $2::text || '%%' escape '\'
+			order by chapters.book_id desc, chapters.hash_code desc, chapters.chapter_index
+			limit $3 offset $4`,
+			QueryField, QueryTable,
+		),
+		r.site, repo.EscapeLike(keyword), limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fail to search chapter content: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]model.ContentMatch, 0)
+	for rows.Next() {
+		var (
+			match   model.ContentMatch
+			content string
+		)
+
+		match.Book, err = rowsToBookWith(rows, &match.ChapterIndex, &match.ChapterTitle, &content)
+		if err != nil {
+			return nil, fmt.Errorf("fail to search chapter content: %w", err)
+		}
+
+		match.Snippet = model.ContentSnippet(content, keyword, model.SnippetRadius)
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
 // error related
 func (r *PsqlRepo) SaveError(bk *model.Book, e error) error {
 	var err error
@@ -351,6 +511,82 @@ func (r *PsqlRepo) SaveError(bk *model.Book, e error) error {
 	return nil
 }
 
+func (r *PsqlRepo) CreateJobRun(run *model.JobRun) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FinishJobRun(run *model.JobRun) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FindJobRuns(operation string, limit, offset int) ([]model.JobRun, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FindJobRun(id int) (*model.JobRun, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) CreateJob(job *model.Job) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) ClaimJob(owner string, lease time.Duration) (*model.Job, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) RenewJobLease(job *model.Job, lease time.Duration) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FinishJob(job *model.Job) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FindJob(id int) (*model.Job, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) EnqueueUpdateBookTasks() (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) EnqueueDownloadBookTasks(includeInProgress bool) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) LeaseBookTask(operation, owner string, lease time.Duration) (*model.BookTask, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) RenewBookTaskLease(task *model.BookTask, lease time.Duration) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) CompleteBookTask(task *model.BookTask) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FailBookTask(task *model.BookTask, retryAfter time.Duration) error {
+	return errors.New("not implemented")
+}
+
+func (r *PsqlRepo) FindBookTasks(operation string, status model.BookTaskStatus, limit, offset int) ([]model.BookTask, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) RequeueBookTask(id int) (*model.BookTask, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) DeleteFinishedBookTasks(before time.Time) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *PsqlRepo) LockOperation(operation string) (func() error, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (r *PsqlRepo) backupBooks(path string) error {
 	_, err := r.db.Exec(
 		fmt.Sprintf(
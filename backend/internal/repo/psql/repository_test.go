@@ -516,89 +516,6 @@ func TestPsqlRepo_FindBooksForDownload(t *testing.T) {
 	}
 }
 
-func TestPsqlRepo_FindBooksByTitleWriter(t *testing.T) {
-	t.Parallel()
-	//TODO: fill in testcase
-	StubPsqlConn()
-	db, err := OpenDatabase("")
-	if err != nil {
-		t.Fatalf("error in open database: %v", err)
-	}
-	site := "bk_tit_wrt/find"
-
-	t.Cleanup(func() {
-		db.Exec("delete from books where site=$1", site)
-		db.Exec("delete from writers where id>0 and name like $1", site+"%")
-		db.Exec("delete from errors where site=$1", site)
-		db.Close()
-	})
-
-	bksDB := stubData(NewRepo(site, db), site)
-
-	tests := []struct {
-		name         string
-		r            repo.Repository
-		title        string
-		writer       string
-		limit        int
-		offset       int
-		expectResult []model.Book
-		expectErr    bool
-	}{
-		{
-			name:         "works",
-			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
-			limit:        10,
-			offset:       0,
-			expectResult: []model.Book{bksDB[3], bksDB[2], bksDB[1], bksDB[0]},
-			expectErr:    false,
-		},
-		{
-			name:         "works with limit",
-			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
-			limit:        1,
-			offset:       0,
-			expectResult: []model.Book{bksDB[3]},
-			expectErr:    false,
-		},
-		{
-			name:         "works with offset",
-			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
-			limit:        1,
-			offset:       1,
-			expectResult: []model.Book{bksDB[2]},
-			expectErr:    false,
-		},
-		{
-			name:         "return all books match either title or writer",
-			r:            NewRepo(site, db),
-			title:        "title 1",
-			writer:       "writer 3",
-			limit:        5,
-			offset:       0,
-			expectResult: []model.Book{bksDB[3], bksDB[0]},
-			expectErr:    false,
-		},
-	}
-
-	for _, test := range tests {
-		test := test
-		t.Run(test.name, func(t *testing.T) {
-			result, err := test.r.FindBooksByTitleWriter(test.title, test.writer, test.limit, test.offset)
-			if (err != nil) != test.expectErr {
-				t.Errorf("got error: %v; want err: %v", err, test.expectErr)
-			}
-			assert.Equal(t, test.expectResult, result)
-		})
-	}
-}
-
 func TestPsqlRepo_FindBooksByRandom(t *testing.T) {
 	t.Parallel()
 
@@ -990,3 +907,72 @@ func TestPsqlRepo_Stats(t *testing.T) {
 		})
 	}
 }
+
+func TestPsqlRepo_Chapters(t *testing.T) {
+	t.Parallel()
+	StubPsqlConn()
+	db, err := OpenDatabase("")
+	if err != nil {
+		t.Fatalf("error in open database: %v", err)
+	}
+	site := "chapter/save"
+
+	t.Cleanup(func() {
+		db.Exec("delete from chapters where site=$1", site)
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+		db.Close()
+	})
+
+	r := NewRepo(site, db)
+	stubData(r, site)
+
+	bk := &model.Book{Site: site, ID: 1}
+	chapters := model.Chapters{
+		{Index: 0, URL: "url 0", Title: "title 0", Content: "content 0 keyword"},
+		{Index: 1, URL: "url 1", Title: "title 1", Error: errors.New("fetch fail")},
+		{Index: 2, URL: "url 2", Title: "title 2", Content: "content 2"},
+	}
+
+	t.Run("save chapters", func(t *testing.T) {
+		assert.NoError(t, r.SaveChapters(bk, chapters))
+	})
+
+	t.Run("find chapters by book", func(t *testing.T) {
+		result, err := r.FindChaptersByBook(bk)
+		assert.NoError(t, err)
+		if assert.Len(t, result, 3) {
+			assert.Equal(t, "content 0 keyword", result[0].Content)
+			assert.EqualError(t, result[1].Error, "fetch fail")
+			assert.False(t, result[2].FetchedAt.IsZero())
+		}
+	})
+
+	t.Run("find chapter", func(t *testing.T) {
+		ch, err := r.FindChapter(bk, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "title 2", ch.Title)
+
+		_, err = r.FindChapter(bk, 3)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("search chapter content", func(t *testing.T) {
+		matches, err := r.SearchChapterContent("keyword", 10, 0)
+		assert.NoError(t, err)
+		if assert.Len(t, matches, 1) {
+			assert.Equal(t, 1, matches[0].Book.ID)
+			assert.Equal(t, 0, matches[0].ChapterIndex)
+			assert.Equal(t, "title 0", matches[0].ChapterTitle)
+		}
+	})
+
+	t.Run("delete chapters", func(t *testing.T) {
+		assert.NoError(t, r.DeleteChapters(bk))
+
+		result, err := r.FindChaptersByBook(bk)
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
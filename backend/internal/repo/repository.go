@@ -2,6 +2,7 @@ package repo
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/htchan/BookSpider/internal/model"
 )
@@ -19,12 +20,14 @@ type Repository interface {
 	FindAllBooks() (<-chan model.Book, error)
 	FindBooksForUpdate() (<-chan model.Book, error)
 	FindBooksForDownload() (<-chan model.Book, error)
-	FindBooksByTitleWriter(title, writer string, limit, offset int) ([]model.Book, error)
+	FindDownloadedBooksWithoutChapters() (<-chan model.Book, error) // books downloaded before chapters were stored
+	SearchBooks(title, writer string, limit, offset int) ([]model.Book, error)
 	FindBooksByRandom(limit int) ([]model.Book, error)
 	UpdateBooksStatus() error
 
 	FindBookGroupByID(id int) (model.BookGroup, error)
 	FindBookGroupByIDHash(id, hashCode int) (model.BookGroup, error)
+	FindWorkByChecksum(checksum, writerChecksum string) (model.BookGroup, error) // books of all sites with the same title and writer
 
 	FindAllBookIDs() ([]int, error)
 
@@ -32,9 +35,43 @@ type Repository interface {
 	SaveWriter(*model.Writer) error // create and update id in writer
 	// the system will not delete / update existing writers
 
+	// chapter related
+	SaveChapters(*model.Book, model.Chapters) error // create / update chapters of the book
+	FindChaptersByBook(*model.Book) (model.Chapters, error)
+	FindChapter(bk *model.Book, index int) (*model.Chapter, error)
+	DeleteChapters(*model.Book) error
+	SearchChapterContent(keyword string, limit, offset int) ([]model.ContentMatch, error)
+
 	// error related
 	SaveError(*model.Book, error) error // create / update / delete errors depends on error content
 
+	// job run related
+	CreateJobRun(*model.JobRun) error // create and update id in job run
+	FinishJobRun(*model.JobRun) error
+	FindJobRuns(operation string, limit, offset int) ([]model.JobRun, error) // runs of all operations if operation is empty
+	FindJobRun(id int) (*model.JobRun, error)
+
+	// job related
+	CreateJob(*model.Job) error                                     // create and update id in job
+	ClaimJob(owner string, lease time.Duration) (*model.Job, error) // lease the oldest queued job or running job with lease expired, return nil if no job is available
+	RenewJobLease(job *model.Job, lease time.Duration) error
+	FinishJob(*model.Job) error // save status set by job.Finish
+	FindJob(id int) (*model.Job, error)
+
+	// book task related
+	EnqueueUpdateBookTasks() (int, error) // enqueue latest hash of all books, return number of tasks enqueued
+	EnqueueDownloadBookTasks(includeInProgress bool) (int, error)
+	LeaseBookTask(operation, owner string, lease time.Duration) (*model.BookTask, error) // return nil if no task is available
+	RenewBookTaskLease(task *model.BookTask, lease time.Duration) error
+	CompleteBookTask(*model.BookTask) error
+	FailBookTask(task *model.BookTask, retryAfter time.Duration) error // save status and error set by task.Fail
+	FindBookTasks(operation string, status model.BookTaskStatus, limit, offset int) ([]model.BookTask, error)
+	RequeueBookTask(id int) (*model.BookTask, error)       // queue dead task again with attempts reset
+	DeleteFinishedBookTasks(before time.Time) (int, error) // delete done tasks updated before the time, return number of tasks deleted
+
+	// lock related
+	LockOperation(operation string) (unlock func() error, err error) // return ErrOperationLocked if the operation of site is locked by others
+
 	// database
 	Backup(path string) error
 	DBStats() sql.DBStats // return empty if repo is not based on db
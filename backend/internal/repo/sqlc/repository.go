@@ -3,6 +3,9 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -46,17 +49,18 @@ func NewRepo(site string, db *sql.DB) *SqlcRepo {
 
 func (r *SqlcRepo) CreateBook(bk *model.Book) error {
 	result, err := r.queries.CreateBookWithZeroHash(r.ctx, sqlc.CreateBookWithZeroHashParams{
-		Site:           bk.Site,
-		ID:             int32(bk.ID),
-		Title:          toSqlString(bk.Title),
-		WriterID:       toSqlInt(bk.Writer.ID),
-		WriterChecksum: toSqlString(bk.Writer.Checksum()),
-		Type:           toSqlString(bk.Type),
-		UpdateDate:     toSqlString(bk.UpdateDate),
-		UpdateChapter:  toSqlString(bk.UpdateChapter),
-		Status:         bk.Status.String(),
-		IsDownloaded:   bk.IsDownloaded,
-		Checksum:       toSqlString(bk.Checksum()),
+		Site:            bk.Site,
+		ID:              int32(bk.ID),
+		Title:           toSqlString(bk.Title),
+		WriterID:        toSqlInt(bk.Writer.ID),
+		WriterChecksum:  toSqlString(bk.Writer.Checksum()),
+		Type:            toSqlString(bk.Type),
+		UpdateDate:      toSqlString(bk.UpdateDate),
+		UpdateChapter:   toSqlString(bk.UpdateChapter),
+		Status:          bk.Status.String(),
+		IsDownloaded:    bk.IsDownloaded,
+		Checksum:        toSqlString(bk.Checksum()),
+		NormalizedTitle: toSqlString(model.NormalizeSearchText(bk.Title)),
 	})
 	if err == nil {
 		bk.HashCode = int(result.HashCode)
@@ -64,18 +68,19 @@ func (r *SqlcRepo) CreateBook(bk *model.Book) error {
 	}
 
 	_, err = r.queries.CreateBookWithHash(r.ctx, sqlc.CreateBookWithHashParams{
-		Site:           bk.Site,
-		ID:             int32(bk.ID),
-		HashCode:       int32(bk.HashCode),
-		Title:          toSqlString(bk.Title),
-		WriterID:       toSqlInt(bk.Writer.ID),
-		WriterChecksum: toSqlString(bk.Writer.Checksum()),
-		Type:           toSqlString(bk.Type),
-		UpdateDate:     toSqlString(bk.UpdateDate),
-		UpdateChapter:  toSqlString(bk.UpdateChapter),
-		Status:         bk.Status.String(),
-		IsDownloaded:   bk.IsDownloaded,
-		Checksum:       toSqlString(bk.Checksum()),
+		Site:            bk.Site,
+		ID:              int32(bk.ID),
+		HashCode:        int32(bk.HashCode),
+		Title:           toSqlString(bk.Title),
+		WriterID:        toSqlInt(bk.Writer.ID),
+		WriterChecksum:  toSqlString(bk.Writer.Checksum()),
+		Type:            toSqlString(bk.Type),
+		UpdateDate:      toSqlString(bk.UpdateDate),
+		UpdateChapter:   toSqlString(bk.UpdateChapter),
+		Status:          bk.Status.String(),
+		IsDownloaded:    bk.IsDownloaded,
+		Checksum:        toSqlString(bk.Checksum()),
+		NormalizedTitle: toSqlString(model.NormalizeSearchText(bk.Title)),
 	})
 	if err != nil {
 		return fmt.Errorf("fail to insert book: %v", err)
@@ -86,18 +91,19 @@ func (r *SqlcRepo) CreateBook(bk *model.Book) error {
 
 func (r *SqlcRepo) UpdateBook(bk *model.Book) error {
 	_, err := r.queries.UpdateBook(r.ctx, sqlc.UpdateBookParams{
-		Site:           bk.Site,
-		ID:             int32(bk.ID),
-		HashCode:       int32(bk.HashCode),
-		Title:          toSqlString(bk.Title),
-		WriterID:       toSqlInt(bk.Writer.ID),
-		WriterChecksum: toSqlString(bk.Writer.Checksum()),
-		Type:           toSqlString(bk.Type),
-		UpdateDate:     toSqlString(bk.UpdateDate),
-		UpdateChapter:  toSqlString(bk.UpdateChapter),
-		Status:         bk.Status.String(),
-		IsDownloaded:   bk.IsDownloaded,
-		Checksum:       toSqlString(bk.Checksum()),
+		Site:            bk.Site,
+		ID:              int32(bk.ID),
+		HashCode:        int32(bk.HashCode),
+		Title:           toSqlString(bk.Title),
+		WriterID:        toSqlInt(bk.Writer.ID),
+		WriterChecksum:  toSqlString(bk.Writer.Checksum()),
+		Type:            toSqlString(bk.Type),
+		UpdateDate:      toSqlString(bk.UpdateDate),
+		UpdateChapter:   toSqlString(bk.UpdateChapter),
+		Status:          bk.Status.String(),
+		IsDownloaded:    bk.IsDownloaded,
+		Checksum:        toSqlString(bk.Checksum()),
+		NormalizedTitle: toSqlString(model.NormalizeSearchText(bk.Title)),
 	})
 	if err != nil {
 		return fmt.Errorf("fail to update book: %w", err)
@@ -320,15 +326,58 @@ func (r *SqlcRepo) FindBooksForDownload() (<-chan model.Book, error) {
 
 	return bkChan, nil
 }
-func (r *SqlcRepo) FindBooksByTitleWriter(title, writer string, limit, offset int) ([]model.Book, error) {
-	results, err := r.queries.ListBooksByTitleWriter(r.ctx, sqlc.ListBooksByTitleWriterParams{
-		Site:    r.site,
-		Column2: toSqlString(fmt.Sprintf("%%%s%%", title)),
-		Column3: toSqlString(fmt.Sprintf("%%%s%%", writer)),
-		Limit:   int32(limit), Offset: int32(offset),
+
+func (r *SqlcRepo) FindDownloadedBooksWithoutChapters() (<-chan model.Book, error) {
+	results, err := r.queries.ListDownloadedBooksWithoutChapters(r.ctx, r.site)
+	if err != nil {
+		return nil, fmt.Errorf("fail to query downloaded books without chapters: %w", err)
+	}
+
+	bkChan := make(chan model.Book)
+
+	go func() {
+		for i := range results {
+			var bkErr error
+			if results[i].Data != "" {
+				bkErr = fmt.Errorf(results[i].Data)
+			}
+
+			bkChan <- model.Book{
+				Site:     results[i].Site,
+				ID:       int(results[i].ID),
+				HashCode: int(results[i].HashCode),
+				Title:    results[i].Title.String,
+				Writer: model.Writer{
+					ID:   int(results[i].WriterID.Int32),
+					Name: results[i].Name,
+				},
+				Type:          results[i].Type.String,
+				UpdateDate:    results[i].UpdateDate.String,
+				UpdateChapter: results[i].UpdateChapter.String,
+				Status:        model.StatusFromString(results[i].Status),
+				IsDownloaded:  results[i].IsDownloaded,
+				Error:         bkErr,
+			}
+		}
+		close(bkChan)
+	}()
+
+	return bkChan, nil
+}
+
+func (r *SqlcRepo) SearchBooks(title, writer string, limit, offset int) ([]model.Book, error) {
+	title, writer = model.NormalizeSearchText(title), model.NormalizeSearchText(writer)
+	results, err := r.queries.SearchBooksByTitleWriter(r.ctx, sqlc.SearchBooksByTitleWriterParams{
+		Site:          r.site,
+		Title:         title,
+		Writer:        writer,
+		TitlePattern:  repo.EscapeLike(title),
+		WriterPattern: repo.EscapeLike(writer),
+		LimitCount:    int32(limit),
+		OffsetCount:   int32(offset),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("fail to query book by site id: %w", err)
+		return nil, fmt.Errorf("fail to search books by title writer: %w", err)
 	}
 
 	bks := make([]model.Book, len(results))
@@ -470,6 +519,43 @@ func (r *SqlcRepo) FindBookGroupByIDHash(id, hashCode int) (model.BookGroup, err
 	return group, nil
 }
 
+func (r *SqlcRepo) FindWorkByChecksum(checksum, writerChecksum string) (model.BookGroup, error) {
+	results, err := r.queries.ListBooksByChecksum(r.ctx, sqlc.ListBooksByChecksumParams{
+		Checksum:       toSqlString(checksum),
+		WriterChecksum: toSqlString(writerChecksum),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to list books by checksum: %w", err)
+	}
+
+	group := make(model.BookGroup, len(results))
+	for i := range results {
+		var bkErr error
+		if results[i].Data != "" {
+			bkErr = fmt.Errorf(results[i].Data)
+		}
+
+		group[i] = model.Book{
+			Site:     results[i].Site,
+			ID:       int(results[i].ID),
+			HashCode: int(results[i].HashCode),
+			Title:    results[i].Title.String,
+			Writer: model.Writer{
+				ID:   int(results[i].WriterID.Int32),
+				Name: results[i].Name,
+			},
+			Type:          results[i].Type.String,
+			UpdateDate:    results[i].UpdateDate.String,
+			UpdateChapter: results[i].UpdateChapter.String,
+			Status:        model.StatusFromString(results[i].Status),
+			IsDownloaded:  results[i].IsDownloaded,
+			Error:         bkErr,
+		}
+	}
+
+	return group, nil
+}
+
 func (r *SqlcRepo) UpdateBooksStatus() error {
 	return r.queries.UpdateBooksStatus(r.ctx, sqlc.UpdateBooksStatusParams{
 		Site:       r.site,
@@ -494,8 +580,9 @@ func (r *SqlcRepo) FindAllBookIDs() ([]int, error) {
 // writer related
 func (r *SqlcRepo) SaveWriter(writer *model.Writer) error {
 	result, err := r.queries.CreateWriter(r.ctx, sqlc.CreateWriterParams{
-		Name:     toSqlString(writer.Name),
-		Checksum: toSqlString(writer.Checksum()),
+		Name:           toSqlString(writer.Name),
+		Checksum:       toSqlString(writer.Checksum()),
+		NormalizedName: toSqlString(model.NormalizeSearchText(writer.Name)),
 	})
 	if err != nil {
 		return fmt.Errorf("fail to save writer: %w", err)
@@ -506,6 +593,150 @@ func (r *SqlcRepo) SaveWriter(writer *model.Writer) error {
 	return nil
 }
 
+// chapter related
+func toModelChapter(ch sqlc.Chapter) model.Chapter {
+	var chErr error
+	if ch.Error != "" {
+		chErr = errors.New(ch.Error)
+	}
+
+	return model.Chapter{
+		Index:     int(ch.ChapterIndex),
+		URL:       ch.Url,
+		Title:     ch.Title,
+		Content:   ch.Content,
+		FetchedAt: ch.FetchedAt.Time,
+		Error:     chErr,
+	}
+}
+
+func (r *SqlcRepo) SaveChapters(bk *model.Book, chapters model.Chapters) error {
+	tx, err := r.db.BeginTx(r.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("fail to begin save chapters transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	queries := r.queries.WithTx(tx)
+	for i := range chapters {
+		if chapters[i].FetchedAt.IsZero() {
+			chapters[i].FetchedAt = time.Now().UTC().Truncate(time.Microsecond)
+		}
+
+		var chErr string
+		if chapters[i].Error != nil {
+			chErr = chapters[i].Error.Error()
+		}
+
+		err := queries.SaveChapter(r.ctx, sqlc.SaveChapterParams{
+			Site:         bk.Site,
+			BookID:       int32(bk.ID),
+			HashCode:     int32(bk.HashCode),
+			ChapterIndex: int32(chapters[i].Index),
+			Url:          chapters[i].URL,
+			Title:        chapters[i].Title,
+			Content:      chapters[i].Content,
+			FetchedAt:    sql.NullTime{Time: chapters[i].FetchedAt, Valid: true},
+			Error:        chErr,
+		})
+		if err != nil {
+			return fmt.Errorf("fail to save chapter %d: %w", chapters[i].Index, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("fail to commit save chapters transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) FindChaptersByBook(bk *model.Book) (model.Chapters, error) {
+	results, err := r.queries.ListChaptersByBook(r.ctx, sqlc.ListChaptersByBookParams{
+		Site:     bk.Site,
+		BookID:   int32(bk.ID),
+		HashCode: int32(bk.HashCode),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to list chapters by book: %w", err)
+	}
+
+	chapters := make(model.Chapters, len(results))
+	for i := range results {
+		chapters[i] = toModelChapter(results[i])
+	}
+
+	return chapters, nil
+}
+
+func (r *SqlcRepo) FindChapter(bk *model.Book, index int) (*model.Chapter, error) {
+	result, err := r.queries.GetChapter(r.ctx, sqlc.GetChapterParams{
+		Site:         bk.Site,
+		BookID:       int32(bk.ID),
+		HashCode:     int32(bk.HashCode),
+		ChapterIndex: int32(index),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to get chapter: %w", err)
+	}
+
+	ch := toModelChapter(result)
+
+	return &ch, nil
+}
+
+func (r *SqlcRepo) DeleteChapters(bk *model.Book) error {
+	err := r.queries.DeleteChaptersByBook(r.ctx, sqlc.DeleteChaptersByBookParams{
+		Site:     bk.Site,
+		BookID:   int32(bk.ID),
+		HashCode: int32(bk.HashCode),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to delete chapters: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) SearchChapterContent(keyword string, limit, offset int) ([]model.ContentMatch, error) {
+	results, err := r.queries.SearchChapterContent(r.ctx, sqlc.SearchChapterContentParams{
+		Site:        r.site,
+		Keyword:     repo.EscapeLike(keyword),
+		LimitCount:  int32(limit),
+		OffsetCount: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to search chapter content: %w", err)
+	}
+
+	matches := make([]model.ContentMatch, len(results))
+	for i := range results {
+		matches[i] = model.ContentMatch{
+			Book: model.Book{
+				Site:     results[i].Site,
+				ID:       int(results[i].BookID),
+				HashCode: int(results[i].HashCode),
+				Title:    results[i].BookTitle.String,
+				Writer: model.Writer{
+					ID:   int(results[i].WriterID.Int32),
+					Name: results[i].WriterName,
+				},
+				Type:          results[i].Type.String,
+				UpdateDate:    results[i].UpdateDate.String,
+				UpdateChapter: results[i].UpdateChapter.String,
+				Status:        model.StatusFromString(results[i].Status),
+				IsDownloaded:  results[i].IsDownloaded,
+			},
+			ChapterIndex: int(results[i].ChapterIndex),
+			ChapterTitle: results[i].ChapterTitle,
+			Snippet:      model.ContentSnippet(results[i].Content, keyword, model.SnippetRadius),
+		}
+	}
+
+	return matches, nil
+}
+
 // error related
 func (r *SqlcRepo) SaveError(bk *model.Book, e error) error {
 	var err error
@@ -530,6 +761,477 @@ func (r *SqlcRepo) SaveError(bk *model.Book, e error) error {
 	return nil
 }
 
+// job run related
+func toModelJobRun(run sqlc.JobRun) (model.JobRun, error) {
+	var stats map[string]int64
+	err := json.Unmarshal(run.Stats, &stats)
+	if err != nil {
+		return model.JobRun{}, fmt.Errorf("fail to parse job run stats: %w", err)
+	}
+
+	var endedAt *time.Time
+	if run.EndedAt.Valid {
+		endedAt = &run.EndedAt.Time
+	}
+
+	return model.JobRun{
+		ID:        int(run.ID),
+		Site:      run.Site,
+		Operation: run.Operation,
+		StartedAt: run.StartedAt,
+		EndedAt:   endedAt,
+		Outcome:   model.JobOutcome(run.Outcome),
+		Error:     run.Error,
+		Stats:     stats,
+	}, nil
+}
+
+func (r *SqlcRepo) CreateJobRun(run *model.JobRun) error {
+	id, err := r.queries.CreateJobRun(r.ctx, sqlc.CreateJobRunParams{
+		Site:      run.Site,
+		Operation: run.Operation,
+		StartedAt: run.StartedAt,
+		Outcome:   string(run.Outcome),
+	})
+	if err != nil {
+		return fmt.Errorf("fail to create job run: %w", err)
+	}
+
+	run.ID = int(id)
+
+	return nil
+}
+
+func (r *SqlcRepo) FinishJobRun(run *model.JobRun) error {
+	stats := run.Stats
+	if stats == nil {
+		stats = map[string]int64{}
+	}
+
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("fail to marshal job run stats: %w", err)
+	}
+
+	var endedAt sql.NullTime
+	if run.EndedAt != nil {
+		endedAt = sql.NullTime{Time: *run.EndedAt, Valid: true}
+	}
+
+	err = r.queries.FinishJobRun(r.ctx, sqlc.FinishJobRunParams{
+		ID:      int32(run.ID),
+		EndedAt: endedAt,
+		Outcome: string(run.Outcome),
+		Error:   run.Error,
+		Stats:   statsJSON,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to finish job run: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) FindJobRuns(operation string, limit, offset int) ([]model.JobRun, error) {
+	results, err := r.queries.ListJobRuns(r.ctx, sqlc.ListJobRunsParams{
+		Site:        r.site,
+		Operation:   operation,
+		LimitCount:  int32(limit),
+		OffsetCount: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to list job runs: %w", err)
+	}
+
+	runs := make([]model.JobRun, len(results))
+	for i := range results {
+		runs[i], err = toModelJobRun(results[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return runs, nil
+}
+
+func (r *SqlcRepo) FindJobRun(id int) (*model.JobRun, error) {
+	result, err := r.queries.GetJobRun(r.ctx, sqlc.GetJobRunParams{
+		Site: r.site,
+		ID:   int32(id),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("fail to get job run: %w", repo.ErrJobRunNotExist)
+	} else if err != nil {
+		return nil, fmt.Errorf("fail to get job run: %w", err)
+	}
+
+	run, err := toModelJobRun(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// job related
+func toModelJob(job sqlc.Job) (model.Job, error) {
+	var stats map[string]int64
+	err := json.Unmarshal(job.Stats, &stats)
+	if err != nil {
+		return model.Job{}, fmt.Errorf("fail to parse job stats: %w", err)
+	}
+
+	var startedAt, endedAt, leaseExpiresAt *time.Time
+	if job.StartedAt.Valid {
+		startedAt = &job.StartedAt.Time
+	}
+	if job.EndedAt.Valid {
+		endedAt = &job.EndedAt.Time
+	}
+	if job.LeaseExpiresAt.Valid {
+		leaseExpiresAt = &job.LeaseExpiresAt.Time
+	}
+
+	return model.Job{
+		ID:        int(job.ID),
+		Site:      job.Site,
+		Operation: job.Operation,
+		BookID:    int(job.BookID.Int32),
+		HashCode:  int(job.HashCode.Int32),
+		Status:    model.JobOutcome(job.Status),
+		Error:     job.Error,
+		Stats:     stats,
+		CreatedAt: job.CreatedAt,
+		StartedAt: startedAt,
+		EndedAt:   endedAt,
+
+		Attempts:       int(job.Attempts),
+		LeaseOwner:     job.LeaseOwner,
+		LeaseExpiresAt: leaseExpiresAt,
+	}, nil
+}
+
+func (r *SqlcRepo) CreateJob(job *model.Job) error {
+	var bookID, hashCode sql.NullInt32
+	if job.IsBookJob() {
+		bookID, hashCode = toSqlInt(job.BookID), toSqlInt(job.HashCode)
+	}
+
+	id, err := r.queries.CreateJob(r.ctx, sqlc.CreateJobParams{
+		Site:      job.Site,
+		Operation: job.Operation,
+		BookID:    bookID,
+		HashCode:  hashCode,
+		Status:    string(job.Status),
+		CreatedAt: job.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to create job: %w", err)
+	}
+
+	job.ID = int(id)
+
+	return nil
+}
+
+func (r *SqlcRepo) ClaimJob(owner string, lease time.Duration) (*model.Job, error) {
+	result, err := r.queries.ClaimJob(r.ctx, sqlc.ClaimJobParams{
+		StartedAt:    sql.NullTime{Time: time.Now().UTC().Truncate(time.Microsecond), Valid: true},
+		LeaseOwner:   owner,
+		LeaseSeconds: int32(lease.Seconds()),
+		Site:         r.site,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("fail to claim job: %w", err)
+	}
+
+	job, err := toModelJob(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *SqlcRepo) FinishJob(job *model.Job) error {
+	stats := job.Stats
+	if stats == nil {
+		stats = map[string]int64{}
+	}
+
+	statsJSON, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("fail to marshal job stats: %w", err)
+	}
+
+	var endedAt sql.NullTime
+	if job.EndedAt != nil {
+		endedAt = sql.NullTime{Time: *job.EndedAt, Valid: true}
+	}
+
+	err = jobLeaseLost(r.queries.FinishJob(r.ctx, sqlc.FinishJobParams{
+		ID:         int32(job.ID),
+		Status:     string(job.Status),
+		Error:      job.Error,
+		Stats:      statsJSON,
+		EndedAt:    endedAt,
+		LeaseOwner: job.LeaseOwner,
+		Attempts:   int32(job.Attempts),
+	}))
+	if err != nil {
+		return fmt.Errorf("fail to finish job: %w", err)
+	}
+
+	return nil
+}
+
+// jobLeaseLost return ErrJobLeaseLost if no job is updated, which means the
+// lease expired and the job was claimed by another worker
+func jobLeaseLost(rowsAffected int64, err error) error {
+	if err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return repo.ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) RenewJobLease(job *model.Job, lease time.Duration) error {
+	err := jobLeaseLost(r.queries.RenewJobLease(r.ctx, sqlc.RenewJobLeaseParams{
+		LeaseSeconds: int32(lease.Seconds()),
+		ID:           int32(job.ID),
+		LeaseOwner:   job.LeaseOwner,
+	}))
+	if err != nil {
+		return fmt.Errorf("fail to renew job lease: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) FindJob(id int) (*model.Job, error) {
+	result, err := r.queries.GetJob(r.ctx, sqlc.GetJobParams{
+		Site: r.site,
+		ID:   int32(id),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("fail to get job: %w", repo.ErrJobNotExist)
+	} else if err != nil {
+		return nil, fmt.Errorf("fail to get job: %w", err)
+	}
+
+	job, err := toModelJob(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func toModelBookTask(task sqlc.BookTask) model.BookTask {
+	var leaseExpiresAt *time.Time
+	if task.LeaseExpiresAt.Valid {
+		leaseExpiresAt = &task.LeaseExpiresAt.Time
+	}
+
+	return model.BookTask{
+		ID:             int(task.ID),
+		Site:           task.Site,
+		Operation:      task.Operation,
+		BookID:         int(task.BookID),
+		HashCode:       int(task.HashCode),
+		Status:         model.BookTaskStatus(task.Status),
+		Attempts:       int(task.Attempts),
+		AvailableAt:    task.AvailableAt,
+		LeaseOwner:     task.LeaseOwner,
+		LeaseExpiresAt: leaseExpiresAt,
+		Error:          task.Error,
+		UpdatedAt:      task.UpdatedAt,
+	}
+}
+
+// leaseLost return ErrBookTaskLeaseLost if no task is updated, which means
+// the lease expired and the task was taken over by another worker
+func leaseLost(rowsAffected int64, err error) error {
+	if err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return repo.ErrBookTaskLeaseLost
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) EnqueueUpdateBookTasks() (int, error) {
+	count, err := r.queries.EnqueueUpdateBookTasks(r.ctx, r.site)
+	if err != nil {
+		return 0, fmt.Errorf("fail to enqueue update book tasks: %w", err)
+	}
+
+	return int(count), nil
+}
+
+func (r *SqlcRepo) EnqueueDownloadBookTasks(includeInProgress bool) (int, error) {
+	count, err := r.queries.EnqueueDownloadBookTasks(r.ctx, sqlc.EnqueueDownloadBookTasksParams{
+		Site:              r.site,
+		IncludeInProgress: includeInProgress,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("fail to enqueue download book tasks: %w", err)
+	}
+
+	return int(count), nil
+}
+
+func (r *SqlcRepo) LeaseBookTask(operation, owner string, lease time.Duration) (*model.BookTask, error) {
+	result, err := r.queries.LeaseBookTask(r.ctx, sqlc.LeaseBookTaskParams{
+		LeaseOwner:   owner,
+		LeaseSeconds: int32(lease.Seconds()),
+		Site:         r.site,
+		Operation:    operation,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("fail to lease book task: %w", err)
+	}
+
+	task := toModelBookTask(result)
+
+	return &task, nil
+}
+
+func (r *SqlcRepo) RenewBookTaskLease(task *model.BookTask, lease time.Duration) error {
+	err := leaseLost(r.queries.RenewBookTaskLease(r.ctx, sqlc.RenewBookTaskLeaseParams{
+		LeaseSeconds: int32(lease.Seconds()),
+		ID:           int32(task.ID),
+		LeaseOwner:   task.LeaseOwner,
+	}))
+	if err != nil {
+		return fmt.Errorf("fail to renew book task lease: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) CompleteBookTask(task *model.BookTask) error {
+	err := leaseLost(r.queries.CompleteBookTask(r.ctx, sqlc.CompleteBookTaskParams{
+		ID:         int32(task.ID),
+		LeaseOwner: task.LeaseOwner,
+	}))
+	if err != nil {
+		return fmt.Errorf("fail to complete book task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) FailBookTask(task *model.BookTask, retryAfter time.Duration) error {
+	err := leaseLost(r.queries.FailBookTask(r.ctx, sqlc.FailBookTaskParams{
+		Status:       string(task.Status),
+		Attempts:     int32(task.Attempts),
+		Error:        task.Error,
+		RetrySeconds: int32(retryAfter.Seconds()),
+		ID:           int32(task.ID),
+		LeaseOwner:   task.LeaseOwner,
+	}))
+	if err != nil {
+		return fmt.Errorf("fail to fail book task: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SqlcRepo) FindBookTasks(operation string, status model.BookTaskStatus, limit, offset int) ([]model.BookTask, error) {
+	results, err := r.queries.ListBookTasks(r.ctx, sqlc.ListBookTasksParams{
+		Site:        r.site,
+		Operation:   operation,
+		Status:      string(status),
+		LimitCount:  int32(limit),
+		OffsetCount: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to list book tasks: %w", err)
+	}
+
+	tasks := make([]model.BookTask, len(results))
+	for i := range results {
+		tasks[i] = toModelBookTask(results[i])
+	}
+
+	return tasks, nil
+}
+
+func (r *SqlcRepo) RequeueBookTask(id int) (*model.BookTask, error) {
+	result, err := r.queries.RequeueBookTask(r.ctx, sqlc.RequeueBookTaskParams{
+		Site: r.site,
+		ID:   int32(id),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("fail to requeue book task: %w", repo.ErrBookTaskNotExist)
+	} else if err != nil {
+		return nil, fmt.Errorf("fail to requeue book task: %w", err)
+	}
+
+	task := toModelBookTask(result)
+
+	return &task, nil
+}
+
+func (r *SqlcRepo) DeleteFinishedBookTasks(before time.Time) (int, error) {
+	count, err := r.queries.DeleteFinishedBookTasks(r.ctx, sqlc.DeleteFinishedBookTasksParams{
+		Site:      r.site,
+		UpdatedAt: before,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("fail to delete finished book tasks: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// LockOperation take the advisory lock of the operation on site, so the
+// operation is run by one worker at a time across all pods. the lock belongs
+// to a dedicated connection, it is released by unlock or by database once the
+// connection is gone
+func (r *SqlcRepo) LockOperation(operation string) (func() error, error) {
+	conn, err := r.db.Conn(r.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fail to lock operation: %w", err)
+	}
+
+	queries := sqlc.New(conn)
+	params := sqlc.TryLockOperationParams{Site: r.site, Operation: operation}
+
+	locked, err := queries.TryLockOperation(r.ctx, params)
+	if err == nil && !locked {
+		err = repo.ErrOperationLocked
+	}
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("fail to lock operation: %w", err)
+	}
+
+	return func() error {
+		defer conn.Close()
+
+		_, err := queries.UnlockOperation(r.ctx, sqlc.UnlockOperationParams(params))
+		if err != nil {
+			// discard the connection instead of putting it back to pool, so
+			// the lock is released with the session
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+
+			return fmt.Errorf("fail to unlock operation: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
 func (r *SqlcRepo) backupBooks(path string) error {
 	_, err := r.db.Exec(
 		fmt.Sprintf(
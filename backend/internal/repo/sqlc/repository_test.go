@@ -1,10 +1,12 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/htchan/BookSpider/internal/model"
@@ -496,21 +498,59 @@ func TestSqlcRepo_FindBooksForDownload(t *testing.T) {
 	}
 }
 
-func TestSqlcRepo_FindBooksByTitleWriter(t *testing.T) {
+func TestSqlcRepo_FindDownloadedBooksWithoutChapters(t *testing.T) {
 	t.Parallel()
-	//TODO: fill in testcase
 	StubPsqlConn()
 	db := testDB
-	site := "bk_tit_wrt/find"
+	site := "down_bk/no_ch"
 
 	t.Cleanup(func() {
 		db.Exec("delete from books where site=$1", site)
 		db.Exec("delete from writers where id>0 and name like $1", site+"%")
 		db.Exec("delete from errors where site=$1", site)
+		db.Exec("delete from chapters where site=$1", site)
+	})
+
+	r := NewRepo(site, db)
+	bksDB := stubData(r, site)
+	err := r.SaveChapters(&bksDB[0], model.Chapters{{Index: 0, Title: "title 0", Content: "content 0"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := r.FindDownloadedBooksWithoutChapters()
+	assert.NoError(t, err)
+
+	var bks []model.Book
+	for bk := range result {
+		bks = append(bks, bk)
+	}
+
+	assert.Equal(t, []model.Book{bksDB[1]}, bks)
+}
+
+func TestSqlcRepo_SearchBooks(t *testing.T) {
+	t.Parallel()
+	StubPsqlConn()
+	db := testDB
+	site := "bk_search"
 
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
 	})
 
-	bksDB := stubData(NewRepo(site, db), site)
+	r := NewRepo(site, db)
+	bksDB := stubData(r, site)
+	traditionalBk := model.Book{
+		Site: site, ID: 5, HashCode: 0,
+		Title: "測試書名", Writer: model.Writer{Name: site + " 作者"}, Type: "type 5",
+		UpdateDate: "date 5", UpdateChapter: "chapter 5",
+		Status: model.StatusEnd, IsDownloaded: false, Error: nil,
+	}
+	r.SaveWriter(&traditionalBk.Writer)
+	r.CreateBook(&traditionalBk)
 
 	tests := []struct {
 		name         string
@@ -523,43 +563,53 @@ func TestSqlcRepo_FindBooksByTitleWriter(t *testing.T) {
 		expectErr    bool
 	}{
 		{
-			name:         "works",
+			name:         "match traditional title by simplified keyword",
 			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
+			title:        "测试",
+			writer:       "",
 			limit:        10,
 			offset:       0,
-			expectResult: []model.Book{bksDB[3], bksDB[2], bksDB[1], bksDB[0]},
+			expectResult: []model.Book{traditionalBk},
 			expectErr:    false,
 		},
 		{
-			name:         "works with limit",
+			name:         "match traditional writer by simplified keyword",
 			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
-			limit:        1,
+			title:        "",
+			writer:       "作者",
+			limit:        10,
 			offset:       0,
-			expectResult: []model.Book{bksDB[3]},
+			expectResult: []model.Book{traditionalBk},
 			expectErr:    false,
 		},
 		{
-			name:         "works with offset",
+			name:         "match title ignoring spaces and cases",
 			r:            NewRepo(site, db),
-			title:        "title",
-			writer:       "writer",
-			limit:        1,
-			offset:       1,
-			expectResult: []model.Book{bksDB[2]},
+			title:        "TITLE 1",
+			writer:       "",
+			limit:        10,
+			offset:       0,
+			expectResult: []model.Book{bksDB[0]},
 			expectErr:    false,
 		},
 		{
-			name:         "return all books match either title or writer",
+			name:         "match wildcards in keyword literally",
 			r:            NewRepo(site, db),
-			title:        "title 1",
-			writer:       "writer 3",
-			limit:        5,
+			title:        "title_%",
+			writer:       "",
+			limit:        10,
 			offset:       0,
-			expectResult: []model.Book{bksDB[3], bksDB[0]},
+			expectResult: []model.Book{},
+			expectErr:    false,
+		},
+		{
+			name:         "return empty result if nothing match",
+			r:            NewRepo(site, db),
+			title:        "not exist",
+			writer:       "",
+			limit:        10,
+			offset:       0,
+			expectResult: []model.Book{},
 			expectErr:    false,
 		},
 	}
@@ -567,7 +617,7 @@ func TestSqlcRepo_FindBooksByTitleWriter(t *testing.T) {
 	for _, test := range tests {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			result, err := test.r.FindBooksByTitleWriter(test.title, test.writer, test.limit, test.offset)
+			result, err := test.r.SearchBooks(test.title, test.writer, test.limit, test.offset)
 			if (err != nil) != test.expectErr {
 				t.Errorf("got error: %v; want err: %v", err, test.expectErr)
 			}
@@ -622,6 +672,66 @@ func TestSqlcRepo_FindBooksByRandom(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_FindWorkByChecksum(t *testing.T) {
+	t.Parallel()
+	StubPsqlConn()
+	db := testDB
+	site, otherSite := "bk_work_1", "bk_work_2"
+
+	t.Cleanup(func() {
+		db.Exec("delete from books where site=$1 or site=$2", site, otherSite)
+		db.Exec("delete from writers where id>0 and name like $1", "bk_work%")
+		db.Exec("delete from errors where site=$1 or site=$2", site, otherSite)
+	})
+
+	bks := []model.Book{
+		{
+			Site: site, ID: 1, HashCode: 0,
+			Title: "bk_work title", Writer: model.Writer{Name: "bk_work writer"}, Type: "type 1",
+			UpdateDate: "date 1", UpdateChapter: "chapter 1",
+			Status: model.StatusEnd, IsDownloaded: true, Error: nil,
+		},
+		{
+			Site: otherSite, ID: 5, HashCode: 100,
+			Title: "bk_work title", Writer: model.Writer{Name: "bk_work writer"}, Type: "type 5",
+			UpdateDate: "date 5", UpdateChapter: "chapter 5",
+			Status: model.StatusInProgress, IsDownloaded: false, Error: nil,
+		},
+		{
+			Site: otherSite, ID: 6, HashCode: 0,
+			Title: "bk_work title", Writer: model.Writer{Name: "bk_work other writer"}, Type: "type 6",
+			UpdateDate: "date 6", UpdateChapter: "chapter 6",
+			Status: model.StatusInProgress, IsDownloaded: false, Error: nil,
+		},
+	}
+	for i := range bks {
+		r := NewRepo(bks[i].Site, db)
+		r.SaveWriter(&bks[i].Writer)
+		r.CreateBook(&bks[i])
+	}
+
+	t.Run("find books of same work in all sites", func(t *testing.T) {
+		r := NewRepo(site, db)
+		result, err := r.FindWorkByChecksum(bks[0].Checksum(), bks[0].Writer.Checksum())
+		assert.NoError(t, err)
+		assert.Equal(t, model.BookGroup{bks[0], bks[1]}, result)
+	})
+
+	t.Run("return empty group if no book match", func(t *testing.T) {
+		r := NewRepo(site, db)
+		result, err := r.FindWorkByChecksum("not exist", "not exist")
+		assert.NoError(t, err)
+		assert.Equal(t, model.BookGroup{}, result)
+	})
+
+	t.Run("find book group of other site by id", func(t *testing.T) {
+		r := NewRepo(otherSite, db)
+		result, err := r.FindBookGroupByID(5)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, model.BookGroup{bks[0], bks[1]}, result)
+	})
+}
+
 func TestSqlcRepo_UpdateBooksStatus(t *testing.T) {
 	t.Parallel()
 
@@ -780,6 +890,246 @@ func TestSqlcRepo_FindAllBookIDs(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_SaveChapters(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "ch/save"
+
+	t.Cleanup(func() {
+		db.Exec("delete from chapters where site=$1", site)
+	})
+
+	fetchedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		r         repo.Repository
+		bk        *model.Book
+		chapters  model.Chapters
+		wantError error
+		want      model.Chapters
+	}{
+		{
+			name: "create chapters",
+			r:    NewRepo(site, db),
+			bk:   &model.Book{Site: site, ID: 1, HashCode: 100},
+			chapters: model.Chapters{
+				{Index: 0, URL: "url 0", Title: "title 0", Content: "content " + model.CONTENT_SEP, FetchedAt: fetchedAt},
+				{Index: 1, URL: "url 1", FetchedAt: fetchedAt, Error: errors.New("chapter error")},
+			},
+			wantError: nil,
+			want: model.Chapters{
+				{Index: 0, URL: "url 0", Title: "title 0", Content: "content " + model.CONTENT_SEP, FetchedAt: fetchedAt},
+				{Index: 1, URL: "url 1", FetchedAt: fetchedAt, Error: errors.New("chapter error")},
+			},
+		},
+		{
+			name: "update existing chapters",
+			r:    NewRepo(site, db),
+			bk:   &model.Book{Site: site, ID: 2, HashCode: 100},
+			chapters: model.Chapters{
+				{Index: 0, URL: "url 0", Title: "title 0", FetchedAt: fetchedAt, Error: errors.New("chapter error")},
+				{Index: 0, URL: "url 0", Title: "title 0", Content: "content 0", FetchedAt: fetchedAt},
+			},
+			wantError: nil,
+			want: model.Chapters{
+				{Index: 0, URL: "url 0", Title: "title 0", Content: "content 0", FetchedAt: fetchedAt},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.r.SaveChapters(test.bk, test.chapters)
+			assert.ErrorIs(t, err, test.wantError)
+
+			got, err := test.r.FindChaptersByBook(test.bk)
+			assert.NoError(t, err)
+			for i := range got {
+				got[i].FetchedAt = got[i].FetchedAt.UTC()
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSqlcRepo_FindChapter(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "ch/find"
+
+	t.Cleanup(func() {
+		db.Exec("delete from chapters where site=$1", site)
+	})
+
+	fetchedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	bk := &model.Book{Site: site, ID: 1, HashCode: 100}
+	r := NewRepo(site, db)
+	r.SaveChapters(bk, model.Chapters{
+		{Index: 0, URL: "url 0", Title: "title 0", Content: "content 0", FetchedAt: fetchedAt},
+	})
+
+	tests := []struct {
+		name      string
+		r         repo.Repository
+		bk        *model.Book
+		index     int
+		wantError error
+		want      *model.Chapter
+	}{
+		{
+			name:      "happy flow",
+			r:         r,
+			bk:        bk,
+			index:     0,
+			wantError: nil,
+			want:      &model.Chapter{Index: 0, URL: "url 0", Title: "title 0", Content: "content 0", FetchedAt: fetchedAt},
+		},
+		{
+			name:      "chapter not exist",
+			r:         r,
+			bk:        bk,
+			index:     1,
+			wantError: sql.ErrNoRows,
+			want:      nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := test.r.FindChapter(test.bk, test.index)
+			if got != nil {
+				got.FetchedAt = got.FetchedAt.UTC()
+			}
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestSqlcRepo_DeleteChapters(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "ch/delete"
+
+	t.Cleanup(func() {
+		db.Exec("delete from chapters where site=$1", site)
+	})
+
+	r := NewRepo(site, db)
+	bk := &model.Book{Site: site, ID: 1, HashCode: 100}
+	otherBk := &model.Book{Site: site, ID: 1, HashCode: 200}
+	r.SaveChapters(bk, model.Chapters{{Index: 0, URL: "url 0"}})
+	r.SaveChapters(otherBk, model.Chapters{{Index: 0, URL: "url 0"}})
+
+	err := r.DeleteChapters(bk)
+	assert.NoError(t, err)
+
+	got, err := r.FindChaptersByBook(bk)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = r.FindChaptersByBook(otherBk)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestSqlcRepo_SearchChapterContent(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "ch/search"
+
+	t.Cleanup(func() {
+		db.Exec("delete from chapters where site=$1", site)
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+	})
+
+	r := NewRepo(site, db)
+	bksDB := stubData(r, site)
+	r.SaveChapters(&bksDB[0], model.Chapters{
+		{Index: 0, URL: "url 0", Title: "chapter 0", Content: "nothing here"},
+		{Index: 1, URL: "url 1", Title: "chapter 1", Content: "the hidden keyword is here"},
+		{Index: 2, URL: "url 2", Title: "chapter 2", Content: "keyword in failed chapter", Error: errors.New("fail")},
+	})
+	r.SaveChapters(&bksDB[1], model.Chapters{
+		{Index: 0, URL: "url 0", Title: "chapter 0", Content: "another keyword"},
+	})
+
+	tests := []struct {
+		name         string
+		keyword      string
+		limit        int
+		offset       int
+		expectResult []model.ContentMatch
+		expectErr    bool
+	}{
+		{
+			name:    "return matched chapters with snippet",
+			keyword: "keyword",
+			limit:   10,
+			offset:  0,
+			expectResult: []model.ContentMatch{
+				{Book: bksDB[1], ChapterIndex: 0, ChapterTitle: "chapter 0", Snippet: "another <em>keyword</em>"},
+				{Book: bksDB[0], ChapterIndex: 1, ChapterTitle: "chapter 1", Snippet: "the hidden <em>keyword</em> is here"},
+			},
+			expectErr: false,
+		},
+		{
+			name:    "works with offset",
+			keyword: "keyword",
+			limit:   1,
+			offset:  1,
+			expectResult: []model.ContentMatch{
+				{Book: bksDB[0], ChapterIndex: 1, ChapterTitle: "chapter 1", Snippet: "the hidden <em>keyword</em> is here"},
+			},
+			expectErr: false,
+		},
+		{
+			name:         "match wildcards in keyword literally",
+			keyword:      "key%",
+			limit:        10,
+			offset:       0,
+			expectResult: []model.ContentMatch{},
+			expectErr:    false,
+		},
+		{
+			name:         "return empty result if nothing match",
+			keyword:      "not exist",
+			limit:        10,
+			offset:       0,
+			expectResult: []model.ContentMatch{},
+			expectErr:    false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			result, err := r.SearchChapterContent(test.keyword, test.limit, test.offset)
+			if (err != nil) != test.expectErr {
+				t.Errorf("got error: %v; want err: %v", err, test.expectErr)
+			}
+			assert.Equal(t, test.expectResult, result)
+		})
+	}
+}
+
 func TestSqlcRepo_SaveWriter(t *testing.T) {
 	t.Parallel()
 	StubPsqlConn()
@@ -902,6 +1252,329 @@ func TestSqlcRepo_SaveError(t *testing.T) {
 	}
 }
 
+func TestSqlcRepo_JobRuns(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "job/runs"
+
+	t.Cleanup(func() {
+		db.Exec("delete from job_runs where site=$1", site)
+	})
+
+	startedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	endedAt := startedAt.Add(time.Hour)
+	r := NewRepo(site, db)
+
+	updateRun := model.NewJobRun(site, model.JobOperationUpdate, startedAt)
+	assert.NoError(t, r.CreateJobRun(updateRun))
+	assert.Greater(t, updateRun.ID, 0)
+
+	updateRun.Finish(endedAt, nil, map[string]int64{"total": 10, "fail": 1})
+	assert.NoError(t, r.FinishJobRun(updateRun))
+
+	exploreRun := model.NewJobRun(site, model.JobOperationExplore, endedAt)
+	assert.NoError(t, r.CreateJobRun(exploreRun))
+
+	normalize := func(run *model.JobRun) {
+		run.StartedAt = run.StartedAt.UTC()
+		if run.EndedAt != nil {
+			ended := run.EndedAt.UTC()
+			run.EndedAt = &ended
+		}
+	}
+
+	t.Run("find runs of all operations", func(t *testing.T) {
+		runs, err := r.FindJobRuns("", 10, 0)
+		assert.NoError(t, err)
+		for i := range runs {
+			normalize(&runs[i])
+		}
+		assert.Equal(t, []model.JobRun{
+			{ID: exploreRun.ID, Site: site, Operation: model.JobOperationExplore, StartedAt: endedAt, Outcome: model.JobOutcomeRunning, Stats: map[string]int64{}},
+			*updateRun,
+		}, runs)
+	})
+
+	t.Run("find runs of given operation", func(t *testing.T) {
+		runs, err := r.FindJobRuns(model.JobOperationUpdate, 10, 0)
+		assert.NoError(t, err)
+		for i := range runs {
+			normalize(&runs[i])
+		}
+		assert.Equal(t, []model.JobRun{*updateRun}, runs)
+	})
+
+	t.Run("find run by id", func(t *testing.T) {
+		run, err := r.FindJobRun(updateRun.ID)
+		assert.NoError(t, err)
+		normalize(run)
+		assert.Equal(t, updateRun, run)
+	})
+
+	t.Run("run not exist", func(t *testing.T) {
+		run, err := r.FindJobRun(-1)
+		assert.ErrorIs(t, err, repo.ErrJobRunNotExist)
+		assert.Nil(t, run)
+	})
+}
+
+func TestSqlcRepo_Jobs(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "job/queue"
+
+	t.Cleanup(func() {
+		db.Exec("delete from jobs where site=$1", site)
+	})
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := NewRepo(site, db)
+
+	siteJob := model.NewJob(site, model.JobOperationUpdate, nil, createdAt)
+	assert.NoError(t, r.CreateJob(siteJob))
+	assert.Greater(t, siteJob.ID, 0)
+
+	bookJob := model.NewJob(site, model.JobOperationProcess, &model.Book{Site: site, ID: 1, HashCode: 100}, createdAt)
+	assert.NoError(t, r.CreateJob(bookJob))
+
+	t.Run("claim jobs in created order", func(t *testing.T) {
+		job, err := r.ClaimJob("worker-1", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, siteJob.ID, job.ID)
+		assert.Equal(t, model.JobOutcomeRunning, job.Status)
+		assert.NotNil(t, job.StartedAt)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, "worker-1", job.LeaseOwner)
+		assert.NotNil(t, job.LeaseExpiresAt)
+
+		assert.NoError(t, r.RenewJobLease(job, time.Minute))
+		job.Finish(createdAt.Add(time.Hour), nil, map[string]int64{"total": 1})
+		assert.NoError(t, r.FinishJob(job))
+		assert.ErrorIs(t, r.FinishJob(job), repo.ErrJobLeaseLost)
+
+		job, err = r.ClaimJob("worker-1", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, bookJob.ID, job.ID)
+		assert.Equal(t, 1, job.BookID)
+		assert.Equal(t, 100, job.HashCode)
+		bookJob = job
+
+		job, err = r.ClaimJob("worker-1", time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+	})
+
+	t.Run("claim running job with expired lease", func(t *testing.T) {
+		job, err := r.ClaimJob("worker-2", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, bookJob.ID, job.ID)
+		assert.Equal(t, 2, job.Attempts)
+		assert.Equal(t, "worker-2", job.LeaseOwner)
+
+		assert.ErrorIs(t, r.RenewJobLease(bookJob, time.Minute), repo.ErrJobLeaseLost)
+		assert.ErrorIs(t, r.FinishJob(bookJob), repo.ErrJobLeaseLost)
+
+		job.Finish(createdAt.Add(time.Hour), context.Canceled, nil)
+		assert.NoError(t, r.FinishJob(job))
+
+		// attempt of interrupted job is not counted
+		job, err = r.ClaimJob("worker-2", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, bookJob.ID, job.ID)
+		assert.Equal(t, 2, job.Attempts)
+	})
+
+	t.Run("find finished job", func(t *testing.T) {
+		job, err := r.FindJob(siteJob.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.JobOutcomeSuccess, job.Status)
+		assert.Equal(t, map[string]int64{"total": 1}, job.Stats)
+		assert.Equal(t, createdAt.Add(time.Hour), job.EndedAt.UTC())
+	})
+
+	t.Run("job not exist", func(t *testing.T) {
+		job, err := r.FindJob(-1)
+		assert.ErrorIs(t, err, repo.ErrJobNotExist)
+		assert.Nil(t, job)
+	})
+}
+
+func TestSqlcRepo_BookTasks(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "book/tasks"
+
+	t.Cleanup(func() {
+		db.Exec("delete from book_tasks where site=$1", site)
+		db.Exec("delete from books where site=$1", site)
+		db.Exec("delete from writers where id>0 and name like $1", site+"%")
+		db.Exec("delete from errors where site=$1", site)
+	})
+
+	r := NewRepo(site, db)
+	stubData(r, site)
+
+	t.Run("enqueue books not downloaded once", func(t *testing.T) {
+		count, err := r.EnqueueDownloadBookTasks(false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		count, err = r.EnqueueDownloadBookTasks(false)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		count, err = r.EnqueueDownloadBookTasks(true)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	var endTask, inProgressTask *model.BookTask
+
+	t.Run("lease tasks in enqueued order", func(t *testing.T) {
+		var err error
+		endTask, err = r.LeaseBookTask(model.JobOperationDownload, "worker-1", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, endTask.BookID)
+		assert.Equal(t, 100, endTask.HashCode)
+		assert.Equal(t, model.BookTaskStatusLeased, endTask.Status)
+		assert.Equal(t, 1, endTask.Attempts)
+		assert.Equal(t, "worker-1", endTask.LeaseOwner)
+		assert.NotNil(t, endTask.LeaseExpiresAt)
+
+		inProgressTask, err = r.LeaseBookTask(model.JobOperationDownload, "worker-1", 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, inProgressTask.BookID)
+
+		task, err := r.LeaseBookTask(model.JobOperationUpdate, "worker-1", time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, task)
+	})
+
+	t.Run("take over task with expired lease", func(t *testing.T) {
+		task, err := r.LeaseBookTask(model.JobOperationDownload, "worker-2", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, inProgressTask.ID, task.ID)
+		assert.Equal(t, 2, task.Attempts)
+		assert.Equal(t, "worker-2", task.LeaseOwner)
+
+		assert.ErrorIs(t, r.RenewBookTaskLease(inProgressTask, time.Minute), repo.ErrBookTaskLeaseLost)
+		inProgressTask = task
+
+		task, err = r.LeaseBookTask(model.JobOperationDownload, "worker-2", time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, task)
+	})
+
+	t.Run("not count attempt of interrupted task", func(t *testing.T) {
+		inProgressTask.Fail(fmt.Errorf("download interrupted: %w", context.Canceled), 2)
+		assert.NoError(t, r.FailBookTask(inProgressTask, 0))
+
+		task, err := r.LeaseBookTask(model.JobOperationDownload, "worker-2", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, inProgressTask.ID, task.ID)
+		assert.Equal(t, 2, task.Attempts)
+		inProgressTask = task
+	})
+
+	t.Run("complete task", func(t *testing.T) {
+		assert.NoError(t, r.RenewBookTaskLease(endTask, time.Minute))
+		assert.NoError(t, r.CompleteBookTask(endTask))
+		assert.ErrorIs(t, r.CompleteBookTask(endTask), repo.ErrBookTaskLeaseLost)
+	})
+
+	t.Run("dead letter task", func(t *testing.T) {
+		inProgressTask.Fail(errors.New("some error"), 2)
+		assert.NoError(t, r.FailBookTask(inProgressTask, time.Minute))
+
+		tasks, err := r.FindBookTasks(model.JobOperationDownload, model.BookTaskStatusDead, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, inProgressTask.ID, tasks[0].ID)
+		assert.Equal(t, "some error", tasks[0].Error)
+		assert.Equal(t, "", tasks[0].LeaseOwner)
+	})
+
+	t.Run("enqueue completed task again without reviving dead letter task", func(t *testing.T) {
+		count, err := r.EnqueueDownloadBookTasks(true)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		tasks, err := r.FindBookTasks(model.JobOperationDownload, model.BookTaskStatusDead, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("requeue dead letter task", func(t *testing.T) {
+		task, err := r.RequeueBookTask(inProgressTask.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.BookTaskStatusQueued, task.Status)
+		assert.Equal(t, 0, task.Attempts)
+
+		task, err = r.RequeueBookTask(inProgressTask.ID)
+		assert.ErrorIs(t, err, repo.ErrBookTaskNotExist)
+		assert.Nil(t, task)
+
+		tasks, err := r.FindBookTasks("", model.BookTaskStatusQueued, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+	})
+
+	t.Run("delete finished tasks", func(t *testing.T) {
+		task, err := r.LeaseBookTask(model.JobOperationDownload, "worker-1", time.Minute)
+		assert.NoError(t, err)
+		assert.NoError(t, r.CompleteBookTask(task))
+
+		count, err := r.DeleteFinishedBookTasks(time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		count, err = r.DeleteFinishedBookTasks(time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		tasks, err := r.FindBookTasks("", "", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, model.BookTaskStatusQueued, tasks[0].Status)
+	})
+}
+
+func TestSqlcRepo_LockOperation(t *testing.T) {
+	t.Parallel()
+
+	StubPsqlConn()
+	db := testDB
+	site := "lock/operation"
+
+	r, other := NewRepo(site, db), NewRepo(site, db)
+
+	unlock, err := r.LockOperation(model.JobOperationExplore)
+	assert.NoError(t, err)
+
+	_, err = other.LockOperation(model.JobOperationExplore)
+	assert.ErrorIs(t, err, repo.ErrOperationLocked)
+
+	otherUnlock, err := other.LockOperation(model.JobOperationValidate)
+	assert.NoError(t, err)
+	assert.NoError(t, otherUnlock())
+
+	otherSiteUnlock, err := NewRepo("other/site", db).LockOperation(model.JobOperationExplore)
+	assert.NoError(t, err)
+	assert.NoError(t, otherSiteUnlock())
+
+	assert.NoError(t, unlock())
+
+	otherUnlock, err = other.LockOperation(model.JobOperationExplore)
+	assert.NoError(t, err)
+	assert.NoError(t, otherUnlock())
+}
+
 func TestSqlcRepo_Backup(t *testing.T) {
 	t.Parallel()
 	StubPsqlConn()
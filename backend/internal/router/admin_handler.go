@@ -0,0 +1,97 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	"github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+const defaultForceReason = "forced by admin api"
+
+// @Summary		List circuit breakers
+// @description	list circuit breaker state of all sites
+// @Tags			book-spider-admin
+// @Produce		json
+// @Success		200	{object}	circuitBreakersResp
+// @Failure		401	{object}	errResp
+// @Router			/admin/circuit-breakers [get]
+func CircuitBreakersAdminHandler(services map[string]service.Service) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		states := make(map[string]circuitbreaker.State, len(services))
+		for name, serv := range services {
+			states[name] = serv.CircuitBreakerState()
+		}
+		json.NewEncoder(res).Encode(circuitBreakersResp{states})
+	}
+}
+
+// @Summary		Get circuit breaker
+// @description	get circuit breaker state of site
+// @Tags			book-spider-admin
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Success		200			{object}	circuitbreaker.State
+// @Failure		401			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/admin/sites/{siteName}/circuit-breaker [get]
+func CircuitBreakerAdminHandler(res http.ResponseWriter, req *http.Request) {
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	json.NewEncoder(res).Encode(serv.CircuitBreakerState())
+}
+
+// @Summary		Force open circuit breaker
+// @description	open circuit breaker of site until it is closed by admin api
+// @Tags			book-spider-admin
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			reason		query		string	false	"reason of opening circuit"
+// @Success		200			{object}	circuitbreaker.State
+// @Failure		401			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/admin/sites/{siteName}/circuit-breaker/open [post]
+func CircuitBreakerOpenAdminHandler(res http.ResponseWriter, req *http.Request) {
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	reason := forceReason(req)
+
+	serv.ForceOpenCircuitBreaker(reason)
+	zerolog.Ctx(req.Context()).Info().
+		Str("site", serv.Name()).
+		Str("reason", reason).
+		Msg("circuit breaker force opened")
+
+	json.NewEncoder(res).Encode(serv.CircuitBreakerState())
+}
+
+// @Summary		Force close circuit breaker
+// @description	close circuit breaker of site and resume automatic control
+// @Tags			book-spider-admin
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			reason		query		string	false	"reason of closing circuit"
+// @Success		200			{object}	circuitbreaker.State
+// @Failure		401			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/admin/sites/{siteName}/circuit-breaker/close [post]
+func CircuitBreakerCloseAdminHandler(res http.ResponseWriter, req *http.Request) {
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	reason := forceReason(req)
+
+	serv.ForceCloseCircuitBreaker(reason)
+	zerolog.Ctx(req.Context()).Info().
+		Str("site", serv.Name()).
+		Str("reason", reason).
+		Msg("circuit breaker force closed")
+
+	json.NewEncoder(res).Encode(serv.CircuitBreakerState())
+}
+
+func forceReason(req *http.Request) string {
+	if reason := req.URL.Query().Get("reason"); reason != "" {
+		return reason
+	}
+
+	return defaultForceReason
+}
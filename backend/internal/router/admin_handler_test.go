@@ -0,0 +1,238 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
+	"github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CircuitBreakersAdminHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		setupServs func(ctrl *gomock.Controller) map[string]service.Service
+		expectRes  string
+	}{
+		{
+			name: "works",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().CircuitBreakerState().Return(circuitbreaker.State{
+					Status:      circuitbreaker.StatusOpen,
+					Reason:      "vendor outage",
+					Since:       time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					Forced:      true,
+					Transitions: map[circuitbreaker.CircuitBreakerStatus]int64{circuitbreaker.StatusOpen: 1},
+				})
+
+				return map[string]service.Service{"test": serv}
+			},
+			expectRes: `{"circuit_breakers":{"test":{"status":"open","reason":"vendor outage","since":"2020-01-02T00:00:00Z","forced":true,"fail_count":0,"half_open_level":0,"window_requests":0,"window_failures":0,"transitions":{"open":1}}}}`,
+		},
+		{
+			name: "no services",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				return map[string]service.Service{}
+			},
+			expectRes: `{"circuit_breakers":{}}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/admin/circuit-breakers", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			res := httptest.NewRecorder()
+			CircuitBreakersAdminHandler(test.setupServs(ctrl)).ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_CircuitBreakerAdminHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.Service
+		expectRes string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().CircuitBreakerState().Return(circuitbreaker.State{
+					Status:    circuitbreaker.StatusClosed,
+					FailCount: 3,
+				})
+
+				return serv
+			},
+			expectRes: `{"status":"closed","reason":"","since":"0001-01-01T00:00:00Z","forced":false,"fail_count":3,"half_open_level":0,"window_requests":0,"window_failures":0,"transitions":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", "https://localhost/admin/sites/test/circuit-breaker", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			CircuitBreakerAdminHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_CircuitBreakerOpenAdminHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.Service
+		url       string
+		expectRes string
+	}{
+		{
+			name: "open with reason",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Name().Return("test")
+				serv.EXPECT().ForceOpenCircuitBreaker("vendor outage")
+				serv.EXPECT().CircuitBreakerState().Return(circuitbreaker.State{
+					Status: circuitbreaker.StatusOpen,
+					Reason: "vendor outage",
+					Forced: true,
+				})
+
+				return serv
+			},
+			url:       "https://localhost/admin/sites/test/circuit-breaker/open?reason=vendor+outage",
+			expectRes: `{"status":"open","reason":"vendor outage","since":"0001-01-01T00:00:00Z","forced":true,"fail_count":0,"half_open_level":0,"window_requests":0,"window_failures":0,"transitions":null}`,
+		},
+		{
+			name: "open without reason",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Name().Return("test")
+				serv.EXPECT().ForceOpenCircuitBreaker(defaultForceReason)
+				serv.EXPECT().CircuitBreakerState().Return(circuitbreaker.State{
+					Status: circuitbreaker.StatusOpen,
+					Reason: defaultForceReason,
+					Forced: true,
+				})
+
+				return serv
+			},
+			url:       "https://localhost/admin/sites/test/circuit-breaker/open",
+			expectRes: `{"status":"open","reason":"forced by admin api","since":"0001-01-01T00:00:00Z","forced":true,"fail_count":0,"half_open_level":0,"window_requests":0,"window_failures":0,"transitions":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			CircuitBreakerOpenAdminHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_CircuitBreakerCloseAdminHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setupServ func(ctrl *gomock.Controller) service.Service
+		url       string
+		expectRes string
+	}{
+		{
+			name: "close with reason",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Name().Return("test")
+				serv.EXPECT().ForceCloseCircuitBreaker("vendor back")
+				serv.EXPECT().CircuitBreakerState().Return(circuitbreaker.State{
+					Status: circuitbreaker.StatusClosed,
+					Reason: "vendor back",
+				})
+
+				return serv
+			},
+			url:       "https://localhost/admin/sites/test/circuit-breaker/close?reason=vendor+back",
+			expectRes: `{"status":"closed","reason":"vendor back","since":"0001-01-01T00:00:00Z","forced":false,"fail_count":0,"half_open_level":0,"window_requests":0,"window_failures":0,"transitions":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			CircuitBreakerCloseAdminHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
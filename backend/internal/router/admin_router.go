@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/htchan/BookSpider/internal/service"
+)
+
+// AddAdminRoutes register the routes to control the services running in
+// current process. requests must carry the token as bearer token, all
+// requests are rejected if token is empty
+func AddAdminRoutes(router chi.Router, token string, services map[string]service.Service) {
+	router.Route("/admin", func(router chi.Router) {
+		router.Use(ZerologMiddleware)
+		router.Use(AdminAuthMiddleware(token))
+
+		router.Get("/circuit-breakers", CircuitBreakersAdminHandler(services))
+
+		router.Route("/sites/{siteName}/circuit-breaker", func(router chi.Router) {
+			router.Use(GetSiteMiddleware(services))
+			router.Get("/", CircuitBreakerAdminHandler)
+			router.Post("/open", CircuitBreakerOpenAdminHandler)
+			router.Post("/close", CircuitBreakerCloseAdminHandler)
+		})
+	})
+}
@@ -3,9 +3,16 @@ package router
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
@@ -29,6 +36,138 @@ func GeneralInfoAPIHandler(services map[string]service.Service) http.HandlerFunc
 	}
 }
 
+// generalSearchMaxRows limit the books queried from each site for searching
+// in all sites, as every site need to return offset+limit books to fill the
+// requested page after merging
+const generalSearchMaxRows = 200
+
+// @Summary		Search books in all sites
+// @description	search books in all sites, books are ranked by title / writer matching and completeness
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			title		query		string	false	"book title"
+// @Param			writer		query		string	false	"book writer"
+// @Param			page		query		int		false	"page number"
+// @Param			per_page	query		int		false	"number of books per page"
+// @Success		200			{object}	booksResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/books/search [get]
+func GeneralBookSearchAPIHandler(services map[string]service.Service) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := zerolog.Ctx(req.Context())
+		title := req.Context().Value(TITLE_KEY).(string)
+		writer := req.Context().Value(WRITER_KEY).(string)
+		limit := req.Context().Value(LIMIT_KEY).(int)
+		offset := req.Context().Value(OFFSET_KEY).(int)
+
+		if limit < 0 || offset < 0 || offset+limit > generalSearchMaxRows {
+			writeError(res, 400, fmt.Errorf("%w: only first %d books can be searched in all sites", InvalidParamsError, generalSearchMaxRows))
+
+			return
+		}
+
+		var (
+			wg       sync.WaitGroup
+			lock     sync.Mutex
+			bks      = make([]model.Book, 0)
+			failures = 0
+		)
+
+		for _, serv := range services {
+			wg.Add(1)
+
+			go func(serv service.Service) {
+				defer wg.Done()
+
+				siteBks, err := serv.QueryBooks(req.Context(), title, writer, limit+offset, 0)
+
+				lock.Lock()
+				defer lock.Unlock()
+
+				if err != nil {
+					logger.Error().Err(err).Str("site", serv.Name()).Msg("query books failed")
+					failures++
+
+					return
+				}
+
+				bks = append(bks, siteBks...)
+			}(serv)
+		}
+
+		wg.Wait()
+
+		if len(services) > 0 && failures == len(services) {
+			writeError(res, 400, errors.New("query books failed"))
+
+			return
+		}
+
+		rankBooks(bks, title, writer)
+
+		if offset > len(bks) {
+			offset = len(bks)
+		}
+		if offset+limit > len(bks) {
+			limit = len(bks) - offset
+		}
+
+		json.NewEncoder(res).Encode(booksResp{bks[offset : offset+limit]})
+	}
+}
+
+// bookMatchScore expect title and writer to be normalized by
+// model.NormalizeSearchText, so traditional and simplified chinese are matched
+// alike as the search query of repo does
+func bookMatchScore(bk model.Book, title, writer string) int {
+	score := 0
+	if title != "" {
+		bkTitle := model.NormalizeSearchText(bk.Title)
+		if bkTitle == title {
+			score += 2
+		} else if strings.HasPrefix(bkTitle, title) {
+			score += 1
+		}
+	}
+
+	if writer != "" {
+		bkWriter := model.NormalizeSearchText(bk.Writer.Name)
+		if bkWriter == writer {
+			score += 2
+		} else if strings.HasPrefix(bkWriter, writer) {
+			score += 1
+		}
+	}
+
+	return score
+}
+
+// rankBooks sort books by how well they match the query, then by
+// completeness of the book, and finally by site and id for stable pagination
+func rankBooks(bks []model.Book, title, writer string) {
+	title, writer = model.NormalizeSearchText(title), model.NormalizeSearchText(writer)
+
+	sort.SliceStable(bks, func(i, j int) bool {
+		iScore, jScore := bookMatchScore(bks[i], title, writer), bookMatchScore(bks[j], title, writer)
+		if iScore != jScore {
+			return iScore > jScore
+		}
+
+		if bks[i].MoreCompleteThan(bks[j]) {
+			return true
+		} else if bks[j].MoreCompleteThan(bks[i]) {
+			return false
+		}
+
+		if bks[i].Site != bks[j].Site {
+			return bks[i].Site < bks[j].Site
+		}
+
+		return bks[i].ID < bks[j].ID
+	})
+}
+
 // @Summary		Get site info
 // @description	get site info
 // @Tags			book-spider-api
@@ -92,6 +231,39 @@ func BookRandomAPIHandler(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// @Summary		Search books content
+// @description	search keyword in downloaded chapters, matched chapters are returned with highlighted snippet
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			q			query		string	true	"keyword in chapter content"
+// @Param			page		query		int		false	"page number"
+// @Param			per_page	query		int		false	"number of chapters per page"
+// @Success		200			{object}	contentMatchesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/content-search [get]
+func BookContentSearchAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	keyword := strings.TrimSpace(req.URL.Query().Get("q"))
+	limit := req.Context().Value(LIMIT_KEY).(int)
+	offset := req.Context().Value(OFFSET_KEY).(int)
+
+	if keyword == "" {
+		writeError(res, 400, InvalidParamsError)
+		return
+	}
+
+	matches, err := serv.SearchContent(req.Context(), keyword, limit, offset)
+	if err != nil {
+		logger.Error().Err(err).Msg("search content failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(contentMatchesResp{matches})
+	}
+}
+
 // @Summary		Get book info
 // @description	get book info
 // @Tags			book-spider-api
@@ -133,6 +305,270 @@ func BookDownloadAPIHandler(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// @Summary		Get book work
+// @description	get the canonical book and all sources of the same title and writer across sites
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	true	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Success		200			{object}	model.Work
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/work [get]
+func BookWorkAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	bk := req.Context().Value(BOOK_KEY).(*model.Book)
+
+	work, err := serv.BookWork(req.Context(), bk)
+	if err != nil {
+		logger.Error().Err(err).Msg("build book work failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(work)
+	}
+}
+
+// @Summary		List job runs
+// @description	list job runs of site from the latest one, optionally filtered by operation
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			operation	query		string	false	"operation of job runs"
+// @Param			page		query		int		false	"page number"
+// @Param			per_page	query		int		false	"number of job runs per page"
+// @Success		200			{object}	jobRunsResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/job-runs [get]
+func JobRunsAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	operation := strings.TrimSpace(req.URL.Query().Get("operation"))
+	limit := req.Context().Value(LIMIT_KEY).(int)
+	offset := req.Context().Value(OFFSET_KEY).(int)
+
+	runs, err := serv.JobRuns(req.Context(), operation, limit, offset)
+	if err != nil {
+		logger.Error().Err(err).Msg("list job runs failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(jobRunsResp{runs})
+	}
+}
+
+// @Summary		Get job run
+// @description	get job run with the stats snapshot when it ended
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			runID		path		int		true	"job run id"
+// @Success		200			{object}	model.JobRun
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/job-runs/{runID} [get]
+func JobRunAPIHandler(res http.ResponseWriter, req *http.Request) {
+	run := req.Context().Value(JOB_RUN_KEY).(*model.JobRun)
+	json.NewEncoder(res).Encode(run)
+}
+
+// @Summary		Enqueue job
+// @description	enqueue operation of site, or of the book if idHash is given. the job is run by worker
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			idHash		path		string	false	"id and hash in format <id>[-<hash>]. -<hash is optional"
+// @Param			operation	query		string	true	"one of update, explore, download, validate, process and patch-chapters"
+// @Success		201			{object}	model.Job
+// @Failure		400			{object}	errResp
+// @Failure		401			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/jobs [post]
+// @Router			/api/book-spider/sites/{siteName}/books/{idHash}/jobs [post]
+func EnqueueJobAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	bk, _ := req.Context().Value(BOOK_KEY).(*model.Book)
+	operation := strings.TrimSpace(req.URL.Query().Get("operation"))
+
+	job, err := serv.EnqueueJob(req.Context(), operation, bk)
+	if err != nil {
+		logger.Error().Err(err).Str("operation", operation).Msg("enqueue job failed")
+		writeError(res, 400, err)
+	} else {
+		res.WriteHeader(http.StatusCreated)
+		json.NewEncoder(res).Encode(job)
+	}
+}
+
+// @Summary		Get job
+// @description	get status of enqueued job, with the stats of operation once it ended
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			jobID		path		int		true	"job id"
+// @Success		200			{object}	model.Job
+// @Failure		401			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/jobs/{jobID} [get]
+func JobAPIHandler(res http.ResponseWriter, req *http.Request) {
+	job := req.Context().Value(JOB_KEY).(*model.Job)
+	json.NewEncoder(res).Encode(job)
+}
+
+// @Summary		List book tasks
+// @description	list book tasks of site, optionally filtered by operation and status
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			operation	query		string	false	"operation of book tasks"
+// @Param			status		query		string	false	"one of queued, leased, done and dead"
+// @Param			page		query		int		false	"page number"
+// @Param			per_page	query		int		false	"number of book tasks per page"
+// @Success		200			{object}	bookTasksResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/book-tasks [get]
+func BookTasksAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+	operation := strings.TrimSpace(req.URL.Query().Get("operation"))
+	status := model.BookTaskStatus(strings.TrimSpace(req.URL.Query().Get("status")))
+	limit := req.Context().Value(LIMIT_KEY).(int)
+	offset := req.Context().Value(OFFSET_KEY).(int)
+
+	tasks, err := serv.BookTasks(req.Context(), operation, status, limit, offset)
+	if err != nil {
+		logger.Error().Err(err).Msg("list book tasks failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(bookTasksResp{tasks})
+	}
+}
+
+// @Summary		Requeue book task
+// @description	put dead lettered book task back to queue with attempts reset
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			taskID		path		int		true	"book task id"
+// @Success		200			{object}	model.BookTask
+// @Failure		400			{object}	errResp
+// @Failure		401			{object}	errResp
+// @Failure		404			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/book-tasks/{taskID}/requeue [post]
+func RequeueBookTaskAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+
+	taskID, err := strconv.Atoi(chi.URLParam(req, "taskID"))
+	if err != nil {
+		writeError(res, http.StatusBadRequest, InvalidParamsError)
+		return
+	}
+
+	task, err := serv.RequeueBookTask(req.Context(), taskID)
+	if errors.Is(err, repo.ErrBookTaskNotExist) {
+		writeError(res, http.StatusNotFound, errors.New("dead book task not found"))
+	} else if err != nil {
+		logger.Error().Err(err).Int("book_task_id", taskID).Msg("requeue book task failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(task)
+	}
+}
+
+const (
+	defaultScheduleRunCount = 5
+	maxScheduleRunCount     = 50
+)
+
+// scheduleRunCount return the number of next runs requested in count query
+func scheduleRunCount(req *http.Request) (int, error) {
+	countStr := req.URL.Query().Get("count")
+	if countStr == "" {
+		return defaultScheduleRunCount, nil
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 || count > maxScheduleRunCount {
+		return 0, InvalidParamsError
+	}
+
+	return count, nil
+}
+
+// @Summary		List schedules of all sites
+// @description	list cron schedules of operations of all sites with the next planned runs
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			count	query		int	false	"number of next runs of each schedule, 5 by default and 50 at most"
+// @Success		200		{object}	schedulesResp
+// @Failure		400		{object}	errResp
+// @Router			/api/book-spider/schedules [get]
+func GeneralSchedulesAPIHandler(services map[string]service.Service) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		logger := zerolog.Ctx(req.Context())
+
+		count, err := scheduleRunCount(req)
+		if err != nil {
+			writeError(res, 400, err)
+			return
+		}
+
+		now := time.Now()
+		schedules := make([]model.Schedule, 0)
+		for _, serv := range services {
+			siteSchedules, err := serv.Schedules(req.Context(), now, count)
+			if err != nil {
+				logger.Error().Err(err).Str("site", serv.Name()).Msg("list schedules failed")
+				writeError(res, 400, err)
+				return
+			}
+
+			schedules = append(schedules, siteSchedules...)
+		}
+
+		sort.SliceStable(schedules, func(i, j int) bool {
+			return schedules[i].Site < schedules[j].Site
+		})
+
+		json.NewEncoder(res).Encode(schedulesResp{schedules})
+	}
+}
+
+// @Summary		List schedules
+// @description	list cron schedules of operations of site with the next planned runs
+// @Tags			book-spider-api
+// @Accept			json
+// @Produce		json
+// @Param			siteName	path		string	true	"site name"
+// @Param			count		query		int		false	"number of next runs of each schedule, 5 by default and 50 at most"
+// @Success		200			{object}	schedulesResp
+// @Failure		400			{object}	errResp
+// @Router			/api/book-spider/sites/{siteName}/schedules [get]
+func SchedulesAPIHandler(res http.ResponseWriter, req *http.Request) {
+	logger := zerolog.Ctx(req.Context())
+	serv := req.Context().Value(SERV_KEY).(service.Service)
+
+	count, err := scheduleRunCount(req)
+	if err != nil {
+		writeError(res, 400, err)
+		return
+	}
+
+	schedules, err := serv.Schedules(req.Context(), time.Now(), count)
+	if err != nil {
+		logger.Error().Err(err).Msg("list schedules failed")
+		writeError(res, 400, err)
+	} else {
+		json.NewEncoder(res).Encode(schedulesResp{schedules})
+	}
+}
+
 // @Summary		DB stats
 // @description	db stats
 // @Tags			book-spider-api
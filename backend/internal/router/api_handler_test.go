@@ -9,7 +9,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
 	"github.com/htchan/BookSpider/internal/model"
@@ -70,6 +72,155 @@ func Test_GeneralInfoAPIHandler(t *testing.T) {
 	}
 }
 
+func Test_GeneralBookSearchAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setupServs    func(ctrl *gomock.Controller) map[string]service.Service
+		url           string
+		title, writer string
+		limit, offset int
+		expectRes     string
+	}{
+		{
+			name: "merge and rank books from all sites",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv1 := mockservice.NewMockService(ctrl)
+				serv1.EXPECT().QueryBooks(gomock.Any(), "title", "", 2, 0).Return([]model.Book{
+					{Site: "test1", ID: 1, Title: "title 1", Status: model.StatusEnd},
+					{Site: "test1", ID: 2, Title: "title", Status: model.StatusInProgress},
+				}, nil)
+
+				serv2 := mockservice.NewMockService(ctrl)
+				serv2.EXPECT().QueryBooks(gomock.Any(), "title", "", 2, 0).Return([]model.Book{
+					{Site: "test2", ID: 3, Title: "title", Status: model.StatusEnd},
+				}, nil)
+
+				return map[string]service.Service{
+					"test1": serv1,
+					"test2": serv2,
+				}
+			},
+			url:    "https://localhost/data",
+			title:  "title",
+			writer: "",
+			limit:  2,
+			offset: 0,
+			expectRes: `{"books":[` +
+				`{"site":"test2","id":3,"hash_code":"0","title":"title","writer":"","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":false,"error":""},` +
+				`{"site":"test1","id":2,"hash_code":"0","title":"title","writer":"","type":"","update_date":"","update_chapter":"","status":"INPROGRESS","is_downloaded":false,"error":""}` +
+				`]}`,
+		},
+		{
+			name: "paginate merged books and skip failed site",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv1 := mockservice.NewMockService(ctrl)
+				serv1.EXPECT().QueryBooks(gomock.Any(), "", "writer", 2, 0).Return([]model.Book{
+					{Site: "test1", ID: 1, Writer: model.Writer{Name: "writer"}},
+					{Site: "test1", ID: 2, Writer: model.Writer{Name: "writer"}},
+				}, nil)
+
+				serv2 := mockservice.NewMockService(ctrl)
+				serv2.EXPECT().QueryBooks(gomock.Any(), "", "writer", 2, 0).Return(nil, errors.New("some error"))
+				serv2.EXPECT().Name().Return("test2")
+
+				return map[string]service.Service{
+					"test1": serv1,
+					"test2": serv2,
+				}
+			},
+			url:    "https://localhost/data",
+			title:  "",
+			writer: "writer",
+			limit:  1,
+			offset: 1,
+			expectRes: `{"books":[` +
+				`{"site":"test1","id":2,"hash_code":"0","title":"","writer":"writer","type":"","update_date":"","update_chapter":"","status":"ERROR","is_downloaded":false,"error":""}` +
+				`]}`,
+		},
+		{
+			name: "rank traditional and simplified chinese alike",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv1 := mockservice.NewMockService(ctrl)
+				serv1.EXPECT().QueryBooks(gomock.Any(), "测试", "", 1, 0).Return([]model.Book{
+					{Site: "test1", ID: 1, Title: "测试 2", Status: model.StatusEnd},
+				}, nil)
+
+				serv2 := mockservice.NewMockService(ctrl)
+				serv2.EXPECT().QueryBooks(gomock.Any(), "测试", "", 1, 0).Return([]model.Book{
+					{Site: "test2", ID: 2, Title: "測試", Status: model.StatusInProgress},
+				}, nil)
+
+				return map[string]service.Service{
+					"test1": serv1,
+					"test2": serv2,
+				}
+			},
+			url:    "https://localhost/data",
+			title:  "测试",
+			writer: "",
+			limit:  1,
+			offset: 0,
+			expectRes: `{"books":[` +
+				`{"site":"test2","id":2,"hash_code":"0","title":"測試","writer":"","type":"","update_date":"","update_chapter":"","status":"INPROGRESS","is_downloaded":false,"error":""}` +
+				`]}`,
+		},
+		{
+			name: "page out of range",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				return map[string]service.Service{"test1": mockservice.NewMockService(ctrl)}
+			},
+			url:       "https://localhost/data",
+			title:     "title",
+			limit:     10,
+			offset:    195,
+			expectRes: `{"error":"invalid params: only first 200 books can be searched in all sites"}`,
+		},
+		{
+			name: "all sites failed",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().QueryBooks(gomock.Any(), "title", "", 10, 0).Return(nil, errors.New("some error"))
+				serv.EXPECT().Name().Return("test1")
+
+				return map[string]service.Service{"test1": serv}
+			},
+			url:       "https://localhost/data",
+			title:     "title",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"error":"query books failed"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), TITLE_KEY, test.title)
+			ctx = context.WithValue(ctx, WRITER_KEY, test.writer)
+			ctx = context.WithValue(ctx, LIMIT_KEY, test.limit)
+			ctx = context.WithValue(ctx, OFFSET_KEY, test.offset)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			GeneralBookSearchAPIHandler(test.setupServs(ctrl)).ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_SiteInfoAPIHandler(t *testing.T) {
 	t.Parallel()
 
@@ -251,6 +402,87 @@ func Test_BookRandomAPIHandler(t *testing.T) {
 	}
 }
 
+func Test_BookContentSearchAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setupServ     func(ctrl *gomock.Controller) service.Service
+		url           string
+		limit, offset int
+		expectRes     string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().SearchContent(gomock.Any(), "keyword", 10, 0).Return([]model.ContentMatch{
+					{
+						Book:         model.Book{Site: "test", ID: 1, Status: model.StatusEnd},
+						ChapterIndex: 2,
+						ChapterTitle: "chapter 3",
+						Snippet:      "some <em>keyword</em> here",
+					},
+				}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data?q=keyword",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"matches":[{"book":{"site":"test","id":1,"hash_code":"0","title":"","writer":"","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":false,"error":""},"chapter_index":2,"chapter_title":"chapter 3","snippet":"some \u003cem\u003ekeyword\u003c/em\u003e here"}]}`,
+		},
+		{
+			name: "missing keyword",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				return mockservice.NewMockService(ctrl)
+			},
+			url:       "https://localhost/data?q=%20",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"error":"invalid params"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().SearchContent(gomock.Any(), "keyword", 10, 0).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			url:       "https://localhost/data?q=keyword",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, LIMIT_KEY, test.limit)
+			ctx = context.WithValue(ctx, OFFSET_KEY, test.offset)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookContentSearchAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_BookInfoAPIHandler(t *testing.T) {
 	t.Parallel()
 
@@ -294,6 +526,96 @@ func Test_BookInfoAPIHandler(t *testing.T) {
 	}
 }
 
+func Test_BookWorkAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		url       string
+		setupServ func(ctrl *gomock.Controller) service.Service
+		bk        *model.Book
+		expectRes string
+	}{
+		{
+			name: "works",
+			url:  "https://localhost/data",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().
+					BookWork(gomock.Any(), &model.Book{
+						Site: "test", ID: 1, HashCode: 100, Title: "title 1",
+						Writer: model.Writer{Name: "writer 1"}, Status: model.StatusInProgress,
+					}).
+					Return(&model.Work{
+						Checksum: "dGl0bGUx", WriterChecksum: "d3JpdGVyMQ==",
+						Canonical: model.Book{
+							Site: "other", ID: 2, HashCode: 100, Title: "title 1",
+							Writer: model.Writer{Name: "writer 1"}, Status: model.StatusEnd, IsDownloaded: true,
+						},
+						Sources: model.BookGroup{
+							{
+								Site: "other", ID: 2, HashCode: 100, Title: "title 1",
+								Writer: model.Writer{Name: "writer 1"}, Status: model.StatusEnd, IsDownloaded: true,
+							},
+							{
+								Site: "test", ID: 1, HashCode: 100, Title: "title 1",
+								Writer: model.Writer{Name: "writer 1"}, Status: model.StatusInProgress,
+							},
+						},
+					}, nil)
+
+				return serv
+			},
+			bk: &model.Book{
+				Site: "test", ID: 1, HashCode: 100, Title: "title 1",
+				Writer: model.Writer{Name: "writer 1"}, Status: model.StatusInProgress,
+			},
+			expectRes: `{"checksum":"dGl0bGUx","writer_checksum":"d3JpdGVyMQ==",` +
+				`"canonical":{"site":"other","id":2,"hash_code":"2s","title":"title 1","writer":"writer 1","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":true,"error":""},` +
+				`"sources":[{"site":"other","id":2,"hash_code":"2s","title":"title 1","writer":"writer 1","type":"","update_date":"","update_chapter":"","status":"END","is_downloaded":true,"error":""},` +
+				`{"site":"test","id":1,"hash_code":"2s","title":"title 1","writer":"writer 1","type":"","update_date":"","update_chapter":"","status":"INPROGRESS","is_downloaded":false,"error":""}]}`,
+		},
+		{
+			name: "fail to find work",
+			url:  "https://localhost/data",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().
+					BookWork(gomock.Any(), &model.Book{Site: "test", ID: 1}).
+					Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			bk:        &model.Book{Site: "test", ID: 1},
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, BOOK_KEY, test.bk)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookWorkAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
 func Test_BookDownloadAPIHandler(t *testing.T) {
 	t.Parallel()
 
@@ -415,3 +737,564 @@ func Test_DBStatAPIHandler(t *testing.T) {
 		})
 	}
 }
+
+func Test_JobRunsAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	endedAt := startedAt.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		setupServ     func(ctrl *gomock.Controller) service.Service
+		url           string
+		limit, offset int
+		expectRes     string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().JobRuns(gomock.Any(), "explore", 10, 0).Return([]model.JobRun{
+					{
+						ID: 1, Site: "test", Operation: "explore",
+						StartedAt: startedAt, EndedAt: &endedAt,
+						Outcome: model.JobOutcomeSuccess, Stats: map[string]int64{"new_entity": 0},
+					},
+				}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data?operation=explore",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"job_runs":[{"id":1,"site":"test","operation":"explore","started_at":"2020-01-02T03:04:05Z","ended_at":"2020-01-02T04:04:05Z","outcome":"success","error":"","stats":{"new_entity":0}}]}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().JobRuns(gomock.Any(), "", 10, 10).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			limit:     10,
+			offset:    10,
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, LIMIT_KEY, test.limit)
+			ctx = context.WithValue(ctx, OFFSET_KEY, test.offset)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			JobRunsAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_JobRunAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		run       *model.JobRun
+		expectRes string
+	}{
+		{
+			name: "running job",
+			run: &model.JobRun{
+				ID: 2, Site: "test", Operation: "download",
+				StartedAt: startedAt, Outcome: model.JobOutcomeRunning,
+			},
+			expectRes: `{"id":2,"site":"test","operation":"download","started_at":"2020-01-02T03:04:05Z","ended_at":null,"outcome":"running","error":"","stats":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			req = req.WithContext(context.WithValue(req.Context(), JOB_RUN_KEY, test.run))
+
+			res := httptest.NewRecorder()
+			JobRunAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_EnqueueJobAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupServ      func(ctrl *gomock.Controller) service.Service
+		bk             *model.Book
+		url            string
+		wantStatusCode int
+		expectRes      string
+	}{
+		{
+			name: "enqueue site job",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().EnqueueJob(gomock.Any(), "explore", nil).Return(&model.Job{
+					ID: 1, Site: "test", Operation: "explore",
+					Status: model.JobOutcomeQueued, CreatedAt: createdAt,
+				}, nil)
+
+				return serv
+			},
+			url:            "https://localhost/data?operation=explore",
+			wantStatusCode: http.StatusCreated,
+			expectRes:      `{"id":1,"site":"test","operation":"explore","status":"queued","error":"","stats":null,"created_at":"2020-01-02T03:04:05Z","started_at":null,"ended_at":null,"attempts":0,"lease_owner":"","lease_expires_at":null}`,
+		},
+		{
+			name: "enqueue book job",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().EnqueueJob(gomock.Any(), "process", &model.Book{Site: "test", ID: 2, HashCode: 3}).Return(&model.Job{
+					ID: 4, Site: "test", Operation: "process", BookID: 2, HashCode: 3,
+					Status: model.JobOutcomeQueued, CreatedAt: createdAt,
+				}, nil)
+
+				return serv
+			},
+			bk:             &model.Book{Site: "test", ID: 2, HashCode: 3},
+			url:            "https://localhost/data?operation=process",
+			wantStatusCode: http.StatusCreated,
+			expectRes:      `{"id":4,"site":"test","operation":"process","book_id":2,"hash_code":3,"status":"queued","error":"","stats":null,"created_at":"2020-01-02T03:04:05Z","started_at":null,"ended_at":null,"attempts":0,"lease_owner":"","lease_expires_at":null}`,
+		},
+		{
+			name: "unknown operation",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().EnqueueJob(gomock.Any(), "", nil).Return(nil, service.ErrUnknownOperation)
+
+				return serv
+			},
+			url:            "https://localhost/data",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"unknown operation"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			if test.bk != nil {
+				ctx = context.WithValue(ctx, BOOK_KEY, test.bk)
+			}
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			EnqueueJobAPIHandler(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_JobAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	endedAt := createdAt.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		job       *model.Job
+		expectRes string
+	}{
+		{
+			name: "ended job",
+			job: &model.Job{
+				ID: 1, Site: "test", Operation: "update",
+				Status: model.JobOutcomeSuccess, Stats: map[string]int64{"total": 1},
+				CreatedAt: createdAt, StartedAt: &createdAt, EndedAt: &endedAt,
+			},
+			expectRes: `{"id":1,"site":"test","operation":"update","status":"success","error":"","stats":{"total":1},"created_at":"2020-01-02T03:04:05Z","started_at":"2020-01-02T03:04:05Z","ended_at":"2020-01-02T04:04:05Z","attempts":0,"lease_owner":"","lease_expires_at":null}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest("GET", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			req = req.WithContext(context.WithValue(req.Context(), JOB_KEY, test.job))
+
+			res := httptest.NewRecorder()
+			JobAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_BookTasksAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	availableAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		setupServ     func(ctrl *gomock.Controller) service.Service
+		url           string
+		limit, offset int
+		expectRes     string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().BookTasks(gomock.Any(), "update", model.BookTaskStatusDead, 10, 0).Return([]model.BookTask{
+					{
+						ID: 1, Site: "test", Operation: "update", BookID: 2, HashCode: 3,
+						Status: model.BookTaskStatusDead, Attempts: 3, AvailableAt: availableAt,
+						Error: "some error", UpdatedAt: availableAt,
+					},
+				}, nil)
+
+				return serv
+			},
+			url:       "https://localhost/data?operation=update&status=dead",
+			limit:     10,
+			offset:    0,
+			expectRes: `{"book_tasks":[{"id":1,"site":"test","operation":"update","book_id":2,"hash_code":3,"status":"dead","attempts":3,"available_at":"2020-01-02T03:04:05Z","lease_owner":"","lease_expires_at":null,"error":"some error","updated_at":"2020-01-02T03:04:05Z"}]}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().BookTasks(gomock.Any(), "", model.BookTaskStatus(""), 10, 10).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			url:       "https://localhost/data",
+			limit:     10,
+			offset:    10,
+			expectRes: `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			ctx = context.WithValue(ctx, LIMIT_KEY, test.limit)
+			ctx = context.WithValue(ctx, OFFSET_KEY, test.offset)
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			BookTasksAPIHandler(res, req)
+
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_RequeueBookTaskAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	availableAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupServ      func(ctrl *gomock.Controller) service.Service
+		taskID         string
+		wantStatusCode int
+		expectRes      string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().RequeueBookTask(gomock.Any(), 1).Return(&model.BookTask{
+					ID: 1, Site: "test", Operation: "download", BookID: 2, HashCode: 3,
+					Status: model.BookTaskStatusQueued, AvailableAt: availableAt, UpdatedAt: availableAt,
+				}, nil)
+
+				return serv
+			},
+			taskID:         "1",
+			wantStatusCode: http.StatusOK,
+			expectRes:      `{"id":1,"site":"test","operation":"download","book_id":2,"hash_code":3,"status":"queued","attempts":0,"available_at":"2020-01-02T03:04:05Z","lease_owner":"","lease_expires_at":null,"error":"","updated_at":"2020-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "task not dead",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().RequeueBookTask(gomock.Any(), 2).Return(nil, repo.ErrBookTaskNotExist)
+
+				return serv
+			},
+			taskID:         "2",
+			wantStatusCode: http.StatusNotFound,
+			expectRes:      `{"error":"dead book task not found"}`,
+		},
+		{
+			name: "invalid task id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				return mockservice.NewMockService(ctrl)
+			},
+			taskID:         "abc",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"invalid params"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().RequeueBookTask(gomock.Any(), 3).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			taskID:         "3",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("POST", "https://localhost/data", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("taskID", test.taskID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, SERV_KEY, test.setupServ(ctrl))
+			req = req.WithContext(ctx)
+
+			res := httptest.NewRecorder()
+			RequeueBookTaskAPIHandler(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GeneralSchedulesAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	nextRun := time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupServs     func(ctrl *gomock.Controller) map[string]service.Service
+		url            string
+		wantStatusCode int
+		expectRes      string
+	}{
+		{
+			name: "works",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv1 := mockservice.NewMockService(ctrl)
+				serv1.EXPECT().Schedules(gomock.Any(), gomock.Any(), 1).Return([]model.Schedule{
+					{Site: "test1", Operation: "process", Cron: "@weekly", NextRuns: []time.Time{nextRun}},
+				}, nil)
+
+				serv2 := mockservice.NewMockService(ctrl)
+				serv2.EXPECT().Schedules(gomock.Any(), gomock.Any(), 1).Return([]model.Schedule{
+					{Site: "test2", Operation: "download", Cron: "0 0 * * *", NextRuns: []time.Time{nextRun}},
+				}, nil)
+
+				return map[string]service.Service{
+					"test1": serv1,
+					"test2": serv2,
+				}
+			},
+			url:            "https://localhost/data?count=1",
+			wantStatusCode: http.StatusOK,
+			expectRes:      `{"schedules":[{"site":"test1","operation":"process","cron":"@weekly","next_runs":["2020-01-05T00:00:00Z"]},{"site":"test2","operation":"download","cron":"0 0 * * *","next_runs":["2020-01-05T00:00:00Z"]}]}`,
+		},
+		{
+			name: "invalid count",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				return map[string]service.Service{"test": mockservice.NewMockService(ctrl)}
+			},
+			url:            "https://localhost/data?count=100",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"invalid params"}`,
+		},
+		{
+			name: "error",
+			setupServs: func(ctrl *gomock.Controller) map[string]service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Schedules(gomock.Any(), gomock.Any(), 5).Return(nil, errors.New("some error"))
+				serv.EXPECT().Name().Return("test")
+
+				return map[string]service.Service{"test": serv}
+			},
+			url:            "https://localhost/data",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			res := httptest.NewRecorder()
+			GeneralSchedulesAPIHandler(test.setupServs(ctrl)).ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_SchedulesAPIHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		setupServ      func(ctrl *gomock.Controller) service.Service
+		url            string
+		wantStatusCode int
+		expectRes      string
+	}{
+		{
+			name: "works",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Schedules(gomock.Any(), gomock.Any(), 2).Return([]model.Schedule{
+					{
+						Site: "test", Operation: "update", Cron: "0 3 * * *",
+						NextRuns: []time.Time{
+							time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC),
+							time.Date(2020, 1, 3, 3, 0, 0, 0, time.UTC),
+						},
+					},
+				}, nil)
+
+				return serv
+			},
+			url:            "https://localhost/data?count=2",
+			wantStatusCode: http.StatusOK,
+			expectRes:      `{"schedules":[{"site":"test","operation":"update","cron":"0 3 * * *","next_runs":["2020-01-02T03:00:00Z","2020-01-03T03:00:00Z"]}]}`,
+		},
+		{
+			name: "invalid count",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				return mockservice.NewMockService(ctrl)
+			},
+			url:            "https://localhost/data?count=abc",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"invalid params"}`,
+		},
+		{
+			name: "error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Schedules(gomock.Any(), gomock.Any(), 5).Return(nil, errors.New("some error"))
+
+				return serv
+			},
+			url:            "https://localhost/data",
+			wantStatusCode: http.StatusBadRequest,
+			expectRes:      `{"error":"some error"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			req = req.WithContext(context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl)))
+
+			res := httptest.NewRecorder()
+			SchedulesAPIHandler(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"database/sql"
 
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
 	"github.com/htchan/BookSpider/internal/model"
 )
 
@@ -14,6 +15,26 @@ type booksResp struct {
 	Books []model.Book `json:"books"`
 }
 
+type contentMatchesResp struct {
+	Matches []model.ContentMatch `json:"matches"`
+}
+
+type jobRunsResp struct {
+	JobRuns []model.JobRun `json:"job_runs"`
+}
+
+type bookTasksResp struct {
+	BookTasks []model.BookTask `json:"book_tasks"`
+}
+
+type schedulesResp struct {
+	Schedules []model.Schedule `json:"schedules"`
+}
+
 type dbStatsResp struct {
 	Stats []sql.DBStats `json:"stats"`
 }
+
+type circuitBreakersResp struct {
+	CircuitBreakers map[string]circuitbreaker.State `json:"circuit_breakers"`
+}
@@ -14,6 +14,7 @@ import (
 )
 
 var UnauthorizedError = errors.New("unauthorized")
+var AdminTokenNotSetError = errors.New("admin token not set")
 var InvalidParamsError = errors.New("invalid params")
 var RecordNotFoundError = errors.New("record not found")
 
@@ -25,6 +26,7 @@ func writeError(res http.ResponseWriter, statusCode int, err error) {
 func AddAPIRoutes(router chi.Router, conf *config.APIConfig, services map[string]service.Service) {
 	router.Route(conf.APIRoutePrefix, func(router chi.Router) {
 		router.Use(ZerologMiddleware)
+		router.Use(MetricsMiddleware)
 		router.Use(
 			cors.Handler(
 				cors.Options{
@@ -37,22 +39,45 @@ func AddAPIRoutes(router chi.Router, conf *config.APIConfig, services map[string
 		)
 
 		router.Get("/info", GeneralInfoAPIHandler(services))
+		router.Get("/schedules", GeneralSchedulesAPIHandler(services))
+		router.With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).
+			Get("/books/search", GeneralBookSearchAPIHandler(services))
 
 		router.Route("/sites/{siteName}", func(router chi.Router) {
 			router.Use(GetSiteMiddleware(services))
 			router.Get("/", SiteInfoAPIHandler)
+			router.Get("/schedules", SchedulesAPIHandler)
 
 			router.Route("/books", func(router chi.Router) {
 				router.With(GetSearchParamsMiddleware).With(GetPageParamsMiddleware).Get("/search", BookSearchAPIHandler)
 				router.With(GetPageParamsMiddleware).Get("/random", BookRandomAPIHandler)
+				router.With(GetPageParamsMiddleware).Get("/content-search", BookContentSearchAPIHandler)
 
 				router.Route("/{idHash:\\d+(-[\\w]+)?}", func(router chi.Router) {
 					// idHash format is <id>-<hash>
 					router.Use(GetBookMiddleware)
 					router.With().Get("/", BookInfoAPIHandler)
 					router.Get("/download", BookDownloadAPIHandler)
+					router.Get("/work", BookWorkAPIHandler)
+					router.With(AdminAuthMiddleware(conf.AdminToken)).Post("/jobs", EnqueueJobAPIHandler)
 				})
 			})
+
+			router.Route("/job-runs", func(router chi.Router) {
+				router.With(GetPageParamsMiddleware).Get("/", JobRunsAPIHandler)
+				router.With(GetJobRunMiddleware).Get("/{runID:\\d+}", JobRunAPIHandler)
+			})
+
+			router.Route("/jobs", func(router chi.Router) {
+				router.Use(AdminAuthMiddleware(conf.AdminToken))
+				router.Post("/", EnqueueJobAPIHandler)
+				router.With(GetJobMiddleware).Get("/{jobID:\\d+}", JobAPIHandler)
+			})
+
+			router.Route("/book-tasks", func(router chi.Router) {
+				router.With(GetPageParamsMiddleware).Get("/", BookTasksAPIHandler)
+				router.With(AdminAuthMiddleware(conf.AdminToken)).Post("/{taskID:\\d+}/requeue", RequeueBookTaskAPIHandler)
+			})
 		})
 
 		router.Get("/db-stats", DBStatsAPIHandler(services))
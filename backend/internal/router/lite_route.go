@@ -9,6 +9,7 @@ import (
 func AddLiteRoutes(router chi.Router, conf *config.APIConfig, services map[string]service.Service) {
 	router.Route(conf.LiteRoutePrefix, func(router chi.Router) {
 		router.Use(ZerologMiddleware)
+		router.Use(MetricsMiddleware)
 		router.Use(SetUriPrefixMiddleware(conf.LiteRoutePrefix))
 
 		router.Route("/sites/{siteName}", func(router chi.Router) {
@@ -0,0 +1,11 @@
+package router
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// AddMetricsRoutes expose the prometheus metrics of current process
+func AddMetricsRoutes(router chi.Router) {
+	router.Handle("/metrics", promhttp.Handler())
+}
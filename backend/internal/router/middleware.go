@@ -2,14 +2,19 @@ package router
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
+	"github.com/htchan/BookSpider/internal/metrics"
 	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -27,6 +32,8 @@ const (
 	OFFSET_KEY     ContextKey = "offset"
 	URI_PREFIX_KEY ContextKey = "uri_prefix"
 	FORMAT_KEY     ContextKey = "format"
+	JOB_RUN_KEY    ContextKey = "job_run"
+	JOB_KEY        ContextKey = "job"
 )
 
 func GetSiteMiddleware(services map[string]service.Service) func(http.Handler) http.Handler {
@@ -93,6 +100,69 @@ func GetBookMiddleware(next http.Handler) http.Handler {
 		},
 	)
 }
+
+func GetJobRunMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			logger := zerolog.Ctx(req.Context())
+			serv := req.Context().Value(SERV_KEY).(service.Service)
+
+			runID, err := strconv.Atoi(chi.URLParam(req, "runID"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+
+			run, err := serv.JobRun(req.Context(), runID)
+			if errors.Is(err, repo.ErrJobRunNotExist) {
+				writeError(res, http.StatusNotFound, errors.New("job run not found"))
+				return
+			} else if err != nil {
+				logger.Error().Err(err).
+					Str("site", serv.Name()).
+					Int("job_run_id", runID).
+					Msg("get job run middleware failed")
+				writeError(res, http.StatusBadRequest, err)
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), JOB_RUN_KEY, run)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
+func GetJobMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			logger := zerolog.Ctx(req.Context())
+			serv := req.Context().Value(SERV_KEY).(service.Service)
+
+			jobID, err := strconv.Atoi(chi.URLParam(req, "jobID"))
+			if err != nil {
+				writeError(res, http.StatusBadRequest, InvalidParamsError)
+				return
+			}
+
+			job, err := serv.Job(req.Context(), jobID)
+			if errors.Is(err, repo.ErrJobNotExist) {
+				writeError(res, http.StatusNotFound, errors.New("job not found"))
+				return
+			} else if err != nil {
+				logger.Error().Err(err).
+					Str("site", serv.Name()).
+					Int("job_id", jobID).
+					Msg("get job middleware failed")
+				writeError(res, http.StatusBadRequest, err)
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), JOB_KEY, job)
+			next.ServeHTTP(res, req.WithContext(ctx))
+		},
+	)
+}
+
 func GetSearchParamsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(res http.ResponseWriter, req *http.Request) {
@@ -149,6 +219,33 @@ func ZerologMiddleware(next http.Handler) http.Handler {
 	)
 }
 
+// MetricsMiddleware report the latency of requests by route pattern instead
+// of the requested path, so that book ids do not blow up the metrics
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			wrappedRes := middleware.NewWrapResponseWriter(res, req.ProtoMajor)
+
+			next.ServeHTTP(wrappedRes, req)
+
+			route := "unmatched"
+			if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+
+			status := wrappedRes.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			metrics.APIRequestDuration.
+				WithLabelValues(req.Method, route, strconv.Itoa(status)).
+				Observe(time.Since(start).Seconds())
+		},
+	)
+}
+
 func SetUriPrefixMiddleware(uriPrefix string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(
@@ -159,3 +256,26 @@ func SetUriPrefixMiddleware(uriPrefix string) func(http.Handler) http.Handler {
 		)
 	}
 }
+
+// AdminAuthMiddleware reject requests without the token as bearer token.
+// all requests are rejected if token is empty, so admin routes are never
+// open by missing config
+func AdminAuthMiddleware(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(
+			func(res http.ResponseWriter, req *http.Request) {
+				if token == "" {
+					writeError(res, http.StatusServiceUnavailable, AdminTokenNotSetError)
+					return
+				}
+
+				given := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+				if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+					writeError(res, http.StatusUnauthorized, UnauthorizedError)
+					return
+				}
+				next.ServeHTTP(res, req)
+			},
+		)
+	}
+}
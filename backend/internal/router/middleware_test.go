@@ -12,10 +12,14 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
+	"github.com/htchan/BookSpider/internal/metrics"
 	mockservice "github.com/htchan/BookSpider/internal/mock/service/v1"
 	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
 	"github.com/htchan/BookSpider/internal/service"
 	servicev1 "github.com/htchan/BookSpider/internal/service/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -370,3 +374,318 @@ func Test_GetPageParamsMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func Test_AdminAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		wantStatusCode int
+		expectRes      string
+	}{
+		{
+			name:           "accept request with matching token",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			wantStatusCode: http.StatusOK,
+			expectRes:      "passed",
+		},
+		{
+			name:           "reject request with wrong token",
+			token:          "secret",
+			authorization:  "Bearer wrong",
+			wantStatusCode: http.StatusUnauthorized,
+			expectRes:      `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "reject request without token",
+			token:          "secret",
+			authorization:  "",
+			wantStatusCode: http.StatusUnauthorized,
+			expectRes:      `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "reject all requests if token is empty",
+			token:          "",
+			authorization:  "Bearer ",
+			wantStatusCode: http.StatusServiceUnavailable,
+			expectRes:      `{"error":"admin token not set"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := AdminAuthMiddleware(test.token)(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprintln(w, "passed")
+				},
+			))
+
+			req, err := http.NewRequest("GET", "", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.expectRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_MetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	requestCount := func(method, route, status string) uint64 {
+		var m dto.Metric
+		metrics.APIRequestDuration.WithLabelValues(method, route, status).(prometheus.Histogram).Write(&m)
+
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	tests := []struct {
+		name       string
+		url        string
+		wantRoute  string
+		wantStatus string
+	}{
+		{
+			name:       "report route pattern instead of path",
+			url:        "/metrics-test/books/123",
+			wantRoute:  "/metrics-test/books/{id}",
+			wantStatus: "200",
+		},
+		{
+			name:       "report status written by handler",
+			url:        "/metrics-test/not-found",
+			wantRoute:  "/metrics-test/not-found",
+			wantStatus: "404",
+		},
+		{
+			name:       "report unmatched route",
+			url:        "/metrics-test/unknown",
+			wantRoute:  "unmatched",
+			wantStatus: "404",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := chi.NewRouter()
+			r.Use(MetricsMiddleware)
+			r.Get("/metrics-test/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "passed")
+			})
+			r.Get("/metrics-test/not-found", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+
+			before := requestCount("GET", test.wantRoute, test.wantStatus)
+
+			req := httptest.NewRequest("GET", test.url, nil)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, before+1, requestCount("GET", test.wantRoute, test.wantStatus))
+		})
+	}
+}
+
+func Test_GetJobRunMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		setupServ      func(ctrl *gomock.Controller) service.Service
+		runID          string
+		wantStatusCode int
+		wantRes        string
+	}{
+		{
+			name: "set request context job run for existing id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().JobRun(gomock.Any(), 1).Return(&model.JobRun{ID: 1}, nil)
+
+				return serv
+			},
+			runID:          "1",
+			wantStatusCode: http.StatusOK,
+			wantRes:        "1",
+		},
+		{
+			name: "return not found for not exist id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().JobRun(gomock.Any(), 2).Return(nil, fmt.Errorf("fail to get job run: %w", repo.ErrJobRunNotExist))
+
+				return serv
+			},
+			runID:          "2",
+			wantStatusCode: http.StatusNotFound,
+			wantRes:        `{"error":"job run not found"}`,
+		},
+		{
+			name: "return bad request for other error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().JobRun(gomock.Any(), 3).Return(nil, errors.New("some error"))
+				serv.EXPECT().Name().Return("test")
+
+				return serv
+			},
+			runID:          "3",
+			wantStatusCode: http.StatusBadRequest,
+			wantRes:        `{"error":"some error"}`,
+		},
+		{
+			name: "return bad request for invalid id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				return mockservice.NewMockService(ctrl)
+			},
+			runID:          "abc",
+			wantStatusCode: http.StatusBadRequest,
+			wantRes:        `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := GetJobRunMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					run := r.Context().Value(JOB_RUN_KEY).(*model.JobRun)
+					fmt.Fprintln(w, run.ID)
+				},
+			))
+
+			req, err := http.NewRequest("GET", "", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("runID", test.runID)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
+
+func Test_GetJobMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		setupServ      func(ctrl *gomock.Controller) service.Service
+		jobID          string
+		wantStatusCode int
+		wantRes        string
+	}{
+		{
+			name: "set request context job for existing id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Job(gomock.Any(), 1).Return(&model.Job{ID: 1}, nil)
+
+				return serv
+			},
+			jobID:          "1",
+			wantStatusCode: http.StatusOK,
+			wantRes:        "1",
+		},
+		{
+			name: "return not found for not exist id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Job(gomock.Any(), 2).Return(nil, fmt.Errorf("fail to get job: %w", repo.ErrJobNotExist))
+
+				return serv
+			},
+			jobID:          "2",
+			wantStatusCode: http.StatusNotFound,
+			wantRes:        `{"error":"job not found"}`,
+		},
+		{
+			name: "return bad request for other error",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				serv := mockservice.NewMockService(ctrl)
+				serv.EXPECT().Job(gomock.Any(), 3).Return(nil, errors.New("some error"))
+				serv.EXPECT().Name().Return("test")
+
+				return serv
+			},
+			jobID:          "3",
+			wantStatusCode: http.StatusBadRequest,
+			wantRes:        `{"error":"some error"}`,
+		},
+		{
+			name: "return bad request for invalid id",
+			setupServ: func(ctrl *gomock.Controller) service.Service {
+				return mockservice.NewMockService(ctrl)
+			},
+			jobID:          "abc",
+			wantStatusCode: http.StatusBadRequest,
+			wantRes:        `{"error":"invalid params"}`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			handler := GetJobMiddleware(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					job := r.Context().Value(JOB_KEY).(*model.Job)
+					fmt.Fprintln(w, job.ID)
+				},
+			))
+
+			req, err := http.NewRequest("GET", "", nil)
+			if err != nil {
+				t.Errorf("cannot init request: %v", err)
+				return
+			}
+
+			ctx := context.WithValue(req.Context(), SERV_KEY, test.setupServ(ctrl))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("jobID", test.jobID)
+			ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+			req = req.WithContext(ctx)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, test.wantStatusCode, res.Code)
+			assert.Equal(t, test.wantRes, strings.Trim(res.Body.String(), "\n"))
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Package schedule parse the standard cron expressions and run the scheduled
+// operations of sites. it does not support seconds field, and all schedules
+// are evaluated in the location of given time
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// maxSearchYears limit the search of next run time, so expression that never
+// matches (e.g. 30th of February) does not loop forever
+const maxSearchYears = 5
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted as sunday and folded to 0 after parsing
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron is a parsed cron expression in format of
+// `<minute> <hour> <day of month> <month> <day of week>`. each field is a
+// bitset of the matched values
+type Cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	// day is matched if either day of month or day of week matched when both
+	// of them are restricted, as the standard cron does
+	domStar, dowStar bool
+}
+
+func Parse(expr string) (*Cron, error) {
+	expr = strings.TrimSpace(expr)
+
+	spec := expr
+	if descriptor, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q has %d fields instead of 5", ErrInvalidCron, expr, len(fields))
+	}
+
+	cron := &Cron{
+		expr:    expr,
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+
+	var err error
+	for _, parse := range []struct {
+		bits  *uint64
+		value string
+		field field
+	}{
+		{&cron.minute, fields[0], minuteField},
+		{&cron.hour, fields[1], hourField},
+		{&cron.dom, fields[2], domField},
+		{&cron.month, fields[3], monthField},
+		{&cron.dow, fields[4], dowField},
+	} {
+		*parse.bits, err = parseField(parse.value, parse.field)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidCron, expr, err)
+		}
+	}
+
+	if cron.dow&(1<<7) != 0 {
+		cron.dow = cron.dow&^(1<<7) | 1
+	}
+
+	return cron, nil
+}
+
+// parseField parse comma separated list of `*`, `<n>` or `<from>-<to>`, each
+// can be followed by `/<step>`
+func parseField(value string, f field) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(value, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q of %s", stepExpr, f.name)
+			}
+		}
+
+		from, to := f.min, f.max
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			fromExpr, toExpr, _ := strings.Cut(rangeExpr, "-")
+
+			var err error
+			if from, err = f.value(fromExpr); err != nil {
+				return 0, err
+			}
+
+			if to, err = f.value(toExpr); err != nil {
+				return 0, err
+			}
+
+			if from > to {
+				return 0, fmt.Errorf("invalid range %q of %s", rangeExpr, f.name)
+			}
+		default:
+			var err error
+			if from, err = f.value(rangeExpr); err != nil {
+				return 0, err
+			}
+
+			// `<n>/<step>` starts from n to the max value
+			if !hasStep {
+				to = from
+			}
+		}
+
+		for i := from; i <= to; i += step {
+			bits |= 1 << i
+		}
+	}
+
+	return bits, nil
+}
+
+func (f field) value(expr string) (int, error) {
+	if n, ok := f.names[strings.ToLower(expr)]; ok {
+		return n, nil
+	}
+
+	n, err := strconv.Atoi(expr)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s %q out of range %d-%d", f.name, expr, f.min, f.max)
+	}
+
+	return n, nil
+}
+
+func (cron *Cron) String() string {
+	return cron.expr
+}
+
+func (cron *Cron) matchDay(t time.Time) bool {
+	domMatch := cron.dom&(1<<t.Day()) != 0
+	dowMatch := cron.dow&(1<<t.Weekday()) != 0
+
+	if cron.domStar || cron.dowStar {
+		return domMatch && dowMatch
+	}
+
+	return domMatch || dowMatch
+}
+
+// Next return the first time matching the expression after t. zero time is
+// returned if the expression never matches
+func (cron *Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if cron.month&(1<<t.Month()) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if !cron.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if cron.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+
+		if cron.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// NextN return the next n times matching the expression after t
+func (cron *Cron) NextN(t time.Time, n int) []time.Time {
+	result := make([]time.Time, 0, n)
+
+	for len(result) < n {
+		t = cron.Next(t)
+		if t.IsZero() {
+			break
+		}
+
+		result = append(result, t)
+	}
+
+	return result
+}
@@ -0,0 +1,183 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		expr      string
+		wantError error
+	}{
+		{name: "every minute", expr: "* * * * *"},
+		{name: "lists, ranges and steps", expr: "0,30 1-5/2 */10 1-12 mon-fri"},
+		{name: "names in upper case", expr: "0 0 * JAN SUN"},
+		{name: "sunday as 7", expr: "0 0 * * 7"},
+		{name: "descriptor", expr: "@daily"},
+		{name: "too few fields", expr: "* * * *", wantError: ErrInvalidCron},
+		{name: "too many fields", expr: "0 * * * * *", wantError: ErrInvalidCron},
+		{name: "value out of range", expr: "60 * * * *", wantError: ErrInvalidCron},
+		{name: "reversed range", expr: "* 5-1 * * *", wantError: ErrInvalidCron},
+		{name: "invalid step", expr: "*/0 * * * *", wantError: ErrInvalidCron},
+		{name: "unknown name", expr: "* * * foo *", wantError: ErrInvalidCron},
+		{name: "unknown descriptor", expr: "@every 1h", wantError: ErrInvalidCron},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cron, err := Parse(test.expr)
+			assert.ErrorIs(t, err, test.wantError)
+			if test.wantError == nil {
+				assert.Equal(t, test.expr, cron.String())
+			}
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	t.Parallel()
+
+	// 2020-01-01 is wednesday
+	from := time.Date(2020, 1, 1, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+			from: from,
+			want: time.Date(2020, 1, 1, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "hourly",
+			expr: "@hourly",
+			from: from,
+			want: time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "daily at later hour of the same day",
+			expr: "15 20 * * *",
+			from: from,
+			want: time.Date(2020, 1, 1, 20, 15, 0, 0, time.UTC),
+		},
+		{
+			name: "daily at passed hour",
+			expr: "0 3 * * *",
+			from: from,
+			want: time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "exact match time is excluded",
+			expr: "30 10 * * *",
+			from: time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC),
+			want: time.Date(2020, 1, 2, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "weekly on sunday as 7",
+			expr: "0 0 * * 7",
+			from: from,
+			want: time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week if both restricted",
+			expr: "0 0 15 * fri",
+			from: from,
+			want: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month and month",
+			expr: "0 0 29 feb *",
+			from: from,
+			want: time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "step from value",
+			expr: "45/5 * * * *",
+			from: from,
+			want: time.Date(2020, 1, 1, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "cross year",
+			expr: "0 0 1 1 *",
+			from: from,
+			want: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never match",
+			expr: "0 0 30 2 *",
+			from: from,
+			want: time.Time{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cron, err := Parse(test.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, test.want, cron.Next(test.from))
+		})
+	}
+}
+
+func TestCron_NextN(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		n    int
+		want []time.Time
+	}{
+		{
+			name: "works",
+			expr: "0 */12 * * *",
+			n:    3,
+			want: []time.Time{
+				time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+				time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "never match",
+			expr: "0 0 31 4 *",
+			n:    3,
+			want: []time.Time{},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cron, err := Parse(test.expr)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, test.want, cron.NextN(from, test.n))
+		})
+	}
+}
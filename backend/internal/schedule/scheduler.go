@@ -0,0 +1,157 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/metrics"
+	"github.com/rs/zerolog"
+)
+
+const (
+	RunStarted = "started"
+	RunSkipped = "skipped"
+)
+
+// ErrSkipped is returned by Run of entry if it does not run the operation,
+// e.g. other operation of the site is running
+var ErrSkipped = errors.New("scheduled run skipped")
+
+// Entry is an operation of site run on the cron schedule
+type Entry struct {
+	Site      string
+	Operation string
+	Cron      *Cron
+	Run       func(context.Context) error
+}
+
+type entry struct {
+	Entry
+	next    time.Time
+	running atomic.Bool
+}
+
+// Scheduler run the entries on their schedules. a run is skipped if the
+// previous run of the same entry has not returned yet, so a slow operation is
+// never run twice on the same site at the same time by this scheduler. runs of
+// other workers are guarded by the entries, which return ErrSkipped if the
+// operation is running elsewhere
+type Scheduler struct {
+	entries []*entry
+	now     func() time.Time
+	wg      sync.WaitGroup
+}
+
+func NewScheduler(entries ...Entry) *Scheduler {
+	s := &Scheduler{now: func() time.Time { return time.Now().UTC() }}
+	for _, e := range entries {
+		s.entries = append(s.entries, &entry{Entry: e})
+	}
+
+	return s
+}
+
+// Run trigger the entries until ctx is done, then wait for the running ones
+// to return. the context passed to the entries is ctx, so they are expected
+// to stop once ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+
+	now := s.now()
+	for _, e := range s.entries {
+		e.next = e.Cron.Next(now)
+		logger.Info().
+			Str("site", e.Site).
+			Str("operation", e.Operation).
+			Str("cron", e.Cron.String()).
+			Time("next_run", e.next).
+			Msg("schedule operation")
+	}
+
+	for ctx.Err() == nil {
+		next, ok := s.nextRunTime()
+		if !ok {
+			logger.Warn().Msg("no operation is scheduled")
+			<-ctx.Done()
+
+			break
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			continue
+		case <-timer.C:
+		}
+
+		now := s.now()
+		for _, e := range s.entries {
+			if e.next.IsZero() || e.next.After(now) {
+				continue
+			}
+
+			s.trigger(ctx, e)
+			e.next = e.Cron.Next(now)
+		}
+	}
+
+	s.wg.Wait()
+}
+
+// nextRunTime return the earliest next run time of all entries. entries never
+// matching their cron expression are ignored
+func (s *Scheduler) nextRunTime() (time.Time, bool) {
+	var next time.Time
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			continue
+		}
+
+		if next.IsZero() || e.next.Before(next) {
+			next = e.next
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// trigger run the entry in background unless its previous run is not
+// completed. it returns false if the run is skipped
+func (s *Scheduler) trigger(ctx context.Context, e *entry) bool {
+	logger := zerolog.Ctx(ctx).With().
+		Str("site", e.Site).
+		Str("operation", e.Operation).
+		Logger()
+
+	if !e.running.CompareAndSwap(false, true) {
+		logger.Warn().Msg("skip scheduled run as previous run is not completed")
+		metrics.ScheduledRuns.WithLabelValues(e.Site, e.Operation, RunSkipped).Inc()
+
+		return false
+	}
+
+	metrics.ScheduledRuns.WithLabelValues(e.Site, e.Operation, RunStarted).Inc()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer e.running.Store(false)
+
+		logger.Info().Msg("start scheduled run")
+		err := e.Run(logger.WithContext(ctx))
+		if errors.Is(err, ErrSkipped) {
+			logger.Warn().Err(err).Msg("skip scheduled run")
+			metrics.ScheduledRuns.WithLabelValues(e.Site, e.Operation, RunSkipped).Inc()
+		} else if err != nil {
+			logger.Error().Err(err).Msg("scheduled run failed")
+		} else {
+			logger.Info().Msg("completed scheduled run")
+		}
+	}()
+
+	return true
+}
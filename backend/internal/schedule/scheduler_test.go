@@ -0,0 +1,138 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_Run(t *testing.T) {
+	t.Parallel()
+
+	cron, err := Parse("* * * * *")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int64
+	s := NewScheduler(Entry{
+		Site:      "test",
+		Operation: "update",
+		Cron:      cron,
+		Run: func(ctx context.Context) error {
+			if runs.Add(1) >= 3 {
+				cancel()
+			}
+
+			return nil
+		},
+	})
+
+	// every call of now moves forward by a minute, so the entry is always due
+	var mu sync.Mutex
+	current := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		current = current.Add(time.Minute)
+
+		return current
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler not stopped after context is cancelled")
+	}
+
+	assert.GreaterOrEqual(t, runs.Load(), int64(3))
+}
+
+func TestScheduler_Run_NoEntry(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		NewScheduler().Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler not stopped after context is cancelled")
+	}
+}
+
+func TestScheduler_trigger(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var runs atomic.Int64
+
+	s := NewScheduler(Entry{
+		Site:      "test",
+		Operation: "download",
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			<-release
+
+			return nil
+		},
+	})
+	e := s.entries[0]
+
+	assert.True(t, s.trigger(context.Background(), e))
+	assert.False(t, s.trigger(context.Background(), e), "overlapped run should be skipped")
+
+	close(release)
+	s.wg.Wait()
+
+	assert.True(t, s.trigger(context.Background(), e), "run should be triggered after previous run completed")
+	s.wg.Wait()
+
+	assert.Equal(t, int64(2), runs.Load())
+}
+
+func TestScheduler_trigger_Skipped(t *testing.T) {
+	t.Parallel()
+
+	var runs atomic.Int64
+
+	s := NewScheduler(Entry{
+		Site:      "test",
+		Operation: "update",
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+
+			return fmt.Errorf("%w: site is busy", ErrSkipped)
+		},
+	})
+	e := s.entries[0]
+
+	assert.True(t, s.trigger(context.Background(), e))
+	s.wg.Wait()
+
+	assert.True(t, s.trigger(context.Background(), e), "skipped run should not block next run")
+	s.wg.Wait()
+
+	assert.Equal(t, int64(2), runs.Load())
+}
@@ -12,4 +12,9 @@ var (
 	ErrInvalidBookID         = errors.New("invalid book id")
 	ErrInvalidHashCode       = errors.New("invalid hash code")
 	ErrTooManyFailedChapters = errors.New("too many failed chapters")
+	ErrUnknownOperation      = errors.New("unknown operation")
+	ErrBookTaskLeaseExpired  = errors.New("book task lease expired in all attempts")
+	ErrJobLeaseExpired       = errors.New("job lease expired in all attempts")
+	ErrOperationRunning      = errors.New("operation is running by other worker")
+	ErrKeywordTooShort       = errors.New("keyword is too short")
 )
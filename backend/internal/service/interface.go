@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"sync/atomic"
+	"time"
 
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
 	"github.com/htchan/BookSpider/internal/model"
 	"github.com/htchan/BookSpider/internal/repo"
 )
@@ -31,6 +33,8 @@ type DownloadStats struct {
 	NoChapter           atomic.Int64
 	TooManyFailChapters atomic.Int64
 	RequestFail         atomic.Int64
+	FetchedChapters     atomic.Int64
+	ResumedChapters     atomic.Int64
 }
 
 type PatchStorageStats struct {
@@ -61,14 +65,36 @@ type Service interface {
 	ProcessBook(context.Context, *model.Book) error
 	Process(context.Context) error
 
+	PatchBookChapters(context.Context, *model.Book) error // load chapters of downloaded book from storage
+	PatchChapters(context.Context) error
+
 	BookInfo(context.Context, *model.Book) string
 	BookContent(context.Context, *model.Book) (string, error)
 	BookChapters(context.Context, *model.Book) (model.Chapters, error)
 	Book(ctx context.Context, id, hash string) (*model.Book, error)
 	BookGroup(ctx context.Context, id, hash string) (*model.Book, *model.BookGroup, error)
+	BookWork(context.Context, *model.Book) (*model.Work, error)
 	QueryBooks(ctx context.Context, title, writer string, limit, offset int) ([]model.Book, error)
 	RandomBooks(ctx context.Context, limit int) ([]model.Book, error)
+	SearchContent(ctx context.Context, keyword string, limit, offset int) ([]model.ContentMatch, error)
 
 	Stats(context.Context) repo.Summary
 	DBStats(context.Context) sql.DBStats
+
+	JobRuns(ctx context.Context, operation string, limit, offset int) ([]model.JobRun, error)
+	JobRun(ctx context.Context, id int) (*model.JobRun, error)
+
+	EnqueueJob(ctx context.Context, operation string, bk *model.Book) (*model.Job, error) // enqueue site job if bk is nil
+	Job(ctx context.Context, id int) (*model.Job, error)
+	RunQueuedJobs(context.Context) error
+
+	RunOperation(ctx context.Context, operation string) error
+	Schedules(ctx context.Context, from time.Time, count int) ([]model.Schedule, error)
+
+	BookTasks(ctx context.Context, operation string, status model.BookTaskStatus, limit, offset int) ([]model.BookTask, error)
+	RequeueBookTask(ctx context.Context, id int) (*model.BookTask, error) // requeue dead letter task
+
+	CircuitBreakerState() circuitbreaker.State
+	ForceOpenCircuitBreaker(reason string)
+	ForceCloseCircuitBreaker(reason string)
 }
@@ -0,0 +1,36 @@
+package service
+
+// Snapshot return the current values of stats, which is stored with job run
+// and reported to metrics
+func (stats *UpdateStats) Snapshot() map[string]int64 {
+	return map[string]int64{
+		"total":               stats.Total.Load(),
+		"fail":                stats.Fail.Load(),
+		"unchanged":           stats.Unchanged.Load(),
+		"new_chapter":         stats.NewChapter.Load(),
+		"new_entity":          stats.NewEntity.Load(),
+		"error_updated":       stats.ErrorUpdated.Load(),
+		"in_progress_updated": stats.InProgressUpdated.Load(),
+		"end_updated":         stats.EndUpdated.Load(),
+		"downloaded_updated":  stats.DownloadedUpdated.Load(),
+	}
+}
+
+func (stats *DownloadStats) Snapshot() map[string]int64 {
+	return map[string]int64{
+		"total":                  stats.Total.Load(),
+		"success":                stats.Success.Load(),
+		"no_chapter":             stats.NoChapter.Load(),
+		"too_many_fail_chapters": stats.TooManyFailChapters.Load(),
+		"request_fail":           stats.RequestFail.Load(),
+		"fetched_chapters":       stats.FetchedChapters.Load(),
+		"resumed_chapters":       stats.ResumedChapters.Load(),
+	}
+}
+
+func (stats *PatchStorageStats) Snapshot() map[string]int64 {
+	return map[string]int64{
+		"file_exist":   stats.FileExist.Load(),
+		"file_missing": stats.FileMissing.Load(),
+	}
+}
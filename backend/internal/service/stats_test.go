@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateStats_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	stats := new(UpdateStats)
+	stats.Total.Add(3)
+	stats.Fail.Add(1)
+	stats.NewEntity.Add(2)
+
+	assert.Equal(t, map[string]int64{
+		"total":               3,
+		"fail":                1,
+		"unchanged":           0,
+		"new_chapter":         0,
+		"new_entity":          2,
+		"error_updated":       0,
+		"in_progress_updated": 0,
+		"end_updated":         0,
+		"downloaded_updated":  0,
+	}, stats.Snapshot())
+}
+
+func TestDownloadStats_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	stats := new(DownloadStats)
+	stats.Total.Add(2)
+	stats.Success.Add(1)
+	stats.FetchedChapters.Add(10)
+
+	assert.Equal(t, map[string]int64{
+		"total":                  2,
+		"success":                1,
+		"no_chapter":             0,
+		"too_many_fail_chapters": 0,
+		"request_fail":           0,
+		"fetched_chapters":       10,
+		"resumed_chapters":       0,
+	}, stats.Snapshot())
+}
+
+func TestPatchStorageStats_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	stats := new(PatchStorageStats)
+	stats.FileMissing.Add(1)
+
+	assert.Equal(t, map[string]int64{"file_exist": 0, "file_missing": 1}, stats.Snapshot())
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"unicode/utf8"
 
 	"github.com/htchan/BookSpider/internal/model"
 	serv "github.com/htchan/BookSpider/internal/service"
@@ -43,12 +44,21 @@ func (s *ServiceImpl) BookContent(ctx context.Context, bk *model.Book) (string,
 }
 
 func (s *ServiceImpl) BookChapters(ctx context.Context, bk *model.Book) (model.Chapters, error) {
+	// chapters of in progress books are only available in repository
+	chapters, err := s.rpo.FindChaptersByBook(bk)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("find chapters from repository failed")
+	} else if len(chapters) > 0 {
+		return chapters, nil
+	}
+
+	// books downloaded before chapters were persisted only exist as txt file
 	content, err := s.BookContent(ctx, bk)
 	if err != nil {
 		return nil, fmt.Errorf("load content failed: %w", err)
 	}
 
-	chapters, err := model.StringToChapters(content)
+	chapters, err = model.StringToChapters(content)
 	if err != nil {
 		return nil, fmt.Errorf("parse chapter failed: %w", err)
 	}
@@ -139,12 +149,51 @@ func (s *ServiceImpl) BookGroup(
 	return &bk, &group, nil
 }
 
+// BookWork find the books of all sites sharing the checksums of bk. bk is
+// always one of the sources, even if it cannot be matched by checksum
+func (s *ServiceImpl) BookWork(ctx context.Context, bk *model.Book) (*model.Work, error) {
+	group, err := s.rpo.FindWorkByChecksum(bk.Checksum(), bk.Writer.Checksum())
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for _, source := range group {
+		if source.Site == bk.Site && source.ID == bk.ID && source.HashCode == bk.HashCode {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		group = append(group, *bk)
+	}
+
+	return model.NewWork(group)
+}
+
 func (s *ServiceImpl) QueryBooks(
 	ctx context.Context, title, writer string, limit, offset int,
 ) ([]model.Book, error) {
-	return s.rpo.FindBooksByTitleWriter(title, writer, limit, offset)
+	return s.rpo.SearchBooks(title, writer, limit, offset)
 }
 
 func (s *ServiceImpl) RandomBooks(ctx context.Context, limit int) ([]model.Book, error) {
 	return s.rpo.FindBooksByRandom(limit)
 }
+
+// minContentSearchKeywordLength is the shortest keyword served by the trigram
+// index of chapter content, shorter keyword would scan all chapters
+const minContentSearchKeywordLength = 3
+
+// SearchContent search the keyword in chapters stored in database, books
+// downloaded before chapters were stored are searchable after patch-chapters
+func (s *ServiceImpl) SearchContent(
+	ctx context.Context, keyword string, limit, offset int,
+) ([]model.ContentMatch, error) {
+	if utf8.RuneCountInString(keyword) < minContentSearchKeywordLength {
+		return nil, fmt.Errorf("search content fail: %w", serv.ErrKeywordTooShort)
+	}
+
+	return s.rpo.SearchChapterContent(keyword, limit, offset)
+}
@@ -119,16 +119,68 @@ func TestServiceImpl_BookChapters(t *testing.T) {
 	}
 
 	tests := []struct {
-		name      string
-		serv      *ServiceImpl
-		bk        *model.Book
-		want      model.Chapters
-		wantError error
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		bk         *model.Book
+		want       model.Chapters
+		wantError  error
 	}{
+		{
+			name: "successfully load chapters of in progress book from repository",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 789, Status: model.StatusInProgress}).Return(model.Chapters{
+					{Index: 0, Title: "db title 1", Content: "db content 1"},
+				}, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
+			bk: &model.Book{ID: 789, Status: model.StatusInProgress},
+			want: model.Chapters{
+				{Index: 0, Title: "db title 1", Content: "db content 1"},
+			},
+			wantError: nil,
+		},
+		{
+			name: "successfully load chapters from repository",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(model.Chapters{
+					{Index: 0, Title: "db title 1", Content: "db content 1"},
+				}, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
+			bk: &model.Book{ID: 123, IsDownloaded: true},
+			want: model.Chapters{
+				{Index: 0, Title: "db title 1", Content: "db content 1"},
+			},
+			wantError: nil,
+		},
 		{
 			name: "successfully read and parse content to chapters",
-			serv: &ServiceImpl{conf: config.SiteConfig{Storage: "./book-chapters"}},
-			bk:   &model.Book{ID: 123, IsDownloaded: true},
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
+			bk: &model.Book{ID: 123, IsDownloaded: true},
+			want: model.Chapters{
+				{Index: 0, Title: "title 1", Content: "content 1"},
+				{Index: 1, Title: "title 2", Content: "content 2"},
+			},
+			wantError: nil,
+		},
+		{
+			name: "fall back to content if repository fail",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
+			bk: &model.Book{ID: 123, IsDownloaded: true},
 			want: model.Chapters{
 				{Index: 0, Title: "title 1", Content: "content 1"},
 				{Index: 1, Title: "title 2", Content: "content 2"},
@@ -136,15 +188,37 @@ func TestServiceImpl_BookChapters(t *testing.T) {
 			wantError: nil,
 		},
 		{
-			name:      "fail to parse content to chapters",
-			serv:      &ServiceImpl{conf: config.SiteConfig{Storage: "./book-chapters"}},
+			name: "book not downloaded",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: false}).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
+			bk:        &model.Book{ID: 123, IsDownloaded: false},
+			want:      nil,
+			wantError: serv.ErrBookNotDownload,
+		},
+		{
+			name: "fail to parse content to chapters",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, HashCode: 10, IsDownloaded: true}).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
 			bk:        &model.Book{ID: 123, HashCode: 10, IsDownloaded: true},
 			want:      nil,
 			wantError: model.ErrCannotParseContent,
 		},
 		{
-			name:      "fail to read content ",
-			serv:      &ServiceImpl{conf: config.SiteConfig{Storage: "./book-chapters"}},
+			name: "fail to read content ",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 456, HashCode: 0, IsDownloaded: true}).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./book-chapters"}}
+			},
 			bk:        &model.Book{ID: 456, HashCode: 0, IsDownloaded: true},
 			want:      nil,
 			wantError: serv.ErrBookFileNotFound,
@@ -156,7 +230,10 @@ func TestServiceImpl_BookChapters(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			t.Parallel()
 
-			got, err := test.serv.BookChapters(context.Background(), test.bk)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			got, err := test.getService(ctrl).BookChapters(context.Background(), test.bk)
 			assert.Equal(t, test.want, got)
 			assert.ErrorIs(t, err, test.wantError)
 		})
@@ -358,6 +435,87 @@ func TestServiceImpl_BookGroup(t *testing.T) {
 	}
 }
 
+func TestServiceImpl_BookWork(t *testing.T) {
+	t.Parallel()
+
+	bk := model.Book{
+		Site: "test", ID: 1, HashCode: 100, Title: "title 1",
+		Writer: model.Writer{Name: "writer 1"}, Status: model.StatusInProgress,
+	}
+	otherBk := model.Book{
+		Site: "other", ID: 2, HashCode: 100, Title: "title 1",
+		Writer: model.Writer{Name: "writer 1"}, Status: model.StatusEnd, IsDownloaded: true,
+	}
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		bk         *model.Book
+		want       *model.Work
+		wantError  error
+	}{
+		{
+			name: "build work from books of all sites",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkByChecksum("dGl0bGUx", "d3JpdGVyMQ==").
+					Return(model.BookGroup{otherBk, bk}, nil)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk: &bk,
+			want: &model.Work{
+				Checksum: "dGl0bGUx", WriterChecksum: "d3JpdGVyMQ==",
+				Canonical: otherBk, Sources: model.BookGroup{otherBk, bk},
+			},
+			wantError: nil,
+		},
+		{
+			name: "include book not matched by checksum",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkByChecksum("dGl0bGUx", "d3JpdGVyMQ==").
+					Return(model.BookGroup{}, nil)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk: &bk,
+			want: &model.Work{
+				Checksum: "dGl0bGUx", WriterChecksum: "d3JpdGVyMQ==",
+				Canonical: bk, Sources: model.BookGroup{bk},
+			},
+			wantError: nil,
+		},
+		{
+			name: "fail to find work",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindWorkByChecksum("dGl0bGUx", "d3JpdGVyMQ==").
+					Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			bk:        &bk,
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			got, err := test.getService(ctrl).BookWork(context.Background(), test.bk)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
 func TestServiceImpl_QueryBooks(t *testing.T) {
 	t.Parallel()
 
@@ -375,7 +533,7 @@ func TestServiceImpl_QueryBooks(t *testing.T) {
 			name: "happy flow with books",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksByTitleWriter("title", "writer", 10, 0).
+				rpo.EXPECT().SearchBooks("title", "writer", 10, 0).
 					Return([]model.Book{{ID: 123, HashCode: 0}}, nil)
 
 				return &ServiceImpl{rpo: rpo}
@@ -387,6 +545,22 @@ func TestServiceImpl_QueryBooks(t *testing.T) {
 			want:      []model.Book{{ID: 123, HashCode: 0}},
 			wantError: nil,
 		},
+		{
+			name: "search books fail",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SearchBooks("title", "writer", 10, 0).
+					Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			title:     "title",
+			writer:    "writer",
+			limit:     10,
+			offset:    0,
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
 	}
 
 	for _, test := range tests {
@@ -448,3 +622,89 @@ func TestServiceImpl_RandomBooks(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceImpl_SearchContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		keyword    string
+		limit      int
+		offset     int
+		want       []model.ContentMatch
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SearchChapterContent("keyword", 10, 0).Return(
+					[]model.ContentMatch{{Book: model.Book{ID: 123}, ChapterIndex: 1}}, nil,
+				)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			keyword:   "keyword",
+			limit:     10,
+			offset:    0,
+			want:      []model.ContentMatch{{Book: model.Book{ID: 123}, ChapterIndex: 1}},
+			wantError: nil,
+		},
+		{
+			name: "search chapter content fail",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SearchChapterContent("keyword", 10, 0).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			keyword:   "keyword",
+			limit:     10,
+			offset:    0,
+			want:      nil,
+			wantError: serv.ErrUnavailable,
+		},
+		{
+			name: "keyword shorter than 3 characters",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{rpo: mockrepo.NewMockRepository(ctrl)}
+			},
+			keyword:   "關鍵",
+			limit:     10,
+			offset:    0,
+			want:      nil,
+			wantError: serv.ErrKeywordTooShort,
+		},
+		{
+			name: "keyword of 3 characters",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().SearchChapterContent("關鍵字", 10, 0).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo}
+			},
+			keyword:   "關鍵字",
+			limit:     10,
+			offset:    0,
+			want:      nil,
+			wantError: nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := test.getService(ctrl)
+
+			got, err := svc.SearchContent(context.Background(), test.keyword, test.limit, test.offset)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/model"
 	serv "github.com/htchan/BookSpider/internal/service"
 	vendor "github.com/htchan/BookSpider/internal/vendorservice"
@@ -32,14 +34,16 @@ func (s *ServiceImpl) UpdateBook(ctx context.Context, bk *model.Book, stats *ser
 		stats = new(serv.UpdateStats)
 	}
 
-	body, err := s.cli.Get(ctx, s.vendorService.BookURL(strconv.FormatInt(int64(bk.ID), 10)))
+	bookURL := s.vendorService.BookURL(strconv.FormatInt(int64(bk.ID), 10))
+	res, err := s.fetch(ctx, client.URLClassBook, bookURL)
 	if err != nil {
 		stats.Fail.Add(1)
 		return fmt.Errorf("get book page failed: %w", err)
 	}
 
-	bkInfo, err := s.vendorService.ParseBook(body)
+	bkInfo, err := s.vendorService.ParseBook(res.Body)
 	if err != nil {
+		s.reportParseFailure(ctx, client.URLClassBook, bookURL, res, err)
 		stats.Fail.Add(1)
 		return fmt.Errorf("parse book page failed: %w", err)
 	}
@@ -126,46 +130,27 @@ func (s *ServiceImpl) UpdateBook(ctx context.Context, bk *model.Book, stats *ser
 	return nil
 }
 
+// Update run the update tasks of all books in the queue shared by workers
 func (s *ServiceImpl) Update(ctx context.Context, stats *serv.UpdateStats) error {
-	var wg sync.WaitGroup
 	if stats == nil {
 		stats = new(serv.UpdateStats)
 	}
+	defer s.reportUpdateStats("update", stats)
+
+	s.deleteFinishedBookTasks(ctx)
 
-	bkChan, err := s.rpo.FindBooksForUpdate()
+	count, err := s.rpo.EnqueueUpdateBookTasks()
 	if err != nil {
-		return fmt.Errorf("fail to load books from DB: %w", err)
+		return fmt.Errorf("fail to enqueue books: %w", err)
 	}
 
-	for bk := range bkChan {
-		bk := bk
-		s.sema.Acquire(ctx, 1)
-		wg.Add(1)
-		stats.Total.Add(1)
-
-		go func(bk *model.Book) {
-			defer wg.Done()
-			defer s.sema.Release(1)
+	zerolog.Ctx(ctx).Info().Int("enqueued_count", count).Msg("enqueue update book tasks")
 
-			logger := zerolog.Ctx(ctx).With().
-				Int("bk_id", bk.ID).
-				Str("bk_hash_code", bk.FormatHashCode()).
-				Str("worker_id", uuid.New().String()).
-				Logger()
-			err := s.UpdateBook(logger.WithContext(ctx), bk, stats)
-			if err != nil {
-				logger.Error().Err(err).
-					Msg("update book failed")
-			}
-		}(&bk)
-
-		// give chance to others service running at the same time
-		time.Sleep(time.Millisecond)
-	}
-
-	wg.Wait()
+	return s.runBookTasks(ctx, model.JobOperationUpdate, nil, func(ctx context.Context, bk *model.Book) error {
+		stats.Total.Add(1)
 
-	return nil
+		return s.UpdateBook(ctx, bk, stats)
+	})
 }
 
 func (s *ServiceImpl) ExploreBook(ctx context.Context, bk *model.Book, stats *serv.UpdateStats) error {
@@ -180,6 +165,12 @@ func (s *ServiceImpl) ExploreBook(ctx context.Context, bk *model.Book, stats *se
 	}
 
 	err := s.UpdateBook(ctx, bk, stats)
+	// book stopped by ctx is left for the next run instead of saving the
+	// interruption as its error
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("explore book interrupted: %w", err)
+	}
+
 	if err != nil {
 		bk.Error = err
 		saveErr := s.rpo.SaveError(bk, bk.Error)
@@ -200,13 +191,17 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 	if stats == nil {
 		stats = new(serv.UpdateStats)
 	}
+	defer s.reportUpdateStats("explore", stats)
 
 	var wg sync.WaitGroup
+	var interruptErr error
 
 	for i := summary.LatestSuccessID + 1; i <= summary.MaxBookID && int(errorCount.Load()) < s.conf.MaxExploreError; i++ {
 		i := i
 
-		s.sema.Acquire(ctx, 1)
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
 		wg.Add(1)
 
 		go func(id int) {
@@ -240,10 +235,12 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 
 	wg.Wait()
 
-	for i := summary.MaxBookID + 1; int(errorCount.Load()) < s.conf.MaxExploreError; i++ {
+	for i := summary.MaxBookID + 1; interruptErr == nil && int(errorCount.Load()) < s.conf.MaxExploreError; i++ {
 		i := i
 
-		s.sema.Acquire(ctx, 1)
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
 		wg.Add(1)
 
 		go func(id int) {
@@ -273,19 +270,26 @@ func (s *ServiceImpl) Explore(ctx context.Context, stats *serv.UpdateStats) erro
 
 	wg.Wait()
 
+	if interruptErr != nil {
+		return fmt.Errorf("explore interrupted: %w", interruptErr)
+	}
+
 	return nil
 }
 
-func (s *ServiceImpl) downloadChapter(ctx context.Context, ch *model.Chapter) error {
-	body, err := s.cli.Get(ctx, ch.URL)
+func (s *ServiceImpl) downloadChapter(ctx context.Context, ch *model.Chapter) (err error) {
+	defer func() { s.reportChapterDownload(err) }()
+
+	res, err := s.fetch(ctx, client.URLClassChapter, ch.URL)
 	if err != nil {
 		ch.Error = err
 
 		return fmt.Errorf("get chapter page failed: %w", err)
 	}
 
-	chapter, err := s.vendorService.ParseChapter(body)
+	chapter, err := s.vendorService.ParseChapter(res.Body)
 	if err != nil {
+		s.reportParseFailure(ctx, client.URLClassChapter, ch.URL, res, err)
 		ch.Error = err
 
 		return fmt.Errorf("parse chapter page failed: %w", err)
@@ -298,49 +302,79 @@ func (s *ServiceImpl) downloadChapter(ctx context.Context, ch *model.Chapter) er
 	return nil
 }
 
-func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *serv.DownloadStats) error {
-	if stats == nil {
-		stats = new(serv.DownloadStats)
-	}
-
-	if bk.Status != model.StatusEnd {
-		return serv.ErrBookStatusNotEnd
-	} else if bk.IsDownloaded {
-		return serv.ErrBookAlreadyDownloaded
-	}
-
-	logger := zerolog.Ctx(ctx)
-
-	logger.Info().Msg("get chapter list")
+// fetchChapterList return the chapter list and its final url after redirects,
+// which is used as the referer of chapter requests
+func (s *ServiceImpl) fetchChapterList(ctx context.Context, bk *model.Book, stats *serv.DownloadStats) (vendor.ChapterList, string, error) {
+	zerolog.Ctx(ctx).Info().Msg("get chapter list")
 
-	body, err := s.cli.Get(ctx, s.vendorService.ChapterListURL(strconv.FormatInt(int64(bk.ID), 10)))
+	requestURL := s.vendorService.ChapterListURL(strconv.FormatInt(int64(bk.ID), 10))
+	res, err := s.fetch(ctx, client.URLClassChapterList, requestURL)
 	if err != nil {
 		stats.RequestFail.Add(1)
 
-		return fmt.Errorf("get chapter list failed: %w", err)
+		return nil, "", fmt.Errorf("get chapter list failed: %w", err)
+	}
+
+	chapterListURL := requestURL
+	if res.URL != "" {
+		chapterListURL = res.URL
 	}
 
-	chapterList, err := s.vendorService.ParseChapterList(strconv.Itoa(bk.ID), body)
+	chapterList, err := s.vendorService.ParseChapterList(strconv.Itoa(bk.ID), res.Body)
 	if err != nil {
 		if errors.Is(err, vendor.ErrChapterListEmpty) {
 			stats.NoChapter.Add(1)
 		} else {
+			s.reportParseFailure(ctx, client.URLClassChapterList, requestURL, res, err)
 			stats.RequestFail.Add(1)
 		}
 
-		return fmt.Errorf("parse chapter list failed: %w", err)
+		return nil, "", fmt.Errorf("parse chapter list failed: %w", err)
+	}
+
+	resolveChapterURLs(chapterList, chapterListURL)
+
+	return chapterList, chapterListURL, nil
+}
+
+// resolveChapterURLs resolve the relative chapter urls against the final url
+// of chapter list page
+func resolveChapterURLs(chapterList vendor.ChapterList, base string) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return
+	}
+
+	for i := range chapterList {
+		ref, err := url.Parse(chapterList[i].URL)
+		if err != nil || ref.IsAbs() {
+			continue
+		}
+
+		chapterList[i].URL = baseURL.ResolveReference(ref).String()
 	}
+}
+
+// downloadChapters fetch content of all given chapters concurrently and
+// return the number of chapters failed to download. once ctx is done, the
+// chapters not yet started are marked as failed with the context error
+func (s *ServiceImpl) downloadChapters(ctx context.Context, chapters model.Chapters) int {
+	logger := zerolog.Ctx(ctx)
 
 	logger.Info().Msg("download chapters")
-	chapters := make(model.Chapters, len(chapterList))
 	var wg sync.WaitGroup
-	failedChapterCount := 0
+	var failedChapterCount atomic.Int64
 
 	for i := range chapters {
-		i := i
-		chapters[i] = model.NewChapter(i, (chapterList)[i].URL, (chapterList)[i].Title)
+		if err := s.sema.Acquire(ctx, 1); err != nil {
+			for j := i; j < len(chapters); j++ {
+				chapters[j].Error = err
+			}
+			failedChapterCount.Add(int64(len(chapters) - i))
+
+			break
+		}
 		wg.Add(1)
-		s.sema.Acquire(ctx, 1)
 
 		go func(ch *model.Chapter) {
 			defer wg.Done()
@@ -352,7 +386,7 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 				Logger()
 			err := s.downloadChapter(chapterLogger.WithContext(ctx), ch)
 			if err != nil {
-				failedChapterCount += 1
+				failedChapterCount.Add(1)
 				chapterLogger.Error().Err(err).
 					Str("chapter_title", ch.Title).
 					Msg("download chapter failed")
@@ -362,17 +396,129 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 
 	wg.Wait()
 
-	if failedChapterCount > 50 || failedChapterCount*10 > len(chapters) {
+	return int(failedChapterCount.Load())
+}
+
+// downloadNewChapters fetch the chapters of the book which are not yet
+// fetched successfully, including the failed ones before the last fetched
+// chapter, and save them to repository
+func (s *ServiceImpl) downloadNewChapters(ctx context.Context, bk *model.Book, stats *serv.DownloadStats) error {
+	logger := zerolog.Ctx(ctx)
+
+	chapterList, chapterListURL, err := s.fetchChapterList(ctx, bk, stats)
+	if err != nil {
+		return err
+	}
+
+	storedChapters, err := s.rpo.FindChaptersByBook(bk)
+	if err != nil {
+		return fmt.Errorf("find fetched chapters fail: %w", err)
+	}
+
+	chapters := make(model.Chapters, len(chapterList))
+	for i := range chapters {
+		chapters[i] = model.NewChapter(i, chapterList[i].URL, chapterList[i].Title)
+	}
+
+	fetchedChapterCount := fillFetchedChapters(chapters, storedChapters)
+	pendingChapters := make(model.Chapters, 0, len(chapters)-fetchedChapterCount)
+	for _, chapter := range chapters {
+		if chapter.FetchedAt.IsZero() {
+			pendingChapters = append(pendingChapters, chapter)
+		}
+	}
+
+	if len(pendingChapters) == 0 {
+		logger.Info().Int("fetched_chapter_count", fetchedChapterCount).Msg("no new chapter")
+		stats.Success.Add(1)
+
+		return nil
+	}
+
+	failedChapterCount := s.downloadChapters(client.WithReferer(ctx, chapterListURL), pendingChapters)
+
+	// chapters stopped by ctx were never fetched, they are left for the next
+	// run instead of being saved as failed
+	attemptedChapters := make(model.Chapters, 0, len(pendingChapters))
+	for _, chapter := range pendingChapters {
+		if !errors.Is(chapter.Error, context.Canceled) && !errors.Is(chapter.Error, context.DeadlineExceeded) {
+			attemptedChapters = append(attemptedChapters, chapter)
+		}
+	}
+
+	logger.Info().
+		Int("fetched_chapter_count", fetchedChapterCount).
+		Int("new_chapter_count", len(pendingChapters)).
+		Int("failed_chapter_count", failedChapterCount).
+		Msg("save new chapters to repository")
+	if len(attemptedChapters) > 0 {
+		err = s.rpo.SaveChapters(bk, attemptedChapters)
+		if err != nil {
+			return fmt.Errorf("save chapters to repository fail: %w", err)
+		}
+	}
+
+	stats.FetchedChapters.Add(int64(len(pendingChapters) - failedChapterCount))
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("download new chapters interrupted: %w", ctx.Err())
+	}
+
+	if failedChapterCount > 0 {
 		stats.TooManyFailChapters.Add(1)
 
-		return fmt.Errorf("Download chapters fail: %w (%v/%v)", serv.ErrTooManyFailedChapters, failedChapterCount, len(chapters))
+		return fmt.Errorf("download new chapters fail: %w (%v/%v)", serv.ErrTooManyFailedChapters, failedChapterCount, len(pendingChapters))
 	}
 
-	logger.Info().Msg("save chapters")
-	file, err := os.Create(s.bookFileLocation(bk))
+	stats.Success.Add(1)
+
+	return nil
+}
+
+// resumeChapters fill chapters with the content checkpointed in repository
+// and return the number of chapters resumed
+func (s *ServiceImpl) resumeChapters(ctx context.Context, bk *model.Book, chapters model.Chapters) int {
+	storedChapters, err := s.rpo.FindChaptersByBook(bk)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("find checkpointed chapters failed")
+
+		return 0
+	}
+
+	return fillFetchedChapters(chapters, storedChapters)
+}
+
+// fillFetchedChapters replace chapters by the stored ones and return the
+// number of chapters replaced. chapter is only replaced if it was fetched
+// successfully and its url is not changed
+func fillFetchedChapters(chapters, storedChapters model.Chapters) int {
+	filledChapterCount := 0
+	for _, storedChapter := range storedChapters {
+		if storedChapter.Index < 0 || storedChapter.Index >= len(chapters) ||
+			storedChapter.Error != nil || storedChapter.FetchedAt.IsZero() ||
+			storedChapter.URL != chapters[storedChapter.Index].URL {
+			continue
+		}
+
+		chapters[storedChapter.Index] = storedChapter
+		filledChapterCount++
+	}
+
+	return filledChapterCount
+}
+
+// writeBookFile write the book to a temporary file and move it to the book
+// location once all chapters are written, so a stopped worker never leave a
+// half written book file
+func (s *ServiceImpl) writeBookFile(bk *model.Book, chapters model.Chapters) error {
+	location := s.bookFileLocation(bk)
+	tmpLocation := location + ".tmp"
+
+	file, err := os.Create(tmpLocation)
 	if err != nil {
 		return fmt.Errorf("create file to save chapters fail: %w", err)
 	}
+	defer os.Remove(tmpLocation)
 	defer file.Close()
 
 	_, err = file.WriteString(bk.HeaderInfo())
@@ -387,6 +533,93 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 		}
 	}
 
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("close file to save chapters fail: %w", err)
+	}
+
+	err = os.Rename(tmpLocation, location)
+	if err != nil {
+		return fmt.Errorf("move saved chapters to book location fail: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *serv.DownloadStats) error {
+	if stats == nil {
+		stats = new(serv.DownloadStats)
+	}
+
+	isInProgress := bk.Status == model.StatusInProgress && s.conf.DownloadInProgress
+	if bk.Status != model.StatusEnd && !isInProgress {
+		return serv.ErrBookStatusNotEnd
+	} else if bk.IsDownloaded {
+		return serv.ErrBookAlreadyDownloaded
+	}
+
+	if isInProgress {
+		return s.downloadNewChapters(ctx, bk, stats)
+	}
+
+	logger := zerolog.Ctx(ctx)
+
+	chapterList, chapterListURL, err := s.fetchChapterList(ctx, bk, stats)
+	if err != nil {
+		return err
+	}
+
+	chapters := make(model.Chapters, len(chapterList))
+	for i := range chapters {
+		chapters[i] = model.NewChapter(i, chapterList[i].URL, chapterList[i].Title)
+	}
+
+	resumedChapterCount := s.resumeChapters(ctx, bk, chapters)
+	pendingChapters := make(model.Chapters, 0, len(chapters)-resumedChapterCount)
+	for _, chapter := range chapters {
+		if chapter.FetchedAt.IsZero() {
+			pendingChapters = append(pendingChapters, chapter)
+		}
+	}
+
+	failedChapterCount := s.downloadChapters(client.WithReferer(ctx, chapterListURL), pendingChapters)
+
+	// checkpoint fetched chapters before checking failures, so retry of this
+	// book only need to fetch the failed chapters
+	if len(pendingChapters) > 0 {
+		logger.Info().
+			Int("resumed_chapter_count", resumedChapterCount).
+			Int("fetched_chapter_count", len(pendingChapters)-failedChapterCount).
+			Msg("save chapters to repository")
+		err = s.rpo.SaveChapters(bk, pendingChapters)
+		if err != nil {
+			return fmt.Errorf("save chapters to repository fail: %w", err)
+		}
+	}
+
+	stats.ResumedChapters.Add(int64(resumedChapterCount))
+	stats.FetchedChapters.Add(int64(len(pendingChapters) - failedChapterCount))
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("download book interrupted: %w", ctx.Err())
+	}
+
+	for _, chapter := range pendingChapters {
+		chapters[chapter.Index] = chapter
+	}
+
+	if failedChapterCount > 50 || failedChapterCount*10 > len(chapters) {
+		stats.TooManyFailChapters.Add(1)
+
+		return fmt.Errorf("Download chapters fail: %w (%v/%v)", serv.ErrTooManyFailedChapters, failedChapterCount, len(chapters))
+	}
+
+	logger.Info().Msg("save chapters")
+	err = s.writeBookFile(bk, chapters)
+	if err != nil {
+		return err
+	}
+
 	logger.Info().Msg("update book is_downloaded")
 	bk.IsDownloaded = true
 	err = s.rpo.UpdateBook(bk)
@@ -399,51 +632,35 @@ func (s *ServiceImpl) DownloadBook(ctx context.Context, bk *model.Book, stats *s
 	return nil
 }
 
+// Download run the download tasks of books in the queue shared by workers
 func (s *ServiceImpl) Download(ctx context.Context, stats *serv.DownloadStats) error {
-	se := semaphore.NewWeighted(int64(s.conf.MaxDownloadConcurrency))
-	var wg sync.WaitGroup
-
 	if stats == nil {
 		stats = new(serv.DownloadStats)
 	}
+	defer s.reportDownloadStats(stats)
+
+	s.deleteFinishedBookTasks(ctx)
 
-	bkChan, err := s.rpo.FindBooksForDownload()
+	count, err := s.rpo.EnqueueDownloadBookTasks(s.conf.DownloadInProgress)
 	if err != nil {
-		return fmt.Errorf("fail to fetch books: %w", err)
+		return fmt.Errorf("fail to enqueue books: %w", err)
 	}
 
-	for bk := range bkChan {
-		bk := bk
-		s.sema.Acquire(ctx, 1)
-		se.Acquire(ctx, 1)
-		wg.Add(1)
-
-		stats.Total.Add(1)
-
-		go func(bk *model.Book) {
-			defer wg.Done()
-			defer se.Release(1)
-			defer s.sema.Release(1)
-
-			logger := zerolog.Ctx(ctx).With().
-				Str("worker_id", uuid.New().String()).
-				Int("bk_id", bk.ID).
-				Str("bk_hash_code", bk.FormatHashCode()).
-				Logger()
+	zerolog.Ctx(ctx).Info().Int("enqueued_count", count).Msg("enqueue download book tasks")
 
-			err := s.DownloadBook(logger.WithContext(ctx), bk, stats)
-			if err != nil {
-				logger.Error().Err(err).Msg("download book failed")
-			}
-		}(&bk)
+	se := semaphore.NewWeighted(int64(s.conf.MaxDownloadConcurrency))
 
-		// give chance to others service running at the same time
-		time.Sleep(time.Millisecond)
-	}
+	return s.runBookTasks(ctx, model.JobOperationDownload, se, func(ctx context.Context, bk *model.Book) error {
+		stats.Total.Add(1)
 
-	wg.Wait()
+		err := s.DownloadBook(ctx, bk, stats)
+		if errors.Is(err, serv.ErrBookStatusNotEnd) || errors.Is(err, serv.ErrBookAlreadyDownloaded) {
+			// the book was changed after the task is enqueued, nothing to retry
+			return nil
+		}
 
-	return nil
+		return err
+	})
 }
 
 func isEnd(bk *model.Book) bool {
@@ -4,8 +4,10 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	client "github.com/htchan/BookSpider/internal/client/v2"
 	"github.com/htchan/BookSpider/internal/config/v2"
 	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
 	repomock "github.com/htchan/BookSpider/internal/mock/repo"
@@ -111,6 +113,7 @@ func TestServiceImpl_DownloadBook(t *testing.T) {
 
 	tests := []struct {
 		name                 string
+		ctx                  context.Context
 		getService           func(ctrl *gomock.Controller) *ServiceImpl
 		book                 *model.Book
 		wantBook             *model.Book
@@ -132,14 +135,16 @@ func TestServiceImpl_DownloadBook(t *testing.T) {
 					{URL: "https://test.com/chapter/1", Title: "title 1"},
 					{URL: "https://test.com/chapter/2", Title: "title 2"},
 				}, nil)
-				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
+				cli.EXPECT().Get(refererMatcher("https://test.com/chapter-list"), "https://test.com/chapter/1").Return("chapter 1 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 1", Body: "content 1 content 1 content 1",
 				}, nil)
-				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/2").Return("chapter 2 response", nil)
+				cli.EXPECT().Get(refererMatcher("https://test.com/chapter-list"), "https://test.com/chapter/2").Return("chapter 2 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 2", Body: "content 2 content 2 content 2",
 				}, nil)
+				rpo.EXPECT().SaveChapters(gomock.Any(), gomock.Any()).Return(nil)
 				rpo.EXPECT().UpdateBook(&model.Book{
 					ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
 					Status: model.StatusEnd, IsDownloaded: true,
@@ -176,6 +181,93 @@ content 2 content 2 content 2
 			wantDownloadStats: func() *serv.DownloadStats {
 				stats := new(serv.DownloadStats)
 				stats.Success.Add(1)
+				stats.FetchedChapters.Add(2)
+
+				return stats
+			},
+		},
+		{
+			name: "resume checkpointed chapters",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("2").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("2", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+					{URL: "https://test.com/chapter/2", Title: "title 2"},
+					{URL: "https://test.com/chapter/3", Title: "title 3"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(model.Chapters{
+					{
+						Index: 0, URL: "https://test.com/chapter/1", Title: "chapter title 1",
+						Content: "content 1", FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						Index: 1, URL: "https://test.com/chapter/2", Title: "title 2",
+						FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Error: serv.ErrUnavailable,
+					},
+					{
+						Index: 2, URL: "https://test.com/chapter/changed", Title: "chapter title 3",
+						Content: "content 3", FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/2").Return("chapter 2 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(&vendor.ChapterInfo{
+					Title: "chapter title 2", Body: "content 2",
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/3").Return("chapter 3 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 3 response").Return(&vendor.ChapterInfo{
+					Title: "chapter title 3", Body: "content 3",
+				}, nil)
+				rpo.EXPECT().SaveChapters(gomock.Any(), model.Chapters{
+					{Index: 1, URL: "https://test.com/chapter/2", Title: "chapter title 2", Content: "content 2"},
+					{Index: 2, URL: "https://test.com/chapter/3", Title: "chapter title 3", Content: "content 3"},
+				}).Return(nil)
+				rpo.EXPECT().UpdateBook(&model.Book{
+					ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+					Status: model.StatusEnd, IsDownloaded: true,
+				}).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book"}, sema: semaphore.NewWeighted(1),
+					rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book: &model.Book{
+				ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantBook: &model.Book{
+				ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
+				Status: model.StatusEnd, IsDownloaded: true,
+			},
+			wantError:            nil,
+			wantBookFileLocation: "./download-book/2.txt",
+			wantBookContent: `title 2
+writer 2
+--------------------
+
+chapter title 1
+--------------------
+content 1
+--------------------
+chapter title 2
+--------------------
+content 2
+--------------------
+chapter title 3
+--------------------
+content 3
+--------------------
+`,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.Success.Add(1)
+				stats.FetchedChapters.Add(2)
+				stats.ResumedChapters.Add(1)
 
 				return stats
 			},
@@ -192,6 +284,197 @@ content 2 content 2 content 2
 				return new(serv.DownloadStats)
 			},
 		},
+		{
+			name: "download new and failed chapters of in progress book",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				bk := &model.Book{
+					ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+					Status: model.StatusInProgress,
+				}
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+					{URL: "https://test.com/chapter/2", Title: "title 2"},
+					{URL: "https://test.com/chapter/3", Title: "title 3"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(bk).Return(model.Chapters{
+					{
+						Index: 0, URL: "https://test.com/chapter/1", Title: "title 1",
+						Content: "content 1", FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						Index: 1, URL: "https://test.com/chapter/2", Title: "title 2",
+						FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Error: serv.ErrUnavailable,
+					},
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/2").Return("chapter 2 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(&vendor.ChapterInfo{
+					Title: "chapter title 2", Body: "content 2 content 2 content 2",
+				}, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/3").Return("chapter 3 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 3 response").Return(&vendor.ChapterInfo{
+					Title: "chapter title 3", Body: "content 3 content 3 content 3",
+				}, nil)
+				rpo.EXPECT().SaveChapters(bk, model.Chapters{
+					{
+						Index: 1, URL: "https://test.com/chapter/2",
+						Title: "chapter title 2", Content: "content 2 content 2 content 2",
+					},
+					{
+						Index: 2, URL: "https://test.com/chapter/3",
+						Title: "chapter title 3", Content: "content 3 content 3 content 3",
+					},
+				}).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book", DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1), rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book: &model.Book{
+				ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+				Status: model.StatusInProgress,
+			},
+			wantBook: &model.Book{
+				ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+				Status: model.StatusInProgress,
+			},
+			wantError: nil,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.Success.Add(1)
+				stats.FetchedChapters.Add(2)
+
+				return stats
+			},
+		},
+		{
+			name: "no new chapters of in progress book",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 1, Status: model.StatusInProgress}).Return(model.Chapters{
+					{
+						Index: 0, URL: "https://test.com/chapter/1", Title: "title 1",
+						Content: "content 1", FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book", DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1), rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book:      &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantBook:  &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantError: nil,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.Success.Add(1)
+
+				return stats
+			},
+		},
+		{
+			name: "count in progress book with failed chapters as failure",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 1, Status: model.StatusInProgress}).Return(nil, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(nil, serv.ErrUnavailable)
+				rpo.EXPECT().SaveChapters(&model.Book{ID: 1, Status: model.StatusInProgress}, model.Chapters{
+					{Index: 0, URL: "https://test.com/chapter/1", Title: "title 1", Error: serv.ErrUnavailable},
+				}).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book", DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1), rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book:      &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantBook:  &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantError: serv.ErrTooManyFailedChapters,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.TooManyFailChapters.Add(1)
+
+				return stats
+			},
+		},
+		{
+			name: "interrupt in progress book without saving unfetched chapters",
+			ctx:  cancelledContext(),
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 1, Status: model.StatusInProgress}).Return(nil, nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book", DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1), rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book:      &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantBook:  &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantError: context.Canceled,
+			wantDownloadStats: func() *serv.DownloadStats {
+				return new(serv.DownloadStats)
+			},
+		},
+		{
+			name: "fail to find fetched chapters of in progress book",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 1, Status: model.StatusInProgress}).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book", DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1), rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book:      &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantBook:  &model.Book{ID: 1, Status: model.StatusInProgress},
+			wantError: serv.ErrUnavailable,
+			wantDownloadStats: func() *serv.DownloadStats {
+				return new(serv.DownloadStats)
+			},
+		},
 		{
 			name: "book already downloaded",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -277,10 +560,15 @@ content 2 content 2 content 2
 					{URL: "https://test.com/chapter/1", Title: "title 1"},
 					{URL: "https://test.com/chapter/2", Title: "title 2"},
 				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(nil, serv.ErrUnavailable)
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/2").Return("chapter 2 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(nil, serv.ErrUnavailable)
+				rpo.EXPECT().SaveChapters(gomock.Any(), model.Chapters{
+					{Index: 0, URL: "https://test.com/chapter/1", Title: "title 1", Error: serv.ErrUnavailable},
+					{Index: 1, URL: "https://test.com/chapter/2", Title: "title 2", Error: serv.ErrUnavailable},
+				}).Return(nil)
 
 				return &ServiceImpl{
 					conf: config.SiteConfig{Storage: "./download-book"}, sema: semaphore.NewWeighted(1),
@@ -303,6 +591,51 @@ content 2 content 2 content 2
 				return stats
 			},
 		},
+		{
+			name: "fail to save chapters to repository",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
+				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(&vendor.ChapterInfo{
+					Title: "chapter title 1", Body: "content 1 content 1 content 1",
+				}, nil)
+				rpo.EXPECT().SaveChapters(&model.Book{
+					ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+					Status: model.StatusEnd, IsDownloaded: false,
+				}, model.Chapters{
+					{
+						Index: 0, URL: "https://test.com/chapter/1",
+						Title: "chapter title 1", Content: "content 1 content 1 content 1",
+					},
+				}).Return(serv.ErrUnavailable)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book"}, sema: semaphore.NewWeighted(1),
+					rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book: &model.Book{
+				ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantBook: &model.Book{
+				ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantError: serv.ErrUnavailable,
+			wantDownloadStats: func() *serv.DownloadStats {
+				return new(serv.DownloadStats)
+			},
+		},
 		{
 			name: "fail to update book after download",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
@@ -316,6 +649,7 @@ content 2 content 2 content 2
 					{URL: "https://test.com/chapter/1", Title: "title 1"},
 					{URL: "https://test.com/chapter/2", Title: "title 2"},
 				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 1", Body: "content 1 content 1 content 1",
@@ -324,6 +658,7 @@ content 2 content 2 content 2
 				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 2", Body: "content 2 content 2 content 2",
 				}, nil)
+				rpo.EXPECT().SaveChapters(gomock.Any(), gomock.Any()).Return(nil)
 				rpo.EXPECT().UpdateBook(&model.Book{
 					ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"},
 					Status: model.StatusEnd, IsDownloaded: true,
@@ -343,6 +678,47 @@ content 2 content 2 content 2
 				Status: model.StatusEnd, IsDownloaded: true,
 			},
 			wantError: serv.ErrUnavailable,
+			wantDownloadStats: func() *serv.DownloadStats {
+				stats := new(serv.DownloadStats)
+				stats.FetchedChapters.Add(2)
+
+				return stats
+			},
+		},
+		{
+			name: "stop before downloading chapters if context is cancelled",
+			ctx:  cancelledContext(),
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("3").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("3", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+					{URL: "https://test.com/chapter/2", Title: "title 2"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
+				rpo.EXPECT().SaveChapters(gomock.Any(), model.Chapters{
+					{Index: 0, URL: "https://test.com/chapter/1", Title: "title 1", Error: context.Canceled},
+					{Index: 1, URL: "https://test.com/chapter/2", Title: "title 2", Error: context.Canceled},
+				}).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./download-book"}, sema: semaphore.NewWeighted(1),
+					rpo: rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			book: &model.Book{
+				ID: 3, Title: "title 3", Writer: model.Writer{Name: "writer 3"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantBook: &model.Book{
+				ID: 3, Title: "title 3", Writer: model.Writer{Name: "writer 3"},
+				Status: model.StatusEnd, IsDownloaded: false,
+			},
+			wantError: context.Canceled,
 			wantDownloadStats: func() *serv.DownloadStats {
 				return new(serv.DownloadStats)
 			},
@@ -357,8 +733,13 @@ content 2 content 2 content 2
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
 			downloadStats := new(serv.DownloadStats)
-			err := test.getService(ctrl).DownloadBook(context.Background(), test.book, downloadStats)
+			err := test.getService(ctrl).DownloadBook(ctx, test.book, downloadStats)
 			assert.ErrorIs(t, err, test.wantError)
 			assert.Equal(t, test.wantBook, test.book)
 			assert.Equal(t, downloadStats, test.wantDownloadStats())
@@ -381,6 +762,7 @@ func TestServiceImpl_Download(t *testing.T) {
 
 	tests := []struct {
 		name       string
+		ctx        context.Context
 		getService func(ctrl *gomock.Controller) *ServiceImpl
 		wantError  error
 	}{
@@ -391,22 +773,42 @@ func TestServiceImpl_Download(t *testing.T) {
 				cli := clientmock.NewMockBookClient(ctrl)
 				vendorService := vendormock.NewMockVendorService(ctrl)
 
-				ch := make(chan model.Book)
-				go func() {
-					ch <- model.Book{ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"}, Status: model.StatusEnd}
-					ch <- model.Book{ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"}, Status: model.StatusEnd}
-					close(ch)
-				}()
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(false).Return(2, nil)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), config.DefaultBookTaskLeaseDuration).
+						Return(&model.BookTask{ID: 1, Operation: model.JobOperationDownload, BookID: 1, Attempts: 1}, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), config.DefaultBookTaskLeaseDuration).
+						Return(&model.BookTask{ID: 2, Operation: model.JobOperationDownload, BookID: 2, Attempts: 1}, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), config.DefaultBookTaskLeaseDuration).
+						Return(nil, nil),
+				)
 
-				rpo.EXPECT().FindBooksForDownload().Return(ch, nil)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{
+					ID: 1, Title: "title 1", Writer: model.Writer{Name: "writer 1"}, Status: model.StatusEnd,
+				}, nil)
 				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list-1")
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list-1").Return("", serv.ErrUnavailable)
+				rpo.EXPECT().FailBookTask(gomock.Any(), config.DefaultBookTaskRetryInterval).DoAndReturn(
+					func(task *model.BookTask, _ time.Duration) error {
+						assert.Equal(t, 1, task.ID)
+						assert.Equal(t, model.BookTaskStatusQueued, task.Status)
+						assert.NotEmpty(t, task.Error)
+
+						return nil
+					},
+				)
+
+				rpo.EXPECT().FindBookByIdHash(2, 0).Return(&model.Book{
+					ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"}, Status: model.StatusEnd,
+				}, nil)
 				vendorService.EXPECT().ChapterListURL("2").Return("https://test.com/chapter-list-2")
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list-2").Return("chapter list response", nil)
 				vendorService.EXPECT().ParseChapterList("2", "chapter list response").Return(vendor.ChapterList{
 					{URL: "https://test.com/chapter/1", Title: "title 1"},
 					{URL: "https://test.com/chapter/2", Title: "title 2"},
 				}, nil)
+				rpo.EXPECT().FindChaptersByBook(gomock.Any()).Return(nil, nil)
 				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter/1").Return("chapter 1 response", nil)
 				vendorService.EXPECT().ParseChapter("chapter 1 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 1", Body: "content 1 content 1 content 1",
@@ -415,13 +817,17 @@ func TestServiceImpl_Download(t *testing.T) {
 				vendorService.EXPECT().ParseChapter("chapter 2 response").Return(&vendor.ChapterInfo{
 					Title: "chapter title 2", Body: "content 2 content 2 content 2",
 				}, nil)
+				rpo.EXPECT().SaveChapters(gomock.Any(), gomock.Any()).Return(nil)
 				rpo.EXPECT().UpdateBook(&model.Book{
 					ID: 2, Title: "title 2", Writer: model.Writer{Name: "writer 2"},
 					Status: model.StatusEnd, IsDownloaded: true,
-				}).Return(serv.ErrUnavailable)
+				}).Return(nil)
+				rpo.EXPECT().CompleteBookTask(&model.BookTask{
+					ID: 2, Operation: model.JobOperationDownload, BookID: 2, Attempts: 1,
+				}).Return(nil)
 
 				return &ServiceImpl{
-					conf: config.SiteConfig{Storage: "./download-book", MaxDownloadConcurrency: 1},
+					conf: config.SiteConfig{Storage: "./download", MaxDownloadConcurrency: 1},
 					sema: semaphore.NewWeighted(2),
 					rpo:  rpo, cli: cli, vendorService: vendorService,
 				}
@@ -429,15 +835,111 @@ func TestServiceImpl_Download(t *testing.T) {
 			wantError: nil,
 		},
 		{
-			name: "fail to find books for download",
+			name: "happy flow with in progress books",
 			getService: func(ctrl *gomock.Controller) *ServiceImpl {
 				rpo := repomock.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksForDownload().Return(nil, serv.ErrUnavailable)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				task := &model.BookTask{ID: 1, Operation: model.JobOperationDownload, BookID: 1, Attempts: 1}
+
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(true).Return(1, nil)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), gomock.Any()).Return(task, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), gomock.Any()).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{ID: 1, Status: model.StatusInProgress}, nil)
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list-1")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list-1").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "https://test.com/chapter/1", Title: "title 1"},
+				}, nil)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 1, Status: model.StatusInProgress}).Return(model.Chapters{
+					{
+						Index: 0, URL: "https://test.com/chapter/1", Title: "title 1",
+						Content: "content 1", FetchedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil)
+				rpo.EXPECT().CompleteBookTask(task).Return(nil)
 
-				return &ServiceImpl{rpo: rpo}
+				return &ServiceImpl{
+					conf: config.SiteConfig{
+						Storage: "./download", MaxDownloadConcurrency: 1, DownloadInProgress: true,
+					},
+					sema: semaphore.NewWeighted(2),
+					rpo:  rpo, cli: cli, vendorService: vendorService,
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "complete task of book downloaded after enqueued",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+
+				task := &model.BookTask{ID: 1, Operation: model.JobOperationDownload, BookID: 1, Attempts: 1}
+
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(false).Return(1, nil)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), gomock.Any()).Return(task, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), gomock.Any()).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{ID: 1, Status: model.StatusEnd, IsDownloaded: true}, nil)
+				rpo.EXPECT().CompleteBookTask(task).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{MaxDownloadConcurrency: 1},
+					sema: semaphore.NewWeighted(1),
+					rpo:  rpo,
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "fail to enqueue books for download",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(true).Return(0, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{DownloadInProgress: true}}
 			},
 			wantError: serv.ErrUnavailable,
 		},
+		{
+			name: "fail to lease book task",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(false).Return(0, nil)
+				rpo.EXPECT().LeaseBookTask(model.JobOperationDownload, gomock.Any(), gomock.Any()).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{MaxDownloadConcurrency: 1},
+					sema: semaphore.NewWeighted(1),
+					rpo:  rpo,
+				}
+			},
+			wantError: serv.ErrUnavailable,
+		},
+		{
+			name: "stop leasing tasks if context is cancelled",
+			ctx:  cancelledContext(),
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueDownloadBookTasks(true).Return(2, nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{MaxDownloadConcurrency: 1, DownloadInProgress: true},
+					sema: semaphore.NewWeighted(1),
+					rpo:  rpo,
+				}
+			},
+			wantError: context.Canceled,
+		},
 	}
 
 	for _, test := range tests {
@@ -448,8 +950,123 @@ func TestServiceImpl_Download(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			err := test.getService(ctrl).Download(context.Background(), nil)
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := test.getService(ctrl).Download(ctx, nil)
 			assert.ErrorIs(t, err, test.wantError)
 		})
 	}
 }
+
+func TestServiceImpl_fetchChapterList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		getService      func(ctrl *gomock.Controller) *ServiceImpl
+		want            vendor.ChapterList
+		wantURL         string
+		wantError       bool
+		wantRequestFail int64
+	}{
+		{
+			name: "resolve relative chapter urls against redirected url",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").DoAndReturn(
+					func(ctx context.Context, _ string) (string, error) {
+						client.ResponseInfoFromContext(ctx).URL = "https://new.test.com/book/1/"
+
+						return "chapter list response", nil
+					},
+				)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "1.html", Title: "title 1"},
+					{URL: "/book/1/2.html", Title: "title 2"},
+					{URL: "https://test.com/chapter/3", Title: "title 3"},
+				}, nil)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			want: vendor.ChapterList{
+				{URL: "https://new.test.com/book/1/1.html", Title: "title 1"},
+				{URL: "https://new.test.com/book/1/2.html", Title: "title 2"},
+				{URL: "https://test.com/chapter/3", Title: "title 3"},
+			},
+			wantURL: "https://new.test.com/book/1/",
+		},
+		{
+			name: "use requested url if final url is unknown",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list/")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list/").Return("chapter list response", nil)
+				vendorService.EXPECT().ParseChapterList("1", "chapter list response").Return(vendor.ChapterList{
+					{URL: "1.html", Title: "title 1"},
+				}, nil)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			want: vendor.ChapterList{
+				{URL: "https://test.com/chapter-list/1.html", Title: "title 1"},
+			},
+			wantURL: "https://test.com/chapter-list/",
+		},
+		{
+			name: "fail to send request",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+
+				vendorService.EXPECT().ChapterListURL("1").Return("https://test.com/chapter-list")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com/chapter-list").Return("", client.ErrTimeout)
+
+				return &ServiceImpl{cli: cli, vendorService: vendorService}
+			},
+			want:            nil,
+			wantURL:         "",
+			wantError:       true,
+			wantRequestFail: 1,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			stats := new(serv.DownloadStats)
+			got, gotURL, err := test.getService(ctrl).fetchChapterList(context.Background(), &model.Book{ID: 1}, stats)
+			assert.Equal(t, test.wantError, err != nil)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantURL, gotURL)
+			assert.Equal(t, test.wantRequestFail, stats.RequestFail.Load())
+		})
+	}
+}
+
+// refererMatcher match the request context carrying the referer
+type refererMatcher string
+
+var _ gomock.Matcher = refererMatcher("")
+
+func (m refererMatcher) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+
+	return ok && client.RefererFromContext(ctx) == string(m)
+}
+
+func (m refererMatcher) String() string {
+	return "context with referer " + string(m)
+}
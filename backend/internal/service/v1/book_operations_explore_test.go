@@ -23,6 +23,7 @@ func TestServiceImpl_ExploreBook(t *testing.T) {
 	tests := []struct {
 		name      string
 		getServ   func(ctrl *gomock.Controller) *ServiceImpl
+		ctx       context.Context
 		bk        *model.Book
 		wantBk    *model.Book
 		wantError error
@@ -73,6 +74,22 @@ func TestServiceImpl_ExploreBook(t *testing.T) {
 			wantBk:    &model.Book{ID: 1, Status: model.StatusError, Error: fmt.Errorf("get book page failed: %w", serv.ErrUnavailable)},
 			wantError: serv.ErrUnavailable,
 		},
+		{
+			name: "not save error if interrupted by ctx",
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+
+				vendorService.EXPECT().BookURL("1").Return("https://test.com")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("", context.Canceled)
+
+				return &ServiceImpl{rpo: repomock.NewMockRepository(ctrl), vendorService: vendorService, cli: cli}
+			},
+			ctx:       cancelledContext(),
+			bk:        &model.Book{ID: 1, Status: model.StatusError, Error: serv.ErrUnavailable},
+			wantBk:    &model.Book{ID: 1, Status: model.StatusError, Error: serv.ErrUnavailable},
+			wantError: context.Canceled,
+		},
 	}
 
 	for _, test := range tests {
@@ -83,7 +100,12 @@ func TestServiceImpl_ExploreBook(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			err := test.getServ(ctrl).ExploreBook(context.Background(), test.bk, nil)
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := test.getServ(ctrl).ExploreBook(ctx, test.bk, nil)
 			assert.Equal(t, test.wantBk, test.bk)
 			assert.ErrorIs(t, err, test.wantError)
 		})
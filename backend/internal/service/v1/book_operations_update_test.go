@@ -407,6 +407,7 @@ func TestServiceImpl_Update(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		ctx       context.Context
 		getServ   func(ctrl *gomock.Controller) *ServiceImpl
 		wantError error
 	}{
@@ -419,15 +420,18 @@ func TestServiceImpl_Update(t *testing.T) {
 					ID: 1, Title: "title", Writer: model.Writer{Name: "writer"}, Type: "type",
 					UpdateDate: "date", UpdateChapter: "chapter", Status: model.StatusInProgress,
 				}
-				ch := make(chan model.Book)
-
-				go func() {
+				task := &model.BookTask{ID: 1, Operation: model.JobOperationUpdate, BookID: 1, Attempts: 1}
+
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueUpdateBookTasks().Return(1, nil)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, gomock.Any(), gomock.Any()).Return(task, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, gomock.Any(), gomock.Any()).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).DoAndReturn(func(int, int) (*model.Book, error) {
 					bk := bk
-					ch <- bk
-					close(ch)
-				}()
-
-				rpo.EXPECT().FindBooksForUpdate().Return(ch, nil)
+					return &bk, nil
+				})
 				vendorService.EXPECT().BookURL("1").Return("https://test.com")
 				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("response", nil)
 				vendorService.EXPECT().ParseBook("response").Return(&vendor.BookInfo{
@@ -440,22 +444,37 @@ func TestServiceImpl_Update(t *testing.T) {
 				rpo.EXPECT().SaveWriter(&model.Writer{Name: "writer"}).Return(nil)
 				rpo.EXPECT().UpdateBook(&bkUpdated).Return(nil)
 				rpo.EXPECT().SaveError(&bkUpdated, nil).Return(nil)
+				rpo.EXPECT().CompleteBookTask(task).Return(nil)
 
 				return &ServiceImpl{sema: semaphore.NewWeighted(1), rpo: rpo, vendorService: vendorService, cli: cli}
 			},
 			wantError: nil,
 		},
 		{
-			name: "return error if find book for update failed",
+			name: "return error if enqueue books for update failed",
 			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
 				rpo := repomock.NewMockRepository(ctrl)
 
-				rpo.EXPECT().FindBooksForUpdate().Return(nil, serv.ErrUnavailable)
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueUpdateBookTasks().Return(0, serv.ErrUnavailable)
 
 				return &ServiceImpl{rpo: rpo}
 			},
 			wantError: serv.ErrUnavailable,
 		},
+		{
+			name: "stop updating books if context is cancelled",
+			ctx:  cancelledContext(),
+			getServ: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+
+				rpo.EXPECT().DeleteFinishedBookTasks(gomock.Any()).Return(0, nil)
+				rpo.EXPECT().EnqueueUpdateBookTasks().Return(2, nil)
+
+				return &ServiceImpl{sema: semaphore.NewWeighted(1), rpo: rpo}
+			},
+			wantError: context.Canceled,
+		},
 	}
 
 	for _, test := range tests {
@@ -466,7 +485,12 @@ func TestServiceImpl_Update(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			err := test.getServ(ctrl).Update(context.Background(), nil)
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := test.getServ(ctrl).Update(ctx, nil)
 			assert.ErrorIs(t, err, test.wantError)
 		})
 	}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	bookTaskOutcomeDone      = "done"
+	bookTaskOutcomeRetry     = "retry"
+	bookTaskOutcomeDead      = "dead"
+	bookTaskOutcomeLeaseLost = "lease_lost"
+)
+
+// newWorkerID return the owner of leased book tasks. hostname is included so
+// the lease can be traced back to the pod holding it
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return hostname + "-" + uuid.New().String()
+}
+
+// deleteFinishedBookTasks delete done tasks not updated within retention, so
+// the queue does not keep a row for every book ever updated or downloaded.
+// failure is only logged as it does not affect the tasks to run
+func (s *ServiceImpl) deleteFinishedBookTasks(ctx context.Context) {
+	conf := s.conf.BookTaskConfig.WithDefaults()
+
+	count, err := s.rpo.DeleteFinishedBookTasks(time.Now().Add(-conf.Retention))
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("delete finished book tasks failed")
+
+		return
+	}
+
+	zerolog.Ctx(ctx).Debug().Int("deleted_count", count).Msg("delete finished book tasks")
+}
+
+// runBookTasks lease the tasks of operation one by one and run them until no
+// task is available or ctx is done. other workers lease from the same queue,
+// so the tasks are shared among them. se limit the concurrency of operation
+// on top of s.sema if it is not nil
+func (s *ServiceImpl) runBookTasks(
+	ctx context.Context, operation string, se *semaphore.Weighted,
+	run func(context.Context, *model.Book) error,
+) error {
+	conf := s.conf.BookTaskConfig.WithDefaults()
+
+	var wg sync.WaitGroup
+	var interruptErr, leaseErr error
+
+	for {
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
+		if se != nil {
+			if interruptErr = se.Acquire(ctx, 1); interruptErr != nil {
+				s.sema.Release(1)
+				break
+			}
+		}
+		release := func() {
+			if se != nil {
+				se.Release(1)
+			}
+			s.sema.Release(1)
+		}
+
+		var task *model.BookTask
+		task, leaseErr = s.rpo.LeaseBookTask(operation, s.workerID, conf.LeaseDuration)
+		if leaseErr != nil || task == nil {
+			release()
+			break
+		}
+
+		wg.Add(1)
+		go func(task *model.BookTask) {
+			defer wg.Done()
+			defer release()
+
+			s.runBookTask(ctx, task, conf, run)
+		}(task)
+
+		// give chance to others service running at the same time
+		time.Sleep(time.Millisecond)
+	}
+
+	wg.Wait()
+
+	if interruptErr != nil {
+		return fmt.Errorf("%s interrupted: %w", operation, interruptErr)
+	} else if leaseErr != nil {
+		return fmt.Errorf("fail to lease book task: %w", leaseErr)
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) runBookTask(
+	ctx context.Context, task *model.BookTask, conf config.BookTaskConfig,
+	run func(context.Context, *model.Book) error,
+) {
+	logger := zerolog.Ctx(ctx).With().
+		Str("worker_id", uuid.New().String()).
+		Int("book_task_id", task.ID).
+		Int("bk_id", task.BookID).
+		Str("bk_hash_code", (&model.Book{HashCode: task.HashCode}).FormatHashCode()).
+		Int("attempts", task.Attempts).
+		Logger()
+	ctx = logger.WithContext(ctx)
+
+	var err error
+	if task.Attempts > conf.MaxAttempts {
+		// lease of previous attempts were not renewed, the worker running it
+		// probably crashed
+		err = serv.ErrBookTaskLeaseExpired
+	} else {
+		err = s.runLeasedBookTask(ctx, task, conf, run)
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("book task failed")
+	}
+
+	s.finishBookTask(ctx, task, conf, err)
+}
+
+func (s *ServiceImpl) runLeasedBookTask(
+	ctx context.Context, task *model.BookTask, conf config.BookTaskConfig,
+	run func(context.Context, *model.Book) error,
+) error {
+	bk, err := s.rpo.FindBookByIdHash(task.BookID, task.HashCode)
+	if err != nil {
+		return fmt.Errorf("find book fail: %w", err)
+	}
+
+	taskCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	renewDone := make(chan struct{})
+	go func() {
+		defer close(renewDone)
+		s.renewBookTaskLease(taskCtx, cancel, task, conf.LeaseDuration)
+	}()
+
+	err = run(taskCtx, bk)
+
+	cancel()
+	<-renewDone
+
+	return err
+}
+
+// renewBookTaskLease keep the lease of task until ctx is done. the task is
+// cancelled once the lease is lost, as another worker has taken it over
+func (s *ServiceImpl) renewBookTaskLease(
+	ctx context.Context, cancel context.CancelFunc,
+	task *model.BookTask, lease time.Duration,
+) {
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.rpo.RenewBookTaskLease(task, lease)
+		if errors.Is(err, repo.ErrBookTaskLeaseLost) {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("book task taken over by another worker")
+			cancel()
+
+			return
+		} else if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("renew book task lease failed")
+		}
+	}
+}
+
+// finishBookTask complete the task, or put it back to queue to be retried
+// later. interrupted task is retried immediately by other workers
+func (s *ServiceImpl) finishBookTask(ctx context.Context, task *model.BookTask, conf config.BookTaskConfig, taskErr error) {
+	var err error
+	outcome := bookTaskOutcomeDone
+
+	if taskErr == nil {
+		err = s.rpo.CompleteBookTask(task)
+	} else {
+		task.Fail(taskErr, conf.MaxAttempts)
+
+		retryAfter := conf.RetryInterval * time.Duration(task.Attempts)
+		if ctx.Err() != nil {
+			retryAfter = 0
+		}
+
+		outcome = bookTaskOutcomeRetry
+		if task.Status == model.BookTaskStatusDead {
+			outcome = bookTaskOutcomeDead
+		}
+
+		err = s.rpo.FailBookTask(task, retryAfter)
+	}
+
+	if errors.Is(err, repo.ErrBookTaskLeaseLost) {
+		outcome = bookTaskOutcomeLeaseLost
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("book task taken over by another worker")
+	} else if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("finish book task failed")
+	}
+
+	metrics.BookTasks.WithLabelValues(s.name, task.Operation, outcome).Inc()
+}
+
+func (s *ServiceImpl) BookTasks(
+	ctx context.Context, operation string, status model.BookTaskStatus, limit, offset int,
+) ([]model.BookTask, error) {
+	return s.rpo.FindBookTasks(operation, status, limit, offset)
+}
+
+func (s *ServiceImpl) RequeueBookTask(ctx context.Context, id int) (*model.BookTask, error) {
+	return s.rpo.RequeueBookTask(id)
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestServiceImpl_runBookTasks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		conf       config.BookTaskConfig
+		getService func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl
+		run        func(ctx context.Context, bk *model.Book) error
+		wantRun    bool
+		wantError  error
+	}{
+		{
+			name: "dead letter task with lease expired in all attempts",
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", config.DefaultBookTaskLeaseDuration).
+						Return(&model.BookTask{ID: 1, BookID: 1, Attempts: 4}, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", config.DefaultBookTaskLeaseDuration).
+						Return(nil, nil),
+				)
+				rpo.EXPECT().FailBookTask(&model.BookTask{
+					ID: 1, BookID: 1, Attempts: 4,
+					Status: model.BookTaskStatusDead, Error: serv.ErrBookTaskLeaseExpired.Error(),
+				}, 4*config.DefaultBookTaskRetryInterval).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			wantRun: false,
+		},
+		{
+			name: "retry task if book is not found",
+			conf: config.BookTaskConfig{RetryInterval: time.Minute},
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).
+						Return(&model.BookTask{ID: 1, BookID: 1, HashCode: 2, Attempts: 2}, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).
+						Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 2).Return(nil, repo.ErrBookNotExist)
+				rpo.EXPECT().FailBookTask(&model.BookTask{
+					ID: 1, BookID: 1, HashCode: 2, Attempts: 2,
+					Status: model.BookTaskStatusQueued, Error: "find book fail: no records found",
+				}, 2*time.Minute).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			wantRun: false,
+		},
+		{
+			name: "renew lease while task is running",
+			conf: config.BookTaskConfig{LeaseDuration: 30 * time.Millisecond},
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				task := &model.BookTask{ID: 1, BookID: 1, Attempts: 1}
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", 30*time.Millisecond).Return(task, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", 30*time.Millisecond).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{ID: 1}, nil)
+				rpo.EXPECT().RenewBookTaskLease(task, 30*time.Millisecond).Return(nil).MinTimes(1)
+				rpo.EXPECT().CompleteBookTask(task).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			run: func(ctx context.Context, bk *model.Book) error {
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			},
+			wantRun: true,
+		},
+		{
+			name: "cancel task once lease is lost",
+			conf: config.BookTaskConfig{LeaseDuration: 30 * time.Millisecond},
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				task := &model.BookTask{ID: 1, BookID: 1, Attempts: 1}
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).Return(task, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{ID: 1}, nil)
+				rpo.EXPECT().RenewBookTaskLease(task, gomock.Any()).Return(repo.ErrBookTaskLeaseLost)
+				rpo.EXPECT().FailBookTask(task, gomock.Any()).Return(repo.ErrBookTaskLeaseLost)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			run: func(ctx context.Context, bk *model.Book) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Second):
+					return errors.New("task not cancelled")
+				}
+			},
+			wantRun: true,
+		},
+		{
+			name: "requeue interrupted task without counting its attempt",
+			conf: config.BookTaskConfig{RetryInterval: time.Minute},
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).
+						Return(&model.BookTask{ID: 1, BookID: 1, Attempts: 3}, nil),
+					rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).
+						Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(1, 0).Return(&model.Book{ID: 1}, nil)
+				rpo.EXPECT().FailBookTask(&model.BookTask{
+					ID: 1, BookID: 1, Attempts: 2,
+					Status: model.BookTaskStatusQueued, Error: "update interrupted: context canceled",
+				}, 2*time.Minute).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			run: func(ctx context.Context, bk *model.Book) error {
+				return fmt.Errorf("update interrupted: %w", context.Canceled)
+			},
+			wantRun: true,
+		},
+		{
+			name: "return error if lease failed",
+			getService: func(ctrl *gomock.Controller, conf config.SiteConfig) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LeaseBookTask(model.JobOperationUpdate, "worker", gomock.Any()).Return(nil, serv.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo, sema: semaphore.NewWeighted(1), workerID: "worker", conf: conf}
+			},
+			wantRun:   false,
+			wantError: serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := test.getService(ctrl, config.SiteConfig{BookTaskConfig: test.conf})
+
+			called := false
+			err := s.runBookTasks(context.Background(), model.JobOperationUpdate, nil, func(ctx context.Context, bk *model.Book) error {
+				called = true
+				if test.run != nil {
+					return test.run(ctx, bk)
+				}
+
+				return nil
+			})
+
+			assert.ErrorIs(t, err, test.wantError)
+			assert.Equal(t, test.wantRun, called)
+		})
+	}
+}
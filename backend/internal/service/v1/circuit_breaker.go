@@ -0,0 +1,17 @@
+package service
+
+import (
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+)
+
+func (s *ServiceImpl) CircuitBreakerState() circuitbreaker.State {
+	return s.breaker.State()
+}
+
+func (s *ServiceImpl) ForceOpenCircuitBreaker(reason string) {
+	s.breaker.ForceOpen(reason)
+}
+
+func (s *ServiceImpl) ForceCloseCircuitBreaker(reason string) {
+	s.breaker.ForceClose(reason)
+}
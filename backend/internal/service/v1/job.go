@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+func (s *ServiceImpl) EnqueueJob(ctx context.Context, operation string, bk *model.Book) (*model.Job, error) {
+	if !model.IsQueueableJobOperation(operation) {
+		return nil, fmt.Errorf("enqueue job fail: %w", serv.ErrUnknownOperation)
+	}
+
+	job := model.NewJob(s.name, operation, bk, time.Now().UTC().Truncate(time.Microsecond))
+	err := s.rpo.CreateJob(job)
+	if err != nil {
+		return nil, fmt.Errorf("enqueue job fail: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *ServiceImpl) Job(ctx context.Context, id int) (*model.Job, error) {
+	return s.rpo.FindJob(id)
+}
+
+// RunQueuedJobs claim and run the queued jobs one by one until no job is
+// queued or ctx is done. job stopped by ctx is queued again. site job fails
+// with serv.ErrOperationRunning if the operation is run by any worker already
+func (s *ServiceImpl) RunQueuedJobs(ctx context.Context) error {
+	for ctx.Err() == nil {
+		ran, err := s.runNextQueuedJob(ctx)
+		if err != nil || !ran {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runNextQueuedJob claim and run the oldest queued job. it return false if no
+// job is queued. job left running by crashed worker is claimed again once its
+// lease expired
+func (s *ServiceImpl) runNextQueuedJob(ctx context.Context) (bool, error) {
+	conf := s.conf.JobConfig.WithDefaults()
+
+	job, err := s.rpo.ClaimJob(s.workerID, conf.LeaseDuration)
+	if err != nil {
+		return false, fmt.Errorf("claim job fail: %w", err)
+	}
+
+	if job == nil {
+		return false, nil
+	}
+
+	logger := zerolog.Ctx(ctx).With().
+		Str("site", s.name).
+		Int("job_id", job.ID).
+		Str("job_operation", job.Operation).
+		Int("attempts", job.Attempts).
+		Logger()
+	logger.Info().Msg("start queued job")
+
+	var stats map[string]int64
+	var jobErr error
+	if job.Attempts > conf.MaxAttempts {
+		// lease of previous attempts were not renewed, the worker running it
+		// probably crashed
+		jobErr = serv.ErrJobLeaseExpired
+	} else {
+		stats, jobErr = s.runLeasedJob(logger.WithContext(ctx), job, conf.LeaseDuration)
+	}
+
+	job.Finish(time.Now().UTC().Truncate(time.Microsecond), jobErr, stats)
+	logger.Info().Err(jobErr).Str("status", string(job.Status)).Msg("complete queued job")
+
+	err = s.rpo.FinishJob(job)
+	if errors.Is(err, repo.ErrJobLeaseLost) {
+		logger.Warn().Err(err).Msg("queued job claimed by another worker")
+	} else if err != nil {
+		return false, fmt.Errorf("finish job fail: %w", err)
+	}
+
+	return true, nil
+}
+
+// runLeasedJob run the job with its lease renewed in background. the job is
+// cancelled once the lease is lost
+func (s *ServiceImpl) runLeasedJob(ctx context.Context, job *model.Job, lease time.Duration) (map[string]int64, error) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	renewDone := make(chan struct{})
+	go func() {
+		defer close(renewDone)
+		s.renewJobLease(jobCtx, cancel, job, lease)
+	}()
+
+	stats, err := s.runQueuedJob(jobCtx, job)
+
+	cancel()
+	<-renewDone
+
+	return stats, err
+}
+
+// renewJobLease keep the lease of job until ctx is done. the job is cancelled
+// once the lease is lost, as another worker has claimed it
+func (s *ServiceImpl) renewJobLease(
+	ctx context.Context, cancel context.CancelFunc,
+	job *model.Job, lease time.Duration,
+) {
+	ticker := time.NewTicker(lease / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.rpo.RenewJobLease(job, lease)
+		if errors.Is(err, repo.ErrJobLeaseLost) {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("queued job claimed by another worker")
+			cancel()
+
+			return
+		} else if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("renew job lease failed")
+		}
+	}
+}
+
+func (s *ServiceImpl) runQueuedJob(ctx context.Context, job *model.Job) (map[string]int64, error) {
+	if !job.IsBookJob() {
+		return s.runSiteJob(ctx, job.Operation)
+	}
+
+	bk, err := s.rpo.FindBookByIdHash(job.BookID, job.HashCode)
+	if err != nil {
+		return nil, fmt.Errorf("find book fail: %w", err)
+	}
+
+	return s.runBookJob(ctx, job.Operation, bk)
+}
+
+// runSiteJob run the operation on whole site, the run is also recorded in
+// job runs as the scheduled one
+func (s *ServiceImpl) runSiteJob(ctx context.Context, operation string) (map[string]int64, error) {
+	switch operation {
+	case model.JobOperationUpdate:
+		stats := new(serv.UpdateStats)
+		err := s.runJob(ctx, operation, func(ctx context.Context) error {
+			return s.Update(ctx, stats)
+		}, stats.Snapshot)
+
+		return stats.Snapshot(), err
+	case model.JobOperationExplore:
+		stats := new(serv.UpdateStats)
+		err := s.runJob(ctx, operation, func(ctx context.Context) error {
+			return s.Explore(ctx, stats)
+		}, stats.Snapshot)
+
+		return stats.Snapshot(), err
+	case model.JobOperationDownload:
+		stats := new(serv.DownloadStats)
+		err := s.runJob(ctx, operation, func(ctx context.Context) error {
+			return s.Download(ctx, stats)
+		}, stats.Snapshot)
+
+		return stats.Snapshot(), err
+	case model.JobOperationValidate:
+		return nil, s.runJob(ctx, operation, s.ValidateEnd, nil)
+	case model.JobOperationCheckAvailability:
+		return nil, s.runJob(ctx, operation, s.CheckAvailability, nil)
+	case model.JobOperationPatchStatus:
+		stats := new(serv.PatchStorageStats)
+		err := s.runJob(ctx, operation, func(ctx context.Context) error {
+			return s.PatchDownloadStatus(ctx, stats)
+		}, stats.Snapshot)
+
+		return stats.Snapshot(), err
+	case model.JobOperationPatchMissingRecords:
+		stats := new(serv.UpdateStats)
+		err := s.runJob(ctx, operation, func(ctx context.Context) error {
+			return s.PatchMissingRecords(ctx, stats)
+		}, stats.Snapshot)
+
+		return stats.Snapshot(), err
+	case model.JobOperationPatchChapters:
+		return nil, s.runJob(ctx, operation, s.PatchChapters, nil)
+	case model.JobOperationProcess:
+		unlock, err := s.lockOperation(ctx, operation)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		return nil, s.Process(ctx)
+	default:
+		return nil, serv.ErrUnknownOperation
+	}
+}
+
+func (s *ServiceImpl) runBookJob(ctx context.Context, operation string, bk *model.Book) (map[string]int64, error) {
+	switch operation {
+	case model.JobOperationUpdate:
+		stats := new(serv.UpdateStats)
+		err := s.UpdateBook(ctx, bk, stats)
+
+		return stats.Snapshot(), err
+	case model.JobOperationExplore:
+		stats := new(serv.UpdateStats)
+		err := s.ExploreBook(ctx, bk, stats)
+
+		return stats.Snapshot(), err
+	case model.JobOperationDownload:
+		stats := new(serv.DownloadStats)
+		err := s.DownloadBook(ctx, bk, stats)
+
+		return stats.Snapshot(), err
+	case model.JobOperationValidate:
+		return nil, s.ValidateBookEnd(ctx, bk)
+	case model.JobOperationProcess:
+		return nil, s.ProcessBook(ctx, bk)
+	case model.JobOperationPatchChapters:
+		return nil, s.PatchBookChapters(ctx, bk)
+	default:
+		return nil, serv.ErrUnknownOperation
+	}
+}
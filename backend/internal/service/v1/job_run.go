@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// runJob run the operation and record it as a job run with the snapshot of
+// stats when it returns. failing to record the run does not stop the
+// operation, it is only logged. exclusive operation is not run and
+// serv.ErrOperationRunning is returned if other worker is running it
+func (s *ServiceImpl) runJob(
+	ctx context.Context, operation string,
+	job func(context.Context) error, stats func() map[string]int64,
+) error {
+	logger := zerolog.Ctx(ctx).With().Str("operation", operation).Logger()
+	ctx = logger.WithContext(ctx)
+
+	if model.IsExclusiveJobOperation(operation) {
+		unlock, lockErr := s.lockOperation(ctx, operation)
+		if lockErr != nil {
+			return lockErr
+		}
+		defer unlock()
+	}
+
+	run := model.NewJobRun(s.name, operation, time.Now().UTC().Truncate(time.Microsecond))
+	createErr := s.rpo.CreateJobRun(run)
+	if createErr != nil {
+		logger.Warn().Err(createErr).Msg("create job run failed")
+	}
+
+	logger.Trace().Msg("start")
+	jobErr := job(ctx)
+
+	var snapshot map[string]int64
+	if stats != nil {
+		snapshot = stats()
+	}
+
+	run.Finish(time.Now().UTC().Truncate(time.Microsecond), jobErr, snapshot)
+
+	event := logger.Trace().Str("outcome", string(run.Outcome))
+	for key, value := range snapshot {
+		event = event.Int64(key, value)
+	}
+	event.Msg("complete")
+
+	if createErr == nil {
+		finishErr := s.rpo.FinishJobRun(run)
+		if finishErr != nil {
+			logger.Warn().Err(finishErr).Int("job_run_id", run.ID).Msg("finish job run failed")
+		}
+	}
+
+	return jobErr
+}
+
+// lockOperation take the lock of operation on site shared by all workers, so
+// the operation never overlaps even if it is run by different pods.
+// serv.ErrOperationRunning is returned if the lock is held by others
+func (s *ServiceImpl) lockOperation(ctx context.Context, operation string) (func(), error) {
+	unlock, err := s.rpo.LockOperation(operation)
+	if errors.Is(err, repo.ErrOperationLocked) {
+		return nil, fmt.Errorf("%s: %w", operation, serv.ErrOperationRunning)
+	} else if err != nil {
+		return nil, fmt.Errorf("lock %s fail: %w", operation, err)
+	}
+
+	return func() {
+		if unlockErr := unlock(); unlockErr != nil {
+			zerolog.Ctx(ctx).Warn().Err(unlockErr).Str("operation", operation).Msg("unlock operation failed")
+		}
+	}, nil
+}
+
+func (s *ServiceImpl) JobRuns(ctx context.Context, operation string, limit, offset int) ([]model.JobRun, error) {
+	return s.rpo.FindJobRuns(operation, limit, offset)
+}
+
+func (s *ServiceImpl) JobRun(ctx context.Context, id int) (*model.JobRun, error) {
+	return s.rpo.FindJobRun(id)
+}
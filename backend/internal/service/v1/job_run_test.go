@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceImpl_runJob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl
+		operation  string
+		jobErr     error
+		stats      func() map[string]int64
+		wantRun    *model.JobRun
+		wantErr    error
+	}{
+		{
+			name: "record successful run with stats",
+			getService: func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					assert.Equal(t, model.JobOutcomeRunning, run.Outcome)
+					run.ID = 1
+					return nil
+				})
+				rpo.EXPECT().FinishJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					*finished = run
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			stats: func() map[string]int64 { return map[string]int64{"total": 1} },
+			wantRun: &model.JobRun{
+				ID: 1, Site: "test", Operation: model.JobOperationUpdate,
+				Outcome: model.JobOutcomeSuccess, Stats: map[string]int64{"total": 1},
+			},
+		},
+		{
+			name: "record failed run",
+			getService: func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					run.ID = 2
+					return nil
+				})
+				rpo.EXPECT().FinishJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					*finished = run
+					return errors.New("some error")
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			jobErr: errors.New("job failed"),
+			wantRun: &model.JobRun{
+				ID: 2, Site: "test", Operation: model.JobOperationUpdate,
+				Outcome: model.JobOutcomeFail, Error: "job failed",
+			},
+		},
+		{
+			name: "run job even if run cannot be created",
+			getService: func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).Return(errors.New("some error"))
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			jobErr:  errors.New("job failed"),
+			wantRun: nil,
+		},
+		{
+			name: "run exclusive operation with lock held",
+			getService: func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				unlocked := false
+				rpo.EXPECT().LockOperation(model.JobOperationExplore).Return(func() error {
+					unlocked = true
+					return nil
+				}, nil)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					assert.False(t, unlocked)
+					run.ID = 3
+					return nil
+				})
+				rpo.EXPECT().FinishJobRun(gomock.Any()).DoAndReturn(func(run *model.JobRun) error {
+					assert.False(t, unlocked)
+					*finished = run
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationExplore,
+			wantRun: &model.JobRun{
+				ID: 3, Site: "test", Operation: model.JobOperationExplore,
+				Outcome: model.JobOutcomeSuccess,
+			},
+		},
+		{
+			name: "skip exclusive operation running by other worker",
+			getService: func(ctrl *gomock.Controller, finished **model.JobRun) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LockOperation(model.JobOperationExplore).
+					Return(nil, fmt.Errorf("fail to lock operation: %w", repo.ErrOperationLocked))
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationExplore,
+			wantRun:   nil,
+			wantErr:   serv.ErrOperationRunning,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var finished *model.JobRun
+			s := test.getService(ctrl, &finished)
+
+			operation := test.operation
+			if operation == "" {
+				operation = model.JobOperationUpdate
+			}
+
+			called := false
+			err := s.runJob(context.Background(), operation, func(ctx context.Context) error {
+				called = true
+				return test.jobErr
+			}, test.stats)
+
+			if test.wantErr != nil {
+				assert.False(t, called)
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.True(t, called)
+				assert.Equal(t, test.jobErr, err)
+			}
+
+			if finished != nil {
+				assert.False(t, finished.StartedAt.IsZero())
+				assert.NotNil(t, finished.EndedAt)
+				finished.StartedAt, finished.EndedAt = test.wantRun.StartedAt, nil
+			}
+			assert.Equal(t, test.wantRun, finished)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceImpl_EnqueueJob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(ctrl *gomock.Controller) *ServiceImpl
+		operation  string
+		bk         *model.Book
+		wantJob    *model.Job
+		wantErr    error
+	}{
+		{
+			name: "enqueue site job",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					job.ID = 1
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationExplore,
+			wantJob: &model.Job{
+				ID: 1, Site: "test", Operation: model.JobOperationExplore,
+				Status: model.JobOutcomeQueued,
+			},
+		},
+		{
+			name: "enqueue book job",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					job.ID = 2
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationProcess,
+			bk:        &model.Book{Site: "test", ID: 3, HashCode: 4},
+			wantJob: &model.Job{
+				ID: 2, Site: "test", Operation: model.JobOperationProcess,
+				BookID: 3, HashCode: 4, Status: model.JobOutcomeQueued,
+			},
+		},
+		{
+			name: "unknown operation",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{name: "test", rpo: repomock.NewMockRepository(ctrl)}
+			},
+			operation: model.JobOperationPatchStatus,
+			wantErr:   serv.ErrUnknownOperation,
+		},
+		{
+			name: "create job fail",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().CreateJob(gomock.Any()).Return(serv.ErrUnavailable)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationUpdate,
+			wantErr:   serv.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := test.getService(ctrl)
+
+			job, err := s.EnqueueJob(context.Background(), test.operation, test.bk)
+			assert.ErrorIs(t, err, test.wantErr)
+
+			if job != nil {
+				assert.False(t, job.CreatedAt.IsZero())
+				job.CreatedAt = test.wantJob.CreatedAt
+			}
+			assert.Equal(t, test.wantJob, job)
+		})
+	}
+}
+
+func TestServiceImpl_RunQueuedJobs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		getService   func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl
+		wantFinished []model.Job
+		wantErr      bool
+	}{
+		{
+			name: "run site and book jobs until no job queued",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+						ID: 1, Site: "test", Operation: model.JobOperationValidate,
+						Status: model.JobOutcomeRunning,
+					}, nil),
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+						ID: 2, Site: "test", Operation: model.JobOperationValidate,
+						BookID: 3, HashCode: 4, Status: model.JobOutcomeRunning,
+					}, nil),
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(nil, nil),
+				)
+
+				rpo.EXPECT().LockOperation(model.JobOperationValidate).Return(func() error { return nil }, nil)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).Return(nil)
+				rpo.EXPECT().UpdateBooksStatus().Return(errors.New("some error"))
+				rpo.EXPECT().FinishJobRun(gomock.Any()).Return(nil)
+
+				rpo.EXPECT().FindBookByIdHash(3, 4).Return(&model.Book{
+					Site: "test", ID: 3, HashCode: 4, Status: model.StatusEnd,
+				}, nil)
+
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return nil
+				}).Times(2)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: model.JobOperationValidate,
+					Status: model.JobOutcomeFail, Error: "some error",
+				},
+				{
+					ID: 2, Site: "test", Operation: model.JobOperationValidate,
+					BookID: 3, HashCode: 4, Status: model.JobOutcomeSuccess,
+				},
+			},
+		},
+		{
+			name: "book job fail if book not found",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+						ID: 1, Site: "test", Operation: model.JobOperationDownload,
+						BookID: 3, HashCode: 4, Status: model.JobOutcomeRunning,
+					}, nil),
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(nil, nil),
+				)
+
+				rpo.EXPECT().FindBookByIdHash(3, 4).Return(nil, errors.New("not found"))
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: model.JobOperationDownload,
+					BookID: 3, HashCode: 4, Status: model.JobOutcomeFail,
+					Error: "find book fail: not found",
+				},
+			},
+		},
+		{
+			name: "site job fail if operation is running by other worker",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+						ID: 1, Site: "test", Operation: model.JobOperationExplore,
+						Status: model.JobOutcomeRunning,
+					}, nil),
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(nil, nil),
+				)
+				rpo.EXPECT().LockOperation(model.JobOperationExplore).Return(nil, repo.ErrOperationLocked)
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return nil
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: model.JobOperationExplore,
+					Status: model.JobOutcomeFail, Error: "explore: " + serv.ErrOperationRunning.Error(),
+					Stats: new(serv.UpdateStats).Snapshot(),
+				},
+			},
+		},
+		{
+			name: "claim job fail",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(nil, errors.New("some error"))
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantErr: true,
+		},
+		{
+			name: "fail job with lease expired in all attempts",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("worker", time.Minute).Return(&model.Job{
+						ID: 1, Site: "test", Operation: model.JobOperationValidate,
+						Status: model.JobOutcomeRunning, Attempts: 3, LeaseOwner: "worker",
+					}, nil),
+					rpo.EXPECT().ClaimJob("worker", time.Minute).Return(nil, nil),
+				)
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return nil
+				})
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, workerID: "worker",
+					conf: config.SiteConfig{JobConfig: config.JobConfig{LeaseDuration: time.Minute, MaxAttempts: 2}},
+				}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: model.JobOperationValidate,
+					Status: model.JobOutcomeFail, Error: serv.ErrJobLeaseExpired.Error(),
+					Attempts: 3, LeaseOwner: "worker",
+				},
+			},
+		},
+		{
+			name: "queue interrupted job again without counting its attempt",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("worker", time.Minute).Return(&model.Job{
+						ID: 1, Site: "test", Operation: model.JobOperationDownload,
+						BookID: 3, HashCode: 4, Status: model.JobOutcomeRunning,
+						Attempts: 2, LeaseOwner: "worker",
+					}, nil),
+					rpo.EXPECT().ClaimJob("worker", time.Minute).Return(nil, nil),
+				)
+				rpo.EXPECT().FindBookByIdHash(3, 4).Return(nil, context.Canceled)
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return nil
+				})
+
+				return &ServiceImpl{
+					name: "test", rpo: rpo, workerID: "worker",
+					conf: config.SiteConfig{JobConfig: config.JobConfig{LeaseDuration: time.Minute, MaxAttempts: 2}},
+				}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: model.JobOperationDownload,
+					BookID: 3, HashCode: 4, Status: model.JobOutcomeQueued,
+					Error: "find book fail: context canceled", Attempts: 1, LeaseOwner: "worker",
+				},
+			},
+		},
+		{
+			name: "continue if job was claimed by another worker",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				gomock.InOrder(
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+						ID: 1, Site: "test", Operation: "unknown", Status: model.JobOutcomeRunning,
+					}, nil),
+					rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(nil, nil),
+				)
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return fmt.Errorf("fail to finish job: %w", repo.ErrJobLeaseLost)
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: "unknown",
+					Status: model.JobOutcomeFail, Error: serv.ErrUnknownOperation.Error(),
+				},
+			},
+		},
+		{
+			name: "finish job fail",
+			getService: func(ctrl *gomock.Controller, finished *[]model.Job) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().ClaimJob("", config.DefaultJobLeaseDuration).Return(&model.Job{
+					ID: 1, Site: "test", Operation: "unknown", Status: model.JobOutcomeRunning,
+				}, nil)
+				rpo.EXPECT().FinishJob(gomock.Any()).DoAndReturn(func(job *model.Job) error {
+					*finished = append(*finished, *job)
+					return errors.New("some error")
+				})
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			wantFinished: []model.Job{
+				{
+					ID: 1, Site: "test", Operation: "unknown",
+					Status: model.JobOutcomeFail, Error: serv.ErrUnknownOperation.Error(),
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var finished []model.Job
+			s := test.getService(ctrl, &finished)
+
+			err := s.RunQueuedJobs(context.Background())
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			for i := range finished {
+				if finished[i].Status != model.JobOutcomeQueued {
+					assert.NotNil(t, finished[i].EndedAt)
+				}
+				finished[i].EndedAt = nil
+			}
+			assert.Equal(t, test.wantFinished, finished)
+		})
+	}
+}
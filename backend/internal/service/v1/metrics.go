@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/metrics"
+	serv "github.com/htchan/BookSpider/internal/service"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+)
+
+// parseFailureFieldErrs map the field name reported to metrics to the vendor
+// errors of the field. url class is reported along with field, so the same
+// field name is shared by different pages
+var parseFailureFieldErrs = []struct {
+	field string
+	errs  []error
+}{
+	{"title", []error{vendor.ErrBookTitleNotFound, vendor.ErrChapterListTitleNotFound, vendor.ErrChapterTitleNotFound}},
+	{"writer", []error{vendor.ErrBookWriterNotFound}},
+	{"type", []error{vendor.ErrBookTypeNotFound}},
+	{"date", []error{vendor.ErrBookDateNotFound}},
+	{"chapter", []error{vendor.ErrBookChapterNotFound}},
+	{"url", []error{vendor.ErrChapterListUrlNotFound}},
+	{"content", []error{vendor.ErrChapterContentNotFound}},
+}
+
+// parseFailureFields return the fields failed to be parsed. vendors join the
+// errors of all missing fields, so multiple fields can be returned
+func parseFailureFields(err error) []string {
+	var fields []string
+	for _, fieldErrs := range parseFailureFieldErrs {
+		for _, fieldErr := range fieldErrs.errs {
+			if errors.Is(err, fieldErr) {
+				fields = append(fields, fieldErrs.field)
+
+				break
+			}
+		}
+	}
+
+	if len(fields) == 0 {
+		fields = append(fields, "unknown")
+	}
+
+	return fields
+}
+
+// fetch get the page of given url class and report the result to metrics
+func (s *ServiceImpl) fetch(ctx context.Context, class client.URLClass, url string) (*client.Response, error) {
+	res, err := client.GetResponse(client.WithURLClass(ctx, class), s.cli, url)
+
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFail
+	}
+
+	metrics.PagesFetched.WithLabelValues(s.name, string(class), result).Inc()
+	metrics.FetchDuration.WithLabelValues(s.name, string(class)).Observe(res.Elapsed.Seconds())
+
+	return res, err
+}
+
+// reportParseFailure report the fields failed to be parsed to metrics, evict
+// the page of url from cache and let circuit breaker count the page, as vendor
+// may return anti-bot page with success status
+func (s *ServiceImpl) reportParseFailure(ctx context.Context, class client.URLClass, url string, res *client.Response, err error) {
+	for _, field := range parseFailureFields(err) {
+		metrics.ParseFailures.WithLabelValues(s.name, string(class), field).Inc()
+	}
+
+	if s.cache != nil {
+		s.cache.ReportParseResult(ctx, url, err)
+	}
+
+	if s.breaker != nil {
+		s.breaker.ReportParseResult(class, res, err)
+	}
+}
+
+func (s *ServiceImpl) reportChapterDownload(err error) {
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFail
+	}
+
+	metrics.ChaptersDownloaded.WithLabelValues(s.name, result).Inc()
+}
+
+func (s *ServiceImpl) reportUpdateStats(operation string, stats *serv.UpdateStats) {
+	for outcome, count := range stats.Snapshot() {
+		metrics.BookUpdates.WithLabelValues(s.name, operation, outcome).Add(float64(count))
+	}
+}
+
+func (s *ServiceImpl) reportDownloadStats(stats *serv.DownloadStats) {
+	for outcome, count := range stats.Snapshot() {
+		metrics.BookDownloads.WithLabelValues(s.name, outcome).Add(float64(count))
+	}
+}
+
+func (s *ServiceImpl) reportPatchStorageStats(stats *serv.PatchStorageStats) {
+	for outcome, count := range stats.Snapshot() {
+		metrics.StoragePatches.WithLabelValues(s.name, outcome).Add(float64(count))
+	}
+}
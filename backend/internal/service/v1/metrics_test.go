@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/client/v2/cache"
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	"github.com/htchan/BookSpider/internal/metrics"
+	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
+	serv "github.com/htchan/BookSpider/internal/service"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseFailureFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "single field",
+			err:  vendor.ErrChapterContentNotFound,
+			want: []string{"content"},
+		},
+		{
+			name: "joined fields",
+			err: errors.Join(
+				vendor.ErrBookTitleNotFound, vendor.ErrBookDateNotFound,
+				vendor.ErrFieldsNotFound,
+			),
+			want: []string{"title", "date"},
+		},
+		{
+			name: "wrapped field",
+			err:  fmt.Errorf("parse chapter url fail: %d, %w", 1, vendor.ErrChapterListUrlNotFound),
+			want: []string{"url"},
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("unknown"),
+			want: []string{"unknown"},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.want, parseFailureFields(test.err))
+		})
+	}
+}
+
+func TestServiceImpl_fetch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		siteName   string
+		getErr     error
+		wantResult string
+	}{
+		{
+			name:       "report success",
+			siteName:   "test-fetch-success",
+			wantResult: metrics.ResultSuccess,
+		},
+		{
+			name:       "report fail",
+			siteName:   "test-fetch-fail",
+			getErr:     serv.ErrUnavailable,
+			wantResult: metrics.ResultFail,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cli := clientmock.NewMockBookClient(ctrl)
+			cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("body", test.getErr)
+
+			s := &ServiceImpl{name: test.siteName, cli: cli}
+			res, err := s.fetch(context.Background(), client.URLClassBook, "https://test.com")
+			assert.ErrorIs(t, err, test.getErr)
+			assert.Equal(t, "body", res.Body)
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(
+				metrics.PagesFetched.WithLabelValues(test.siteName, string(client.URLClassBook), test.wantResult),
+			))
+		})
+	}
+}
+
+func TestServiceImpl_reportParseFailure(t *testing.T) {
+	t.Parallel()
+
+	newBreaker := func() *circuitbreaker.CircuitBreakerClient {
+		return circuitbreaker.NewClient(
+			&circuitbreaker.CircuitBreakerClientConfig{
+				OpenThreshold:         1,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 2,
+				RecoverThreads:        []int64{1},
+				OpenDuration:          time.Minute,
+				RecoverDuration:       time.Minute,
+				CheckConfigs: []circuitbreaker.CheckConfig{
+					{Type: circuitbreaker.CheckTypeParseFailure, Value: []interface{}{"chapter"}},
+				},
+			},
+			nil,
+		)
+	}
+
+	tests := []struct {
+		name       string
+		serv       *ServiceImpl
+		class      client.URLClass
+		wantStatus circuitbreaker.CircuitBreakerStatus
+	}{
+		{
+			name:       "open circuit breaker with parse failure of checked class",
+			serv:       &ServiceImpl{name: "test-parse-failure-checked", breaker: newBreaker()},
+			class:      client.URLClassChapter,
+			wantStatus: circuitbreaker.StatusOpen,
+		},
+		{
+			name:       "ignore parse failure of unchecked class",
+			serv:       &ServiceImpl{name: "test-parse-failure-unchecked", breaker: newBreaker()},
+			class:      client.URLClassBook,
+			wantStatus: circuitbreaker.StatusClosed,
+		},
+		{
+			name:       "no circuit breaker",
+			serv:       &ServiceImpl{name: "test-parse-failure-no-breaker"},
+			class:      client.URLClassChapter,
+			wantStatus: "",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			test.serv.reportParseFailure(context.Background(), test.class, "https://test.com/chapter/1", &client.Response{}, errors.Join(vendor.ErrChapterTitleNotFound, vendor.ErrChapterContentNotFound))
+			if test.serv.breaker != nil {
+				assert.Equal(t, test.wantStatus, test.serv.CircuitBreakerState().Status)
+			}
+
+			for _, field := range []string{"title", "content"} {
+				assert.Equal(t, 1.0, testutil.ToFloat64(
+					metrics.ParseFailures.WithLabelValues(test.serv.name, string(test.class), field),
+				))
+			}
+		})
+	}
+}
+
+func TestServiceImpl_reportParseFailure_EvictCache(t *testing.T) {
+	t.Parallel()
+
+	const url = "https://test.com/book/1"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bookClient := clientmock.NewMockBookClient(ctrl)
+	bookClient.EXPECT().Get(gomock.Any(), url).Return("anti bot", nil).Times(2)
+
+	cacheClient := cache.NewClient(&cache.CacheClientConfig{Dir: t.TempDir(), BookTTL: time.Hour}, bookClient)
+	s := &ServiceImpl{name: "test-parse-failure-evict-cache", cli: cacheClient, cache: cacheClient}
+	ctx := client.WithURLClass(context.Background(), client.URLClassBook)
+
+	_, err := s.cli.Get(ctx, url)
+	assert.NoError(t, err)
+
+	s.reportParseFailure(ctx, client.URLClassBook, url, &client.Response{}, vendor.ErrBookTitleNotFound)
+
+	// the page is fetched again instead of served from cache
+	_, err = s.cli.Get(ctx, url)
+	assert.NoError(t, err)
+}
+
+func TestServiceImpl_reportUpdateStats(t *testing.T) {
+	t.Parallel()
+
+	s := &ServiceImpl{name: "test-report-update-stats"}
+	stats := new(serv.UpdateStats)
+	stats.Total.Add(3)
+	stats.Fail.Add(1)
+
+	s.reportUpdateStats("update", stats)
+	s.reportUpdateStats("update", stats)
+
+	assert.Equal(t, 6.0, testutil.ToFloat64(metrics.BookUpdates.WithLabelValues(s.name, "update", "total")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.BookUpdates.WithLabelValues(s.name, "update", "fail")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.BookUpdates.WithLabelValues(s.name, "update", "unchanged")))
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/schedule"
+	serv "github.com/htchan/BookSpider/internal/service"
+)
+
+// RunOperation run the operation on whole site, it is called by scheduler.
+// serv.ErrOperationRunning is returned without running if the operation is
+// run by any worker already, as scheduled run or queued job. update and
+// download are not locked, as all workers share their book tasks
+func (s *ServiceImpl) RunOperation(ctx context.Context, operation string) error {
+	if !model.IsSchedulableJobOperation(operation) {
+		return fmt.Errorf("run operation fail: %w", serv.ErrUnknownOperation)
+	}
+
+	_, err := s.runSiteJob(ctx, operation)
+
+	return err
+}
+
+// Schedules return the schedules of site ordered by operation, with the next
+// count run times after from
+func (s *ServiceImpl) Schedules(ctx context.Context, from time.Time, count int) ([]model.Schedule, error) {
+	schedules := make([]model.Schedule, 0, len(s.conf.ScheduleConfig.WithDefaults()))
+
+	for operation, expr := range s.conf.ScheduleConfig.WithDefaults() {
+		cron, err := schedule.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("parse schedule of %s fail: %w", operation, err)
+		}
+
+		schedules = append(schedules, model.Schedule{
+			Site:      s.name,
+			Operation: operation,
+			Cron:      cron.String(),
+			NextRuns:  cron.NextN(from.UTC(), count),
+		})
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Operation < schedules[j].Operation
+	})
+
+	return schedules, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	clientmock "github.com/htchan/BookSpider/internal/mock/client/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	vendormock "github.com/htchan/BookSpider/internal/mock/vendorservice"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/htchan/BookSpider/internal/repo"
+	"github.com/htchan/BookSpider/internal/schedule"
+	serv "github.com/htchan/BookSpider/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceImpl_RunOperation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		getService func(ctrl *gomock.Controller) *ServiceImpl
+		operation  string
+		wantErr    error
+	}{
+		{
+			name: "run site operation",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LockOperation(model.JobOperationValidate).Return(func() error { return nil }, nil)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).Return(nil)
+				rpo.EXPECT().UpdateBooksStatus().Return(nil)
+				rpo.EXPECT().FinishJobRun(gomock.Any()).Return(nil)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationValidate,
+		},
+		{
+			name: "return error of operation",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LockOperation(model.JobOperationValidate).Return(func() error { return nil }, nil)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).Return(nil)
+				rpo.EXPECT().UpdateBooksStatus().Return(serv.ErrUnavailable)
+				rpo.EXPECT().FinishJobRun(gomock.Any()).Return(nil)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationValidate,
+			wantErr:   serv.ErrUnavailable,
+		},
+		{
+			name: "run operation not queueable",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				cli := clientmock.NewMockBookClient(ctrl)
+				vendorService := vendormock.NewMockVendorService(ctrl)
+				rpo.EXPECT().CreateJobRun(gomock.Any()).Return(nil)
+				vendorService.EXPECT().AvailabilityURL().Return("https://test.com")
+				cli.EXPECT().Get(gomock.Any(), "https://test.com").Return("body", nil)
+				vendorService.EXPECT().IsAvailable("body").Return(true)
+				rpo.EXPECT().FinishJobRun(gomock.Any()).Return(nil)
+
+				return &ServiceImpl{name: "test", rpo: rpo, cli: cli, vendorService: vendorService}
+			},
+			operation: model.JobOperationCheckAvailability,
+		},
+		{
+			name: "unknown operation",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				return &ServiceImpl{name: "test", rpo: repomock.NewMockRepository(ctrl)}
+			},
+			operation: "unknown",
+			wantErr:   serv.ErrUnknownOperation,
+		},
+		{
+			name: "operation is running by other worker",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LockOperation(model.JobOperationValidate).Return(nil, repo.ErrOperationLocked)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationValidate,
+			wantErr:   serv.ErrOperationRunning,
+		},
+		{
+			name: "process is running by other worker",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := repomock.NewMockRepository(ctrl)
+				rpo.EXPECT().LockOperation(model.JobOperationProcess).Return(nil, repo.ErrOperationLocked)
+
+				return &ServiceImpl{name: "test", rpo: rpo}
+			},
+			operation: model.JobOperationProcess,
+			wantErr:   serv.ErrOperationRunning,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			err := test.getService(ctrl).RunOperation(context.Background(), test.operation)
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestServiceImpl_Schedules(t *testing.T) {
+	t.Parallel()
+
+	// 2020-01-01 is wednesday
+	from := time.Date(2020, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		conf          config.ScheduleConfig
+		count         int
+		wantSchedules []model.Schedule
+		wantErr       error
+	}{
+		{
+			name:  "schedules ordered by operation",
+			conf:  config.ScheduleConfig{"update": "0 3 * * *", "explore": "0 0 * * sun"},
+			count: 2,
+			wantSchedules: []model.Schedule{
+				{
+					Site: "test", Operation: "explore", Cron: "0 0 * * sun",
+					NextRuns: []time.Time{
+						time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC),
+						time.Date(2020, 1, 12, 0, 0, 0, 0, time.UTC),
+					},
+				},
+				{
+					Site: "test", Operation: "update", Cron: "0 3 * * *",
+					NextRuns: []time.Time{
+						time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC),
+						time.Date(2020, 1, 3, 3, 0, 0, 0, time.UTC),
+					},
+				},
+			},
+		},
+		{
+			name:  "default schedule",
+			conf:  nil,
+			count: 1,
+			wantSchedules: []model.Schedule{
+				{
+					Site: "test", Operation: "process", Cron: config.DefaultProcessSchedule,
+					NextRuns: []time.Time{time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			name:    "invalid cron expression",
+			conf:    config.ScheduleConfig{"update": "daily"},
+			count:   1,
+			wantErr: schedule.ErrInvalidCron,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := &ServiceImpl{name: "test", conf: config.SiteConfig{ScheduleConfig: test.conf}}
+
+			schedules, err := s.Schedules(context.Background(), from, test.count)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantSchedules, schedules)
+		})
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"sync"
 
 	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/client/v2/cache"
 	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	ratelimit "github.com/htchan/BookSpider/internal/client/v2/rate_limit"
+	"github.com/htchan/BookSpider/internal/client/v2/replay"
 	"github.com/htchan/BookSpider/internal/client/v2/retry"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
 	"github.com/htchan/BookSpider/internal/config/v2"
@@ -24,11 +27,15 @@ import (
 type ServiceImpl struct {
 	name          string
 	cli           client.BookClient
+	cache         *cache.CacheClient
+	breaker       *circuitbreaker.CircuitBreakerClient
 	rpo           repo.Repository
 	vendorService vendor.VendorService
 
 	conf config.SiteConfig
 	sema *semaphore.Weighted
+	// owner of the book tasks and jobs leased by this service
+	workerID string
 }
 
 var _ serv.Service = (*ServiceImpl)(nil)
@@ -38,20 +45,33 @@ func NewService(
 	vendorService vendor.VendorService,
 	sema *semaphore.Weighted, conf config.SiteConfig,
 ) *ServiceImpl {
-	return &ServiceImpl{
-		name: name,
-		cli: retry.NewClient(
-			&conf.ClientConfig.Retry,
-			circuitbreaker.NewClient(
-				&conf.ClientConfig.CircuitBreaker,
+	breaker := circuitbreaker.NewClient(
+		&conf.ClientConfig.CircuitBreaker,
+		ratelimit.NewClient(
+			&conf.ClientConfig.RateLimit,
+			replay.NewClient(
+				&conf.ClientConfig.Replay,
 				simple.NewClient(&conf.ClientConfig.Simple),
 			),
 		),
+	).WithName(name)
+
+	cacheClient := cache.NewClient(
+		&conf.ClientConfig.Cache,
+		retry.NewClient(&conf.ClientConfig.Retry, breaker).WithName(name),
+	)
+
+	return &ServiceImpl{
+		name:          name,
+		cli:           cacheClient,
+		cache:         cacheClient,
+		breaker:       breaker,
 		rpo:           rpo,
 		vendorService: vendorService,
 
-		sema: sema,
-		conf: conf,
+		sema:     sema,
+		conf:     conf,
+		workerID: newWorkerID(),
 	}
 }
 
@@ -97,6 +117,7 @@ func (s *ServiceImpl) PatchDownloadStatus(ctx context.Context, stats *serv.Patch
 	if stats == nil {
 		stats = new(serv.PatchStorageStats)
 	}
+	defer s.reportPatchStorageStats(stats)
 
 	bks, err := s.rpo.FindAllBooks()
 	if err != nil {
@@ -104,11 +125,14 @@ func (s *ServiceImpl) PatchDownloadStatus(ctx context.Context, stats *serv.Patch
 	}
 
 	var wg sync.WaitGroup
+	var interruptErr error
 	zerolog.Ctx(ctx).Info().Str("site", s.name).Msg("update books is_downloaded by storage")
 
 	for bk := range bks {
 		bk := bk
-		s.sema.Acquire(ctx, 1)
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
 		wg.Add(1)
 
 		go func(bk *model.Book) {
@@ -131,6 +155,14 @@ func (s *ServiceImpl) PatchDownloadStatus(ctx context.Context, stats *serv.Patch
 
 	wg.Wait()
 
+	if interruptErr != nil {
+		// let the repository stop sending the remaining books
+		for range bks {
+		}
+
+		return fmt.Errorf("patch download status interrupted: %w", interruptErr)
+	}
+
 	return nil
 }
 
@@ -142,6 +174,7 @@ func (s *ServiceImpl) PatchMissingRecords(ctx context.Context, stats *serv.Updat
 	}
 
 	var wg sync.WaitGroup
+	var interruptErr error
 	allBkIDs, err := s.rpo.FindAllBookIDs()
 	if err != nil {
 		return fmt.Errorf("find all book ids fail: %w", err)
@@ -150,7 +183,9 @@ func (s *ServiceImpl) PatchMissingRecords(ctx context.Context, stats *serv.Updat
 	missingIDs := s.vendorService.FindMissingIds(allBkIDs)
 	for _, bookID := range missingIDs {
 		bookID := bookID
-		s.sema.Acquire(ctx, 1)
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
 		wg.Add(1)
 		stats.Total.Add(1)
 
@@ -165,6 +200,89 @@ func (s *ServiceImpl) PatchMissingRecords(ctx context.Context, stats *serv.Updat
 	}
 	wg.Wait()
 
+	if interruptErr != nil {
+		return fmt.Errorf("patch missing records interrupted: %w", interruptErr)
+	}
+
+	return nil
+}
+
+// PatchBookChapters load the chapters of book downloaded before chapters were
+// stored from its file, so the book can be found by content search
+func (s *ServiceImpl) PatchBookChapters(ctx context.Context, bk *model.Book) error {
+	chapters, err := s.rpo.FindChaptersByBook(bk)
+	if err != nil {
+		return fmt.Errorf("find chapters fail: %w", err)
+	}
+
+	if len(chapters) > 0 {
+		return nil
+	}
+
+	return s.saveChaptersFromFile(ctx, bk)
+}
+
+func (s *ServiceImpl) PatchChapters(ctx context.Context) error {
+	bks, err := s.rpo.FindDownloadedBooksWithoutChapters()
+	if err != nil {
+		return fmt.Errorf("patch chapters fail: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	var interruptErr error
+	zerolog.Ctx(ctx).Info().Str("site", s.name).Msg("load chapters of downloaded books from storage")
+
+	for bk := range bks {
+		bk := bk
+		if interruptErr = s.sema.Acquire(ctx, 1); interruptErr != nil {
+			break
+		}
+		wg.Add(1)
+
+		go func(bk *model.Book) {
+			defer wg.Done()
+			defer s.sema.Release(1)
+
+			err := s.saveChaptersFromFile(ctx, bk)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).
+					Str("site", s.name).
+					Int("bk_id", bk.ID).
+					Str("bk_hash_code", bk.FormatHashCode()).
+					Msg("patch chapters fail")
+			}
+		}(&bk)
+	}
+
+	wg.Wait()
+
+	if interruptErr != nil {
+		// let the repository stop sending the remaining books
+		for range bks {
+		}
+
+		return fmt.Errorf("patch chapters interrupted: %w", interruptErr)
+	}
+
+	return nil
+}
+
+func (s *ServiceImpl) saveChaptersFromFile(ctx context.Context, bk *model.Book) error {
+	content, err := s.BookContent(ctx, bk)
+	if err != nil {
+		return fmt.Errorf("load content fail: %w", err)
+	}
+
+	chapters, err := model.StringToChapters(content)
+	if err != nil {
+		return fmt.Errorf("parse chapters fail: %w", err)
+	}
+
+	err = s.rpo.SaveChapters(bk, chapters)
+	if err != nil {
+		return fmt.Errorf("save chapters fail: %w", err)
+	}
+
 	return nil
 }
 
@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/htchan/BookSpider/internal/client/v2/cache"
 	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	ratelimit "github.com/htchan/BookSpider/internal/client/v2/rate_limit"
+	"github.com/htchan/BookSpider/internal/client/v2/replay"
 	"github.com/htchan/BookSpider/internal/client/v2/retry"
 	"github.com/htchan/BookSpider/internal/client/v2/simple"
 	"github.com/htchan/BookSpider/internal/config/v2"
@@ -35,6 +38,13 @@ func TestMain(m *testing.M) {
 	}
 }
 
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	return ctx
+}
+
 func TestNewService(t *testing.T) {
 	t.Parallel()
 
@@ -49,15 +59,29 @@ func TestNewService(t *testing.T) {
 	}{
 		{
 			name: "happy flow",
-			want: &ServiceImpl{
-				cli: retry.NewClient(
-					&retry.RetryClientConfig{},
-					circuitbreaker.NewClient(
-						&circuitbreaker.CircuitBreakerClientConfig{},
-						simple.NewClient(&simple.SimpleClientConfig{}),
+			want: func() *ServiceImpl {
+				breaker := circuitbreaker.NewClient(
+					&circuitbreaker.CircuitBreakerClientConfig{},
+					ratelimit.NewClient(
+						&ratelimit.RateLimitClientConfig{},
+						replay.NewClient(
+							&replay.ReplayClientConfig{},
+							simple.NewClient(&simple.SimpleClientConfig{}),
+						),
 					),
-				),
-			},
+				)
+
+				cacheClient := cache.NewClient(
+					&cache.CacheClientConfig{},
+					retry.NewClient(&retry.RetryClientConfig{}, breaker),
+				)
+
+				return &ServiceImpl{
+					cli:     cacheClient,
+					cache:   cacheClient,
+					breaker: breaker,
+				}
+			}(),
 		},
 	}
 
@@ -67,6 +91,8 @@ func TestNewService(t *testing.T) {
 			t.Parallel()
 
 			got := NewService(test.siteName, test.rpo, test.vendorService, test.sema, test.conf)
+			assert.NotEmpty(t, got.workerID)
+			got.workerID = ""
 			assert.Equal(t, test.want, got)
 		})
 	}
@@ -229,6 +255,7 @@ func TestServiceImpl_PatchDownloadStatus(t *testing.T) {
 	tests := []struct {
 		name       string
 		getService func(*gomock.Controller) *ServiceImpl
+		ctx        context.Context
 		wantError  error
 	}{
 		{
@@ -270,6 +297,29 @@ func TestServiceImpl_PatchDownloadStatus(t *testing.T) {
 			},
 			wantError: service.ErrUnavailable,
 		},
+		{
+			name: "interrupted by ctx",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+
+				bkCh := make(chan model.Book)
+				go func() {
+					bkCh <- model.Book{ID: 123, HashCode: 0, IsDownloaded: true}
+					bkCh <- model.Book{ID: 456, HashCode: 0, IsDownloaded: true}
+					close(bkCh)
+				}()
+
+				rpo.EXPECT().FindAllBooks().Return(bkCh, nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./patch-download-status"},
+					rpo:  rpo,
+					sema: semaphore.NewWeighted(1),
+				}
+			},
+			ctx:       cancelledContext(),
+			wantError: context.Canceled,
+		},
 	}
 
 	for _, test := range tests {
@@ -280,7 +330,12 @@ func TestServiceImpl_PatchDownloadStatus(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			err := test.getService(ctrl).PatchDownloadStatus(context.Background(), nil)
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := test.getService(ctrl).PatchDownloadStatus(ctx, nil)
 			assert.ErrorIs(t, err, test.wantError)
 		})
 	}
@@ -292,6 +347,7 @@ func TestServiceImpl_PatchMissingRecords(t *testing.T) {
 	tests := []struct {
 		name       string
 		getService func(*gomock.Controller) *ServiceImpl
+		ctx        context.Context
 		wantError  error
 		wantStats  func() *serv.UpdateStats
 	}{
@@ -357,6 +413,28 @@ func TestServiceImpl_PatchMissingRecords(t *testing.T) {
 				return new(serv.UpdateStats)
 			},
 		},
+		{
+			name: "interrupted by ctx",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				vendorService := mockvendor.NewMockVendorService(ctrl)
+
+				rpo.EXPECT().FindAllBookIDs().Return([]int{1, 2, 4}, nil)
+				vendorService.EXPECT().FindMissingIds([]int{1, 2, 4}).Return([]int{3})
+
+				return &ServiceImpl{
+					name:          "serv",
+					rpo:           rpo,
+					vendorService: vendorService,
+					sema:          semaphore.NewWeighted(1),
+				}
+			},
+			ctx:       cancelledContext(),
+			wantError: context.Canceled,
+			wantStats: func() *serv.UpdateStats {
+				return new(serv.UpdateStats)
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -367,8 +445,202 @@ func TestServiceImpl_PatchMissingRecords(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
 			stats := new(serv.UpdateStats)
-			err := test.getService(ctrl).PatchMissingRecords(context.Background(), stats)
+			err := test.getService(ctrl).PatchMissingRecords(ctx, stats)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestServiceImpl_PatchBookChapters(t *testing.T) {
+	t.Parallel()
+
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll("./patch-book-chapters"))
+	})
+
+	if !assert.NoError(t, os.Mkdir("./patch-book-chapters", os.ModePerm)) ||
+		!assert.NoError(t, os.WriteFile("./patch-book-chapters/123.txt", []byte(
+			"test"+model.CONTENT_SEP+
+				"title 1"+model.CONTENT_SEP+"content 1"+model.CONTENT_SEP+
+				"title 2"+model.CONTENT_SEP+"content 2"+model.CONTENT_SEP,
+		), 0644)) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		bk         *model.Book
+		wantError  error
+	}{
+		{
+			name: "load chapters from file",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(nil, nil)
+				rpo.EXPECT().SaveChapters(&model.Book{ID: 123, IsDownloaded: true}, model.Chapters{
+					{Index: 0, Title: "title 1", Content: "content 1"},
+					{Index: 1, Title: "title 2", Content: "content 2"},
+				}).Return(nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./patch-book-chapters"}}
+			},
+			bk:        &model.Book{ID: 123, IsDownloaded: true},
+			wantError: nil,
+		},
+		{
+			name: "skip book with chapters in repository",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(model.Chapters{
+					{Index: 0, Title: "db title 1", Content: "db content 1"},
+				}, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./patch-book-chapters"}}
+			},
+			bk:        &model.Book{ID: 123, IsDownloaded: true},
+			wantError: nil,
+		},
+		{
+			name: "book not downloaded",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123}).Return(nil, nil)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./patch-book-chapters"}}
+			},
+			bk:        &model.Book{ID: 123},
+			wantError: service.ErrBookNotDownload,
+		},
+		{
+			name: "FindChaptersByBook returns error",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+				rpo.EXPECT().FindChaptersByBook(&model.Book{ID: 123, IsDownloaded: true}).Return(nil, service.ErrUnavailable)
+
+				return &ServiceImpl{rpo: rpo, conf: config.SiteConfig{Storage: "./patch-book-chapters"}}
+			},
+			bk:        &model.Book{ID: 123, IsDownloaded: true},
+			wantError: service.ErrUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			err := test.getService(ctrl).PatchBookChapters(context.Background(), test.bk)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestServiceImpl_PatchChapters(t *testing.T) {
+	t.Parallel()
+
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll("./patch-chapters"))
+	})
+
+	if !assert.NoError(t, os.Mkdir("./patch-chapters", os.ModePerm)) ||
+		!assert.NoError(t, os.WriteFile("./patch-chapters/123.txt", []byte(
+			"test"+model.CONTENT_SEP+"title 1"+model.CONTENT_SEP+"content 1"+model.CONTENT_SEP,
+		), 0644)) {
+		return
+	}
+
+	tests := []struct {
+		name       string
+		getService func(*gomock.Controller) *ServiceImpl
+		ctx        context.Context
+		wantError  error
+	}{
+		{
+			name: "happy flow",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+
+				bkCh := make(chan model.Book, 10)
+				bkCh <- model.Book{ID: 123, HashCode: 0, IsDownloaded: true}
+				bkCh <- model.Book{ID: 456, HashCode: 0, IsDownloaded: true}
+				close(bkCh)
+
+				rpo.EXPECT().FindDownloadedBooksWithoutChapters().Return(bkCh, nil)
+				rpo.EXPECT().SaveChapters(&model.Book{ID: 123, HashCode: 0, IsDownloaded: true}, model.Chapters{
+					{Index: 0, Title: "title 1", Content: "content 1"},
+				}).Return(nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./patch-chapters"},
+					rpo:  rpo,
+					sema: semaphore.NewWeighted(1),
+				}
+			},
+			wantError: nil,
+		},
+		{
+			name: "FindDownloadedBooksWithoutChapters returns error",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+
+				rpo.EXPECT().FindDownloadedBooksWithoutChapters().Return(nil, service.ErrUnavailable)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./patch-chapters"},
+					rpo:  rpo,
+					sema: semaphore.NewWeighted(1),
+				}
+			},
+			wantError: service.ErrUnavailable,
+		},
+		{
+			name: "interrupted by ctx",
+			getService: func(ctrl *gomock.Controller) *ServiceImpl {
+				rpo := mockrepo.NewMockRepository(ctrl)
+
+				bkCh := make(chan model.Book)
+				go func() {
+					bkCh <- model.Book{ID: 123, HashCode: 0, IsDownloaded: true}
+					close(bkCh)
+				}()
+
+				rpo.EXPECT().FindDownloadedBooksWithoutChapters().Return(bkCh, nil)
+
+				return &ServiceImpl{
+					conf: config.SiteConfig{Storage: "./patch-chapters"},
+					rpo:  rpo,
+					sema: semaphore.NewWeighted(1),
+				}
+			},
+			ctx:       cancelledContext(),
+			wantError: context.Canceled,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := test.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			err := test.getService(ctrl).PatchChapters(ctx)
 			assert.ErrorIs(t, err, test.wantError)
 		})
 	}
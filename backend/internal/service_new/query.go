@@ -80,7 +80,7 @@ func (serv *ServiceImp) BookGroup(id int, hash string) (*model.Book, *model.Book
 }
 
 func (serv *ServiceImp) QueryBooks(title, writer string, limit, offset int) ([]model.Book, error) {
-	return serv.rpo.FindBooksByTitleWriter(title, writer, limit, offset)
+	return serv.rpo.SearchBooks(title, writer, limit, offset)
 }
 
 func (serv *ServiceImp) RandomBooks(limit int) ([]model.Book, error) {
@@ -118,7 +118,7 @@ func TestServiceImp_QueryBooks(t *testing.T) {
 			name: "happy flow",
 			setupServ: func(ctrl *gomock.Controller) ServiceImp {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksByTitleWriter("title", "writer", 1, 1).Return([]model.Book{
+				rpo.EXPECT().SearchBooks("title", "writer", 1, 1).Return([]model.Book{
 					{ID: 1, Title: "title", Writer: model.Writer{Name: "somebody"}},
 					{ID: 1, Title: "some text", Writer: model.Writer{Name: "writer"}},
 				}, nil)
@@ -140,7 +140,7 @@ func TestServiceImp_QueryBooks(t *testing.T) {
 			name: "getting error",
 			setupServ: func(ctrl *gomock.Controller) ServiceImp {
 				rpo := mockrepo.NewMockRepository(ctrl)
-				rpo.EXPECT().FindBooksByTitleWriter("title", "writer", 1, 1).Return(nil, errors.New("some error"))
+				rpo.EXPECT().SearchBooks("title", "writer", 1, 1).Return(nil, errors.New("some error"))
 
 				return ServiceImp{rpo: rpo}
 			},
@@ -6,21 +6,51 @@ package sqlc
 
 import (
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 type Book struct {
-	Site           string
+	Site            string
+	ID              int32
+	HashCode        int32
+	Title           sql.NullString
+	WriterID        sql.NullInt32
+	Type            sql.NullString
+	UpdateDate      sql.NullString
+	UpdateChapter   sql.NullString
+	Status          string
+	IsDownloaded    bool
+	Checksum        sql.NullString
+	WriterChecksum  sql.NullString
+	NormalizedTitle sql.NullString
+}
+
+type BookTask struct {
 	ID             int32
+	Site           string
+	Operation      string
+	BookID         int32
 	HashCode       int32
-	Title          sql.NullString
-	WriterID       sql.NullInt32
-	Type           sql.NullString
-	UpdateDate     sql.NullString
-	UpdateChapter  sql.NullString
 	Status         string
-	IsDownloaded   bool
-	Checksum       sql.NullString
-	WriterChecksum sql.NullString
+	Attempts       int32
+	AvailableAt    time.Time
+	LeaseOwner     string
+	LeaseExpiresAt sql.NullTime
+	Error          string
+	UpdatedAt      time.Time
+}
+
+type Chapter struct {
+	Site         string
+	BookID       int32
+	HashCode     int32
+	ChapterIndex int32
+	Url          string
+	Title        string
+	Content      string
+	FetchedAt    sql.NullTime
+	Error        string
 }
 
 type Error struct {
@@ -29,8 +59,37 @@ type Error struct {
 	Data sql.NullString
 }
 
+type Job struct {
+	ID             int32
+	Site           string
+	Operation      string
+	BookID         sql.NullInt32
+	HashCode       sql.NullInt32
+	Status         string
+	Error          string
+	Stats          json.RawMessage
+	CreatedAt      time.Time
+	StartedAt      sql.NullTime
+	EndedAt        sql.NullTime
+	Attempts       int32
+	LeaseOwner     string
+	LeaseExpiresAt sql.NullTime
+}
+
+type JobRun struct {
+	ID        int32
+	Site      string
+	Operation string
+	StartedAt time.Time
+	EndedAt   sql.NullTime
+	Outcome   string
+	Error     string
+	Stats     json.RawMessage
+}
+
 type Writer struct {
-	ID       int32
-	Name     sql.NullString
-	Checksum sql.NullString
+	ID             int32
+	Name           sql.NullString
+	Checksum       sql.NullString
+	NormalizedName sql.NullString
 }
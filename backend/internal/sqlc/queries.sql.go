@@ -8,6 +8,8 @@ package sqlc
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"time"
 )
 
 const booksStat = `-- name: BooksStat :one
@@ -59,28 +61,96 @@ func (q *Queries) BooksStatusStat(ctx context.Context, site string) ([]BooksStat
 	return items, nil
 }
 
+const claimJob = `-- name: ClaimJob :one
+update jobs set status='running', started_at=$1,
+  attempts=attempts+1, lease_owner=$2,
+  lease_expires_at=now() + $3::integer * interval '1 second'
+where id=(
+  select id from jobs where site=$4 and (
+    status='queued' or (status='running' and lease_expires_at<now())
+  )
+  order by id limit 1
+  for update skip locked
+)
+returning id, site, operation, book_id, hash_code, status, error, stats, created_at, started_at, ended_at, attempts, lease_owner, lease_expires_at
+`
+
+type ClaimJobParams struct {
+	StartedAt    sql.NullTime
+	LeaseOwner   string
+	LeaseSeconds int32
+	Site         string
+}
+
+func (q *Queries) ClaimJob(ctx context.Context, arg ClaimJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, claimJob,
+		arg.StartedAt,
+		arg.LeaseOwner,
+		arg.LeaseSeconds,
+		arg.Site,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Site,
+		&i.Operation,
+		&i.BookID,
+		&i.HashCode,
+		&i.Status,
+		&i.Error,
+		&i.Stats,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Attempts,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const completeBookTask = `-- name: CompleteBookTask :execrows
+update book_tasks
+set status='done', error='', lease_owner='', lease_expires_at=null, updated_at=now()
+where id=$1 and status='leased' and lease_owner=$2
+`
+
+type CompleteBookTaskParams struct {
+	ID         int32
+	LeaseOwner string
+}
+
+func (q *Queries) CompleteBookTask(ctx context.Context, arg CompleteBookTaskParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, completeBookTask, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const createBookWithHash = `-- name: CreateBookWithHash :one
 INSERT INTO books
 (site, id, hash_code, title, writer_id, writer_checksum, type, 
-update_date, update_chapter, status, is_downloaded, checksum)
+update_date, update_chapter, status, is_downloaded, checksum, normalized_title)
 VALUES
-($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
+($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, normalized_title
 `
 
 type CreateBookWithHashParams struct {
-	Site           string
-	ID             int32
-	HashCode       int32
-	Title          sql.NullString
-	WriterID       sql.NullInt32
-	WriterChecksum sql.NullString
-	Type           sql.NullString
-	UpdateDate     sql.NullString
-	UpdateChapter  sql.NullString
-	Status         string
-	IsDownloaded   bool
-	Checksum       sql.NullString
+	Site            string
+	ID              int32
+	HashCode        int32
+	Title           sql.NullString
+	WriterID        sql.NullInt32
+	WriterChecksum  sql.NullString
+	Type            sql.NullString
+	UpdateDate      sql.NullString
+	UpdateChapter   sql.NullString
+	Status          string
+	IsDownloaded    bool
+	Checksum        sql.NullString
+	NormalizedTitle sql.NullString
 }
 
 func (q *Queries) CreateBookWithHash(ctx context.Context, arg CreateBookWithHashParams) (Book, error) {
@@ -97,6 +167,7 @@ func (q *Queries) CreateBookWithHash(ctx context.Context, arg CreateBookWithHash
 		arg.Status,
 		arg.IsDownloaded,
 		arg.Checksum,
+		arg.NormalizedTitle,
 	)
 	var i Book
 	err := row.Scan(
@@ -112,6 +183,7 @@ func (q *Queries) CreateBookWithHash(ctx context.Context, arg CreateBookWithHash
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.NormalizedTitle,
 	)
 	return i, err
 }
@@ -119,24 +191,25 @@ func (q *Queries) CreateBookWithHash(ctx context.Context, arg CreateBookWithHash
 const createBookWithZeroHash = `-- name: CreateBookWithZeroHash :one
 INSERT INTO books
 (site, id, hash_code, title, writer_id, writer_checksum, type, 
-update_date, update_chapter, status, is_downloaded, checksum)
+update_date, update_chapter, status, is_downloaded, checksum, normalized_title)
 VALUES
-($1, $2, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
+($1, $2, 0, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, normalized_title
 `
 
 type CreateBookWithZeroHashParams struct {
-	Site           string
-	ID             int32
-	Title          sql.NullString
-	WriterID       sql.NullInt32
-	WriterChecksum sql.NullString
-	Type           sql.NullString
-	UpdateDate     sql.NullString
-	UpdateChapter  sql.NullString
-	Status         string
-	IsDownloaded   bool
-	Checksum       sql.NullString
+	Site            string
+	ID              int32
+	Title           sql.NullString
+	WriterID        sql.NullInt32
+	WriterChecksum  sql.NullString
+	Type            sql.NullString
+	UpdateDate      sql.NullString
+	UpdateChapter   sql.NullString
+	Status          string
+	IsDownloaded    bool
+	Checksum        sql.NullString
+	NormalizedTitle sql.NullString
 }
 
 func (q *Queries) CreateBookWithZeroHash(ctx context.Context, arg CreateBookWithZeroHashParams) (Book, error) {
@@ -152,6 +225,7 @@ func (q *Queries) CreateBookWithZeroHash(ctx context.Context, arg CreateBookWith
 		arg.Status,
 		arg.IsDownloaded,
 		arg.Checksum,
+		arg.NormalizedTitle,
 	)
 	var i Book
 	err := row.Scan(
@@ -167,6 +241,7 @@ func (q *Queries) CreateBookWithZeroHash(ctx context.Context, arg CreateBookWith
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.NormalizedTitle,
 	)
 	return i, err
 }
@@ -191,24 +266,99 @@ func (q *Queries) CreateError(ctx context.Context, arg CreateErrorParams) (Error
 	return i, err
 }
 
+const createJob = `-- name: CreateJob :one
+insert into jobs (site, operation, book_id, hash_code, status, created_at)
+values ($1, $2, $3, $4, $5, $6)
+returning id
+`
+
+type CreateJobParams struct {
+	Site      string
+	Operation string
+	BookID    sql.NullInt32
+	HashCode  sql.NullInt32
+	Status    string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createJob,
+		arg.Site,
+		arg.Operation,
+		arg.BookID,
+		arg.HashCode,
+		arg.Status,
+		arg.CreatedAt,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createJobRun = `-- name: CreateJobRun :one
+insert into job_runs (site, operation, started_at, outcome)
+values ($1, $2, $3, $4)
+returning id
+`
+
+type CreateJobRunParams struct {
+	Site      string
+	Operation string
+	StartedAt time.Time
+	Outcome   string
+}
+
+func (q *Queries) CreateJobRun(ctx context.Context, arg CreateJobRunParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createJobRun,
+		arg.Site,
+		arg.Operation,
+		arg.StartedAt,
+		arg.Outcome,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
 const createWriter = `-- name: CreateWriter :one
-insert into writers (name, checksum) values ($1, $2) 
-on conflict (name) do update set name=$1 
-returning id, name, checksum
+insert into writers (name, checksum, normalized_name) values ($1, $2, $3) 
+on conflict (name) do update set name=$1, normalized_name=$3 
+returning id, name, checksum, normalized_name
 `
 
 type CreateWriterParams struct {
-	Name     sql.NullString
-	Checksum sql.NullString
+	Name           sql.NullString
+	Checksum       sql.NullString
+	NormalizedName sql.NullString
 }
 
 func (q *Queries) CreateWriter(ctx context.Context, arg CreateWriterParams) (Writer, error) {
-	row := q.db.QueryRowContext(ctx, createWriter, arg.Name, arg.Checksum)
+	row := q.db.QueryRowContext(ctx, createWriter, arg.Name, arg.Checksum, arg.NormalizedName)
 	var i Writer
-	err := row.Scan(&i.ID, &i.Name, &i.Checksum)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Checksum,
+		&i.NormalizedName,
+	)
 	return i, err
 }
 
+const deleteChaptersByBook = `-- name: DeleteChaptersByBook :exec
+delete from chapters where site=$1 and book_id=$2 and hash_code=$3
+`
+
+type DeleteChaptersByBookParams struct {
+	Site     string
+	BookID   int32
+	HashCode int32
+}
+
+func (q *Queries) DeleteChaptersByBook(ctx context.Context, arg DeleteChaptersByBookParams) error {
+	_, err := q.db.ExecContext(ctx, deleteChaptersByBook, arg.Site, arg.BookID, arg.HashCode)
+	return err
+}
+
 const deleteError = `-- name: DeleteError :one
 delete from errors where site=$1 and id=$2 returning site, id, data
 `
@@ -225,6 +375,24 @@ func (q *Queries) DeleteError(ctx context.Context, arg DeleteErrorParams) (Error
 	return i, err
 }
 
+const deleteFinishedBookTasks = `-- name: DeleteFinishedBookTasks :execrows
+delete from book_tasks
+where site=$1 and status='done' and updated_at<$2
+`
+
+type DeleteFinishedBookTasksParams struct {
+	Site      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) DeleteFinishedBookTasks(ctx context.Context, arg DeleteFinishedBookTasksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFinishedBookTasks, arg.Site, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const downloadedBooksStat = `-- name: DownloadedBooksStat :one
 select count(*) as downloaded_count from books where site=$1 and is_downloaded=true
 `
@@ -236,6 +404,56 @@ func (q *Queries) DownloadedBooksStat(ctx context.Context, site string) (int64,
 	return downloaded_count, err
 }
 
+const enqueueDownloadBookTasks = `-- name: EnqueueDownloadBookTasks :execrows
+insert into book_tasks (site, operation, book_id, hash_code, status, available_at, updated_at)
+select distinct on (books.site, books.id)
+  books.site, 'download', books.id, books.hash_code, 'queued', now(), now()
+from books
+where books.site=$1 and (
+  (books.status='END' and books.is_downloaded=false)
+  or ($2::boolean and books.status='INPROGRESS')
+)
+order by books.site, books.id desc, books.hash_code desc
+on conflict (site, operation, book_id, hash_code)
+do update set status='queued', attempts=0, available_at=now(),
+  lease_owner='', lease_expires_at=null, error='', updated_at=now()
+where book_tasks.status='done'
+`
+
+type EnqueueDownloadBookTasksParams struct {
+	Site              string
+	IncludeInProgress bool
+}
+
+func (q *Queries) EnqueueDownloadBookTasks(ctx context.Context, arg EnqueueDownloadBookTasksParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enqueueDownloadBookTasks, arg.Site, arg.IncludeInProgress)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enqueueUpdateBookTasks = `-- name: EnqueueUpdateBookTasks :execrows
+insert into book_tasks (site, operation, book_id, hash_code, status, available_at, updated_at)
+select distinct on (books.site, books.id)
+  books.site, 'update', books.id, books.hash_code, 'queued', now(), now()
+from books
+where books.site=$1
+order by books.site, books.id desc, books.hash_code desc
+on conflict (site, operation, book_id, hash_code)
+do update set status='queued', attempts=0, available_at=now(),
+  lease_owner='', lease_expires_at=null, error='', updated_at=now()
+where book_tasks.status='done'
+`
+
+func (q *Queries) EnqueueUpdateBookTasks(ctx context.Context, site string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enqueueUpdateBookTasks, site)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const errorBooksStat = `-- name: ErrorBooksStat :one
 select count(*) as error_count from books where site=$1 and status='ERROR'
 `
@@ -247,6 +465,38 @@ func (q *Queries) ErrorBooksStat(ctx context.Context, site string) (int64, error
 	return error_count, err
 }
 
+const failBookTask = `-- name: FailBookTask :execrows
+update book_tasks
+set status=$1, attempts=$2, error=$3,
+  available_at=now() + $4::integer * interval '1 second',
+  lease_owner='', lease_expires_at=null, updated_at=now()
+where id=$5 and status='leased' and lease_owner=$6
+`
+
+type FailBookTaskParams struct {
+	Status       string
+	Attempts     int32
+	Error        string
+	RetrySeconds int32
+	ID           int32
+	LeaseOwner   string
+}
+
+func (q *Queries) FailBookTask(ctx context.Context, arg FailBookTaskParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failBookTask,
+		arg.Status,
+		arg.Attempts,
+		arg.Error,
+		arg.RetrySeconds,
+		arg.ID,
+		arg.LeaseOwner,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const findAllBookIDs = `-- name: FindAllBookIDs :many
 select distinct(id) as book_id from books where site=$1 order by book_id
 `
@@ -274,6 +524,62 @@ func (q *Queries) FindAllBookIDs(ctx context.Context, site string) ([]int32, err
 	return items, nil
 }
 
+const finishJob = `-- name: FinishJob :execrows
+update jobs set status=$2, error=$3, stats=$4, ended_at=$5, attempts=$7,
+  lease_owner='', lease_expires_at=null
+where id=$1 and status='running' and lease_owner=$6
+`
+
+type FinishJobParams struct {
+	ID         int32
+	Status     string
+	Error      string
+	Stats      json.RawMessage
+	EndedAt    sql.NullTime
+	LeaseOwner string
+	Attempts   int32
+}
+
+func (q *Queries) FinishJob(ctx context.Context, arg FinishJobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, finishJob,
+		arg.ID,
+		arg.Status,
+		arg.Error,
+		arg.Stats,
+		arg.EndedAt,
+		arg.LeaseOwner,
+		arg.Attempts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const finishJobRun = `-- name: FinishJobRun :exec
+update job_runs set ended_at=$2, outcome=$3, error=$4, stats=$5
+where id=$1
+`
+
+type FinishJobRunParams struct {
+	ID      int32
+	EndedAt sql.NullTime
+	Outcome string
+	Error   string
+	Stats   json.RawMessage
+}
+
+func (q *Queries) FinishJobRun(ctx context.Context, arg FinishJobRunParams) error {
+	_, err := q.db.ExecContext(ctx, finishJobRun,
+		arg.ID,
+		arg.EndedAt,
+		arg.Outcome,
+		arg.Error,
+		arg.Stats,
+	)
+	return err
+}
+
 const getBookByID = `-- name: GetBookByID :one
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
@@ -386,9 +692,9 @@ from books
   left join errors on books.site=errors.site and books.id=errors.id
 where (books.checksum, books.writer_checksum) = (
   select bks.checksum, bks.writer_checksum from books as bks 
-  where books.site=$1 and books.id=$2 
-  and books.checksum != '' and books.writer_checksum != ''
-  order by books.hash_code desc limit 1
+  where bks.site=$1 and bks.id=$2 
+  and bks.checksum != '' and bks.writer_checksum != ''
+  order by bks.hash_code desc limit 1
 ) or books.site=$1 and books.id=$2
 `
 
@@ -459,7 +765,7 @@ from books
 where (books.checksum, books.writer_checksum) = (
   select bks.checksum, bks.writer_checksum from books as bks 
   where bks.site=$1 and bks.id=$2 and bks.hash_code=$3 
-  and books.checksum != '' and books.writer_checksum != ''
+  and bks.checksum != '' and bks.writer_checksum != ''
   order by bks.hash_code desc limit 1
 ) or books.site=$1 and books.id=$2
 `
@@ -521,6 +827,204 @@ func (q *Queries) GetBookGroupByIDHash(ctx context.Context, arg GetBookGroupByID
 	return items, nil
 }
 
+const getChapter = `-- name: GetChapter :one
+select site, book_id, hash_code, chapter_index, url, title, content, fetched_at, error from chapters
+where site=$1 and book_id=$2 and hash_code=$3 and chapter_index=$4
+`
+
+type GetChapterParams struct {
+	Site         string
+	BookID       int32
+	HashCode     int32
+	ChapterIndex int32
+}
+
+func (q *Queries) GetChapter(ctx context.Context, arg GetChapterParams) (Chapter, error) {
+	row := q.db.QueryRowContext(ctx, getChapter,
+		arg.Site,
+		arg.BookID,
+		arg.HashCode,
+		arg.ChapterIndex,
+	)
+	var i Chapter
+	err := row.Scan(
+		&i.Site,
+		&i.BookID,
+		&i.HashCode,
+		&i.ChapterIndex,
+		&i.Url,
+		&i.Title,
+		&i.Content,
+		&i.FetchedAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getJob = `-- name: GetJob :one
+select id, site, operation, book_id, hash_code, status, error, stats, created_at, started_at, ended_at, attempts, lease_owner, lease_expires_at from jobs where site=$1 and id=$2
+`
+
+type GetJobParams struct {
+	Site string
+	ID   int32
+}
+
+func (q *Queries) GetJob(ctx context.Context, arg GetJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, getJob, arg.Site, arg.ID)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Site,
+		&i.Operation,
+		&i.BookID,
+		&i.HashCode,
+		&i.Status,
+		&i.Error,
+		&i.Stats,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Attempts,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+	)
+	return i, err
+}
+
+const getJobRun = `-- name: GetJobRun :one
+select id, site, operation, started_at, ended_at, outcome, error, stats from job_runs where site=$1 and id=$2
+`
+
+type GetJobRunParams struct {
+	Site string
+	ID   int32
+}
+
+func (q *Queries) GetJobRun(ctx context.Context, arg GetJobRunParams) (JobRun, error) {
+	row := q.db.QueryRowContext(ctx, getJobRun, arg.Site, arg.ID)
+	var i JobRun
+	err := row.Scan(
+		&i.ID,
+		&i.Site,
+		&i.Operation,
+		&i.StartedAt,
+		&i.EndedAt,
+		&i.Outcome,
+		&i.Error,
+		&i.Stats,
+	)
+	return i, err
+}
+
+const leaseBookTask = `-- name: LeaseBookTask :one
+update book_tasks set status='leased', attempts=attempts+1,
+  lease_owner=$1,
+  lease_expires_at=now() + $2::integer * interval '1 second',
+  updated_at=now()
+where id=(
+  select id from book_tasks
+  where site=$3 and operation=$4 and (
+    (status='queued' and available_at<=now())
+    or (status='leased' and lease_expires_at<now())
+  )
+  order by id limit 1
+  for update skip locked
+)
+returning id, site, operation, book_id, hash_code, status, attempts, available_at, lease_owner, lease_expires_at, error, updated_at
+`
+
+type LeaseBookTaskParams struct {
+	LeaseOwner   string
+	LeaseSeconds int32
+	Site         string
+	Operation    string
+}
+
+func (q *Queries) LeaseBookTask(ctx context.Context, arg LeaseBookTaskParams) (BookTask, error) {
+	row := q.db.QueryRowContext(ctx, leaseBookTask,
+		arg.LeaseOwner,
+		arg.LeaseSeconds,
+		arg.Site,
+		arg.Operation,
+	)
+	var i BookTask
+	err := row.Scan(
+		&i.ID,
+		&i.Site,
+		&i.Operation,
+		&i.BookID,
+		&i.HashCode,
+		&i.Status,
+		&i.Attempts,
+		&i.AvailableAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Error,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listBookTasks = `-- name: ListBookTasks :many
+select id, site, operation, book_id, hash_code, status, attempts, available_at, lease_owner, lease_expires_at, error, updated_at from book_tasks
+where site=$1
+  and ($2::text='' or operation=$2::text)
+  and ($3::text='' or status=$3::text)
+order by updated_at desc, id desc
+limit $4 offset $5
+`
+
+type ListBookTasksParams struct {
+	Site        string
+	Operation   string
+	Status      string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) ListBookTasks(ctx context.Context, arg ListBookTasksParams) ([]BookTask, error) {
+	rows, err := q.db.QueryContext(ctx, listBookTasks,
+		arg.Site,
+		arg.Operation,
+		arg.Status,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookTask
+	for rows.Next() {
+		var i BookTask
+		if err := rows.Scan(
+			&i.ID,
+			&i.Site,
+			&i.Operation,
+			&i.BookID,
+			&i.HashCode,
+			&i.Status,
+			&i.Attempts,
+			&i.AvailableAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.Error,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listBooks = `-- name: ListBooks :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
@@ -583,22 +1087,25 @@ func (q *Queries) ListBooks(ctx context.Context, site string) ([]ListBooksRow, e
 	return items, nil
 }
 
-const listBooksByStatus = `-- name: ListBooksByStatus :many
+const listBooksByChecksum = `-- name: ListBooksByChecksum :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
   books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
+from books
+  left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1 and books.status=$2 order by hash_code desc
+where books.checksum=$1 and books.writer_checksum=$2
+  and books.checksum != '' and books.writer_checksum != ''
+order by books.site, books.id, books.hash_code desc
 `
 
-type ListBooksByStatusParams struct {
-	Site   string
-	Status string
+type ListBooksByChecksumParams struct {
+	Checksum       sql.NullString
+	WriterChecksum sql.NullString
 }
 
-type ListBooksByStatusRow struct {
+type ListBooksByChecksumRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
@@ -613,15 +1120,15 @@ type ListBooksByStatusRow struct {
 	Data          string
 }
 
-func (q *Queries) ListBooksByStatus(ctx context.Context, arg ListBooksByStatusParams) ([]ListBooksByStatusRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksByStatus, arg.Site, arg.Status)
+func (q *Queries) ListBooksByChecksum(ctx context.Context, arg ListBooksByChecksumParams) ([]ListBooksByChecksumRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByChecksum, arg.Checksum, arg.WriterChecksum)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksByStatusRow
+	var items []ListBooksByChecksumRow
 	for rows.Next() {
-		var i ListBooksByStatusRow
+		var i ListBooksByChecksumRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
@@ -649,28 +1156,22 @@ func (q *Queries) ListBooksByStatus(ctx context.Context, arg ListBooksByStatusPa
 	return items, nil
 }
 
-const listBooksByTitleWriter = `-- name: ListBooksByTitleWriter :many
+const listBooksByStatus = `-- name: ListBooksByStatus :many
 select books.site, books.id, books.hash_code, books.title,
   books.writer_id, coalesce(writers.name, ''), books.type,
   books.update_date, books.update_chapter, 
   books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id
+from books left join writers on books.writer_id=writers.id 
   left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1 and books.status != 'ERROR' and 
-  (($2 != '%%' and books.title like $2) or
-  ($3 != '%%' and writers.name like $3))
-order by books.update_date desc, books.id desc limit $4 offset $5
+where books.site=$1 and books.status=$2 order by hash_code desc
 `
 
-type ListBooksByTitleWriterParams struct {
-	Site    string
-	Column2 interface{}
-	Column3 interface{}
-	Limit   int32
-	Offset  int32
+type ListBooksByStatusParams struct {
+	Site   string
+	Status string
 }
 
-type ListBooksByTitleWriterRow struct {
+type ListBooksByStatusRow struct {
 	Site          string
 	ID            int32
 	HashCode      int32
@@ -685,21 +1186,15 @@ type ListBooksByTitleWriterRow struct {
 	Data          string
 }
 
-func (q *Queries) ListBooksByTitleWriter(ctx context.Context, arg ListBooksByTitleWriterParams) ([]ListBooksByTitleWriterRow, error) {
-	rows, err := q.db.QueryContext(ctx, listBooksByTitleWriter,
-		arg.Site,
-		arg.Column2,
-		arg.Column3,
-		arg.Limit,
-		arg.Offset,
-	)
+func (q *Queries) ListBooksByStatus(ctx context.Context, arg ListBooksByStatusParams) ([]ListBooksByStatusRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBooksByStatus, arg.Site, arg.Status)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListBooksByTitleWriterRow
+	var items []ListBooksByStatusRow
 	for rows.Next() {
-		var i ListBooksByTitleWriterRow
+		var i ListBooksByStatusRow
 		if err := rows.Scan(
 			&i.Site,
 			&i.ID,
@@ -853,16 +1348,178 @@ func (q *Queries) ListBooksForUpdate(ctx context.Context, site string) ([]ListBo
 	return items, nil
 }
 
-const listRandomBooks = `-- name: ListRandomBooks :many
-select books.site, books.id, books.hash_code, books.title,
-  books.writer_id, coalesce(writers.name, ''), books.type,
-  books.update_date, books.update_chapter, 
-  books.status, books.is_downloaded, coalesce(errors.data, '')
-from books left join writers on books.writer_id=writers.id 
-  left join errors on books.site=errors.site and books.id=errors.id
-where books.site=$1 and books.is_downloaded=true
-order by books.site, books.id desc, books.hash_code desc 
-limit $2 offset RANDOM() * 
+const listChaptersByBook = `-- name: ListChaptersByBook :many
+select site, book_id, hash_code, chapter_index, url, title, content, fetched_at, error from chapters
+where site=$1 and book_id=$2 and hash_code=$3
+order by chapter_index
+`
+
+type ListChaptersByBookParams struct {
+	Site     string
+	BookID   int32
+	HashCode int32
+}
+
+func (q *Queries) ListChaptersByBook(ctx context.Context, arg ListChaptersByBookParams) ([]Chapter, error) {
+	rows, err := q.db.QueryContext(ctx, listChaptersByBook, arg.Site, arg.BookID, arg.HashCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chapter
+	for rows.Next() {
+		var i Chapter
+		if err := rows.Scan(
+			&i.Site,
+			&i.BookID,
+			&i.HashCode,
+			&i.ChapterIndex,
+			&i.Url,
+			&i.Title,
+			&i.Content,
+			&i.FetchedAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDownloadedBooksWithoutChapters = `-- name: ListDownloadedBooksWithoutChapters :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, '')
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.is_downloaded=true and not exists (
+  select 1 from chapters where chapters.site=books.site
+    and chapters.book_id=books.id and chapters.hash_code=books.hash_code
+)
+order by books.id desc, books.hash_code desc
+`
+
+type ListDownloadedBooksWithoutChaptersRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+}
+
+func (q *Queries) ListDownloadedBooksWithoutChapters(ctx context.Context, site string) ([]ListDownloadedBooksWithoutChaptersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDownloadedBooksWithoutChapters, site)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDownloadedBooksWithoutChaptersRow
+	for rows.Next() {
+		var i ListDownloadedBooksWithoutChaptersRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJobRuns = `-- name: ListJobRuns :many
+select id, site, operation, started_at, ended_at, outcome, error, stats from job_runs
+where site=$1
+  and ($2::text='' or operation=$2::text)
+order by started_at desc, id desc
+limit $3 offset $4
+`
+
+type ListJobRunsParams struct {
+	Site        string
+	Operation   string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) ListJobRuns(ctx context.Context, arg ListJobRunsParams) ([]JobRun, error) {
+	rows, err := q.db.QueryContext(ctx, listJobRuns,
+		arg.Site,
+		arg.Operation,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []JobRun
+	for rows.Next() {
+		var i JobRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.Site,
+			&i.Operation,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Outcome,
+			&i.Error,
+			&i.Stats,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRandomBooks = `-- name: ListRandomBooks :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, '')
+from books left join writers on books.writer_id=writers.id 
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$1 and books.is_downloaded=true
+order by books.site, books.id desc, books.hash_code desc 
+limit $2 offset RANDOM() * 
 (
   select greatest(count(*) - $2, 0)
   from books as bks where site=$1 and bks.is_downloaded=true
@@ -936,27 +1593,346 @@ func (q *Queries) NonErrorBooksStat(ctx context.Context, site string) (interface
 	return latest_success_id, err
 }
 
+const renewBookTaskLease = `-- name: RenewBookTaskLease :execrows
+update book_tasks
+set lease_expires_at=now() + $1::integer * interval '1 second',
+  updated_at=now()
+where id=$2 and status='leased' and lease_owner=$3
+`
+
+type RenewBookTaskLeaseParams struct {
+	LeaseSeconds int32
+	ID           int32
+	LeaseOwner   string
+}
+
+func (q *Queries) RenewBookTaskLease(ctx context.Context, arg RenewBookTaskLeaseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renewBookTaskLease, arg.LeaseSeconds, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renewJobLease = `-- name: RenewJobLease :execrows
+update jobs
+set lease_expires_at=now() + $1::integer * interval '1 second'
+where id=$2 and status='running' and lease_owner=$3
+`
+
+type RenewJobLeaseParams struct {
+	LeaseSeconds int32
+	ID           int32
+	LeaseOwner   string
+}
+
+func (q *Queries) RenewJobLease(ctx context.Context, arg RenewJobLeaseParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renewJobLease, arg.LeaseSeconds, arg.ID, arg.LeaseOwner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const requeueBookTask = `-- name: RequeueBookTask :one
+update book_tasks
+set status='queued', attempts=0, available_at=now(), error='', updated_at=now()
+where site=$1 and id=$2 and status='dead'
+returning id, site, operation, book_id, hash_code, status, attempts, available_at, lease_owner, lease_expires_at, error, updated_at
+`
+
+type RequeueBookTaskParams struct {
+	Site string
+	ID   int32
+}
+
+func (q *Queries) RequeueBookTask(ctx context.Context, arg RequeueBookTaskParams) (BookTask, error) {
+	row := q.db.QueryRowContext(ctx, requeueBookTask, arg.Site, arg.ID)
+	var i BookTask
+	err := row.Scan(
+		&i.ID,
+		&i.Site,
+		&i.Operation,
+		&i.BookID,
+		&i.HashCode,
+		&i.Status,
+		&i.Attempts,
+		&i.AvailableAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Error,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const saveChapter = `-- name: SaveChapter :exec
+insert into chapters
+(site, book_id, hash_code, chapter_index, url, title, content, fetched_at, error)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+on conflict (site, book_id, hash_code, chapter_index)
+do update set url=$5, title=$6, content=$7, fetched_at=$8, error=$9
+`
+
+type SaveChapterParams struct {
+	Site         string
+	BookID       int32
+	HashCode     int32
+	ChapterIndex int32
+	Url          string
+	Title        string
+	Content      string
+	FetchedAt    sql.NullTime
+	Error        string
+}
+
+func (q *Queries) SaveChapter(ctx context.Context, arg SaveChapterParams) error {
+	_, err := q.db.ExecContext(ctx, saveChapter,
+		arg.Site,
+		arg.BookID,
+		arg.HashCode,
+		arg.ChapterIndex,
+		arg.Url,
+		arg.Title,
+		arg.Content,
+		arg.FetchedAt,
+		arg.Error,
+	)
+	return err
+}
+
+const searchBooksByTitleWriter = `-- name: SearchBooksByTitleWriter :many
+select books.site, books.id, books.hash_code, books.title,
+  books.writer_id, coalesce(writers.name, ''), books.type,
+  books.update_date, books.update_chapter, 
+  books.status, books.is_downloaded, coalesce(errors.data, ''),
+  cast(
+    similarity(coalesce(books.normalized_title, ''), $1::text) +
+    similarity(coalesce(writers.normalized_name, ''), $2::text)
+  as real) as relevance
+from books left join writers on books.writer_id=writers.id
+  left join errors on books.site=errors.site and books.id=errors.id
+where books.site=$3 and books.status != 'ERROR' and (
+  ($1::text != '' and
+    books.normalized_title like '%' || $4::text || '%' escape '\') or
+  ($2::text != '' and
+    writers.normalized_name like '%' || $5::text || '%' escape '\')
+)
+order by relevance desc, books.update_date desc, books.id desc
+limit $6 offset $7
+`
+
+type SearchBooksByTitleWriterParams struct {
+	Title         string
+	Writer        string
+	Site          string
+	TitlePattern  string
+	WriterPattern string
+	LimitCount    int32
+	OffsetCount   int32
+}
+
+type SearchBooksByTitleWriterRow struct {
+	Site          string
+	ID            int32
+	HashCode      int32
+	Title         sql.NullString
+	WriterID      sql.NullInt32
+	Name          string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	Data          string
+	Relevance     float32
+}
+
+func (q *Queries) SearchBooksByTitleWriter(ctx context.Context, arg SearchBooksByTitleWriterParams) ([]SearchBooksByTitleWriterRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchBooksByTitleWriter,
+		arg.Title,
+		arg.Writer,
+		arg.Site,
+		arg.TitlePattern,
+		arg.WriterPattern,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchBooksByTitleWriterRow
+	for rows.Next() {
+		var i SearchBooksByTitleWriterRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.ID,
+			&i.HashCode,
+			&i.Title,
+			&i.WriterID,
+			&i.Name,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.Data,
+			&i.Relevance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchChapterContent = `-- name: SearchChapterContent :many
+select chapters.site, chapters.book_id, chapters.hash_code,
+  books.title as book_title, books.writer_id, coalesce(writers.name, '') as writer_name,
+  books.type, books.update_date, books.update_chapter,
+  books.status, books.is_downloaded,
+  chapters.chapter_index, chapters.title as chapter_title, chapters.content
+from chapters join books on chapters.site=books.site
+  and chapters.book_id=books.id and chapters.hash_code=books.hash_code
+  left join writers on books.writer_id=writers.id
+where chapters.site=$1 and chapters.error=''
+  and chapters.content like '%' || $2::text || '%' escape '\'
+order by chapters.book_id desc, chapters.hash_code desc, chapters.chapter_index
+limit $3 offset $4
+`
+
+type SearchChapterContentParams struct {
+	Site        string
+	Keyword     string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type SearchChapterContentRow struct {
+	Site          string
+	BookID        int32
+	HashCode      int32
+	BookTitle     sql.NullString
+	WriterID      sql.NullInt32
+	WriterName    string
+	Type          sql.NullString
+	UpdateDate    sql.NullString
+	UpdateChapter sql.NullString
+	Status        string
+	IsDownloaded  bool
+	ChapterIndex  int32
+	ChapterTitle  string
+	Content       string
+}
+
+func (q *Queries) SearchChapterContent(ctx context.Context, arg SearchChapterContentParams) ([]SearchChapterContentRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChapterContent,
+		arg.Site,
+		arg.Keyword,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChapterContentRow
+	for rows.Next() {
+		var i SearchChapterContentRow
+		if err := rows.Scan(
+			&i.Site,
+			&i.BookID,
+			&i.HashCode,
+			&i.BookTitle,
+			&i.WriterID,
+			&i.WriterName,
+			&i.Type,
+			&i.UpdateDate,
+			&i.UpdateChapter,
+			&i.Status,
+			&i.IsDownloaded,
+			&i.ChapterIndex,
+			&i.ChapterTitle,
+			&i.Content,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const tryLockOperation = `-- name: TryLockOperation :one
+select pg_try_advisory_lock(
+  hashtext($1::text), hashtext($2::text)
+) as locked
+`
+
+type TryLockOperationParams struct {
+	Site      string
+	Operation string
+}
+
+func (q *Queries) TryLockOperation(ctx context.Context, arg TryLockOperationParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryLockOperation, arg.Site, arg.Operation)
+	var locked bool
+	err := row.Scan(&locked)
+	return locked, err
+}
+
+const unlockOperation = `-- name: UnlockOperation :one
+select pg_advisory_unlock(
+  hashtext($1::text), hashtext($2::text)
+) as unlocked
+`
+
+type UnlockOperationParams struct {
+	Site      string
+	Operation string
+}
+
+func (q *Queries) UnlockOperation(ctx context.Context, arg UnlockOperationParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, unlockOperation, arg.Site, arg.Operation)
+	var unlocked bool
+	err := row.Scan(&unlocked)
+	return unlocked, err
+}
+
 const updateBook = `-- name: UpdateBook :one
 Update books SET 
 title=$4, writer_id=$5, writer_checksum=$12, type=$6, update_date=$7, update_chapter=$8,
-status=$9, is_downloaded=$10, checksum=$11
+status=$9, is_downloaded=$10, checksum=$11, normalized_title=$13
 WHERE site=$1 and id=$2 and hash_code=$3
-RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum
+RETURNING site, id, hash_code, title, writer_id, type, update_date, update_chapter, status, is_downloaded, checksum, writer_checksum, normalized_title
 `
 
 type UpdateBookParams struct {
-	Site           string
-	ID             int32
-	HashCode       int32
-	Title          sql.NullString
-	WriterID       sql.NullInt32
-	Type           sql.NullString
-	UpdateDate     sql.NullString
-	UpdateChapter  sql.NullString
-	Status         string
-	IsDownloaded   bool
-	Checksum       sql.NullString
-	WriterChecksum sql.NullString
+	Site            string
+	ID              int32
+	HashCode        int32
+	Title           sql.NullString
+	WriterID        sql.NullInt32
+	Type            sql.NullString
+	UpdateDate      sql.NullString
+	UpdateChapter   sql.NullString
+	Status          string
+	IsDownloaded    bool
+	Checksum        sql.NullString
+	WriterChecksum  sql.NullString
+	NormalizedTitle sql.NullString
 }
 
 func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error) {
@@ -973,6 +1949,7 @@ func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, e
 		arg.IsDownloaded,
 		arg.Checksum,
 		arg.WriterChecksum,
+		arg.NormalizedTitle,
 	)
 	var i Book
 	err := row.Scan(
@@ -988,6 +1965,7 @@ func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, e
 		&i.IsDownloaded,
 		&i.Checksum,
 		&i.WriterChecksum,
+		&i.NormalizedTitle,
 	)
 	return i, err
 }
@@ -0,0 +1,72 @@
+package generic
+
+import (
+	"flag"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"go.uber.org/goleak"
+)
+
+var (
+	testBookBytes        []byte
+	testChapterBytes     []byte
+	testChapterListBytes []byte
+)
+
+// testSiteConf mirrors the selectors of the xbiquge vendor package
+var testSiteConf = config.SiteConfig{
+	URL: config.URLConfig{
+		Base:          "https://www.xbiquge.bz/book/%v/",
+		Download:      "https://www.xbiquge.bz/book/%v/",
+		ChapterPrefix: "https://www.xbiquge.bz",
+	},
+	GoquerySelectorsConfig: config.GoquerySelectorsConfig{
+		Title:            config.GoquerySelectorConfig{Selector: `meta[property="og:novel:book_name"]`, Attr: "content"},
+		Writer:           config.GoquerySelectorConfig{Selector: `meta[property="og:novel:author"]`, Attr: "content"},
+		BookType:         config.GoquerySelectorConfig{Selector: `meta[property="og:novel:category"]`, Attr: "content"},
+		LastUpdate:       config.GoquerySelectorConfig{Selector: `meta[property="og:novel:update_time"]`, Attr: "content"},
+		LastChapter:      config.GoquerySelectorConfig{Selector: `meta[property="og:novel:latest_chapter_name"]`, Attr: "content"},
+		BookChapterURL:   config.GoquerySelectorConfig{Selector: `dd>a`, Attr: "href"},
+		BookChapterTitle: config.GoquerySelectorConfig{Selector: `dd>a`},
+		ChapterTitle:     config.GoquerySelectorConfig{Selector: `div.bookname>h1`},
+		ChapterContent:   config.GoquerySelectorConfig{Selector: `div#content`},
+	},
+	AvailabilityConfig: config.AvailabilityConfig{
+		URL:         "https://www.xbiquge.bz",
+		CheckString: "笔趣阁",
+	},
+	VendorConfig: config.VendorConfig{
+		MissingIDStrategy:            config.MissingIDStrategySequential,
+		MaxLeadingDuplicatedChapters: 12,
+	},
+}
+
+func TestMain(m *testing.M) {
+	var err error
+	testBookBytes, err = os.ReadFile("../test_resources/xbiquge_book.html")
+	if err != nil {
+		log.Fatalf("could not read book string")
+	}
+
+	testChapterBytes, err = os.ReadFile("../test_resources/xbiquge_chapter.html")
+	if err != nil {
+		log.Fatalf("could not read book string")
+	}
+
+	testChapterListBytes, err = os.ReadFile("../test_resources/xbiquge_chapter_list.html")
+	if err != nil {
+		log.Fatalf("could not read book string")
+	}
+
+	leak := flag.Bool("leak", false, "check for memory leaks")
+	flag.Parse()
+
+	if *leak {
+		goleak.VerifyTestMain(m)
+	} else {
+		os.Exit(m.Run())
+	}
+}
@@ -0,0 +1,219 @@
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+)
+
+func parseSelection(conf config.GoquerySelectorConfig, s *goquery.Selection) string {
+	var result string
+	if conf.Attr != "" {
+		result = s.AttrOr(conf.Attr, "")
+	} else {
+		result = vendor.GetGoqueryContentWithoutChildren(s)
+	}
+
+	for _, content := range conf.UnwantedContent {
+		result = strings.ReplaceAll(result, content, "")
+	}
+
+	return strings.TrimSpace(result)
+}
+
+func (p *VendorService) ParseDoc(body string) (*goquery.Document, error) {
+	return goquery.NewDocumentFromReader(strings.NewReader(body))
+}
+
+func (p *VendorService) ParseBook(body string) (*vendor.BookInfo, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	var parseErr error
+
+	// parse title
+	title := parseSelection(p.selectors.Title, doc.Find(p.selectors.Title.Selector))
+	if title == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookTitleNotFound)
+	}
+
+	// parse writer
+	writer := parseSelection(p.selectors.Writer, doc.Find(p.selectors.Writer.Selector))
+	if writer == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookWriterNotFound)
+	}
+
+	// parse type
+	bookType := parseSelection(p.selectors.BookType, doc.Find(p.selectors.BookType.Selector))
+	if bookType == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookTypeNotFound)
+	}
+
+	// parse date
+	date := parseSelection(p.selectors.LastUpdate, doc.Find(p.selectors.LastUpdate.Selector))
+	if date == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookDateNotFound)
+	}
+
+	// parse chapter
+	chapter := parseSelection(p.selectors.LastChapter, doc.Find(p.selectors.LastChapter.Selector))
+	if chapter == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrBookChapterNotFound)
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	return &vendor.BookInfo{
+		Title:         title,
+		Writer:        writer,
+		Type:          bookType,
+		UpdateDate:    date,
+		UpdateChapter: chapter,
+	}, parseErr
+}
+
+func (p *VendorService) ParseChapterList(bookID, body string) (vendor.ChapterList, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	urlConf, titleConf := p.selectors.BookChapterURL, p.selectors.BookChapterTitle
+	if urlConf.Attr == "" {
+		urlConf.Attr = "href"
+	}
+
+	var chapterList vendor.ChapterList
+	var parseErr error
+	doc.Find(urlConf.Selector).Each(func(i int, s *goquery.Selection) {
+		url := parseSelection(urlConf, s)
+		if url == "" {
+			parseErr = errors.Join(
+				parseErr,
+				fmt.Errorf("parse chapter url fail: %d, %w", i, vendor.ErrChapterListUrlNotFound),
+			)
+		}
+
+		titleSelection := s
+		if titleConf.Selector != "" && titleConf.Selector != urlConf.Selector {
+			titleSelection = s.Find(titleConf.Selector)
+		}
+
+		title := parseSelection(titleConf, titleSelection)
+		if title == "" {
+			parseErr = errors.Join(
+				parseErr,
+				fmt.Errorf("parse chapter title fail: %d, %w", i, vendor.ErrChapterListTitleNotFound),
+			)
+		}
+
+		chapterList = append(chapterList, vendor.ChapterListInfo{
+			URL:   p.ChapterURL(url, bookID),
+			Title: title,
+		})
+	})
+
+	if len(chapterList) == 0 {
+		return nil, vendor.ErrChapterListEmpty
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	// some vendors list the latest chapters before the full chapter list
+	for i := 0; i < p.conf.MaxLeadingDuplicatedChapters && len(chapterList) > 0; i++ {
+		targetChapterTitle := chapterList[0].Title
+		found := false
+
+		for _, ch := range chapterList[1:] {
+			if ch.Title == targetChapterTitle {
+				found = true
+
+				break
+			}
+		}
+
+		if found {
+			chapterList = chapterList[1:]
+		}
+	}
+
+	return chapterList, parseErr
+}
+
+func (p *VendorService) ParseChapter(body string) (*vendor.ChapterInfo, error) {
+	doc, docErr := p.ParseDoc(body)
+	if docErr != nil {
+		return nil, fmt.Errorf("parse body fail: %w", docErr)
+	}
+
+	var parseErr error
+
+	// parse title
+	title := parseSelection(p.selectors.ChapterTitle, doc.Find(p.selectors.ChapterTitle.Selector))
+	if title == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrChapterTitleNotFound)
+	}
+
+	// parse content
+	var contents []string
+	doc.Find(p.selectors.ChapterContent.Selector).Each(func(i int, s *goquery.Selection) {
+		content := parseSelection(p.selectors.ChapterContent, s)
+		if content != "" {
+			contents = append(contents, content)
+		}
+	})
+
+	content := strings.Join(contents, "\n")
+	if content == "" {
+		parseErr = errors.Join(parseErr, vendor.ErrChapterContentNotFound)
+	}
+
+	if parseErr != nil {
+		parseErr = errors.Join(parseErr, vendor.ErrFieldsNotFound)
+	}
+
+	return &vendor.ChapterInfo{
+		Title: title,
+		Body:  content,
+	}, parseErr
+}
+
+func (p *VendorService) IsAvailable(body string) bool {
+	return strings.Contains(body, p.availability.CheckString)
+}
+
+func (p *VendorService) FindMissingIds(ids []int) []int {
+	if p.conf.MissingIDStrategy == config.MissingIDStrategyNone {
+		return nil
+	}
+
+	var missingIDs []int
+
+	sort.Ints(ids)
+
+	idPointer, i := 0, 1
+	for idPointer < len(ids) && ids[len(ids)-1] > i {
+		if i == ids[idPointer] {
+			i++
+			idPointer++
+		} else if i > ids[idPointer] {
+			idPointer++
+		} else if i < ids[idPointer] {
+			missingIDs = append(missingIDs, i)
+			i++
+		}
+	}
+
+	return missingIDs
+}
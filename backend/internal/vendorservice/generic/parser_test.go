@@ -0,0 +1,399 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"github.com/htchan/BookSpider/internal/vendorservice/xbiquge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ParseBook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getConf   func() config.SiteConfig
+		body      string
+		want      *vendor.BookInfo
+		wantError error
+	}{
+		{
+			name:    "happy flow with real data",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body:    string(testBookBytes),
+			want: &vendor.BookInfo{
+				Title:         "神印王座II皓月当空",
+				Writer:        "唐家三少",
+				Type:          "都市小说",
+				UpdateDate:    "2023-08-03 10:45:03",
+				UpdateChapter: "正文 第二百二十章 陷阱，绝境？",
+			},
+			wantError: nil,
+		},
+		{
+			name: "happy flow with text and unwanted content",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.Title = config.GoquerySelectorConfig{
+					Selector: "h1", UnwantedContent: []string{"最新章节"},
+				}
+				conf.GoquerySelectorsConfig.LastUpdate = config.GoquerySelectorConfig{
+					Selector: "div.date", UnwantedContent: []string{"更新时间："},
+				}
+
+				return conf
+			},
+			body: `<data>
+				<h1>book name最新章节<span>ignored</span></h1>
+				<meta property="og:novel:author" content="author" />
+				<meta property="og:novel:category" content="type" />
+				<div class="date">更新时间：date</div>
+				<meta property="og:novel:latest_chapter_name" content="chapter name" />
+			</data>`,
+			want: &vendor.BookInfo{
+				Title: "book name", Writer: "author", Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter name",
+			},
+			wantError: nil,
+		},
+		{
+			name:    "title not found",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<meta property="og:novel:author" content="author" />
+				<meta property="og:novel:category" content="type" />
+				<meta property="og:novel:update_time" content="date" />
+				<meta property="og:novel:latest_chapter_name" content="chapter name" />
+			</data>`,
+			want: &vendor.BookInfo{
+				Writer: "author", Type: "type",
+				UpdateDate: "date", UpdateChapter: "chapter name",
+			},
+			wantError: vendor.ErrBookTitleNotFound,
+		},
+		{
+			name:      "all fields not found",
+			getConf:   func() config.SiteConfig { return testSiteConf },
+			body:      "<data></data>",
+			want:      &vendor.BookInfo{},
+			wantError: vendor.ErrFieldsNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewVendorService("test", test.getConf())
+			assert.NoError(t, err)
+
+			got, err := p.ParseBook(test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_ParseChapterList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getConf   func() config.SiteConfig
+		bookID    string
+		body      string
+		want      vendor.ChapterList
+		wantError error
+	}{
+		{
+			name:    "happy flow",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<div>
+					<dd><a href="chapter url 1">chapter name 1</a></dd>
+					<dd><a href="/book/1234/chapter url 2">chapter name 2</a></dd>
+				</div>
+			</data>`,
+			bookID: "1234",
+			want: vendor.ChapterList{
+				{URL: "https://www.xbiquge.bz/book/1234/chapter url 1", Title: "chapter name 1"},
+				{URL: "https://www.xbiquge.bz/book/1234/chapter url 2", Title: "chapter name 2"},
+			},
+			wantError: nil,
+		},
+		{
+			name: "title in nested selector",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.BookChapterURL = config.GoquerySelectorConfig{Selector: "li>a"}
+				conf.GoquerySelectorsConfig.BookChapterTitle = config.GoquerySelectorConfig{Selector: "span"}
+
+				return conf
+			},
+			body: `<data>
+				<li><a href="/1.html"><span>chapter name 1</span></a></li>
+				<li><a href="/2.html"><span>chapter name 2</span></a></li>
+			</data>`,
+			bookID: "1234",
+			want: vendor.ChapterList{
+				{URL: "https://www.xbiquge.bz/1.html", Title: "chapter name 1"},
+				{URL: "https://www.xbiquge.bz/2.html", Title: "chapter name 2"},
+			},
+			wantError: nil,
+		},
+		{
+			name:    "leading duplicated chapters removed",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<dd><a href="3.html">chapter name 3</a></dd>
+				<dd><a href="1.html">chapter name 1</a></dd>
+				<dd><a href="2.html">chapter name 2</a></dd>
+				<dd><a href="3.html">chapter name 3</a></dd>
+			</data>`,
+			bookID: "1234",
+			want: vendor.ChapterList{
+				{URL: "https://www.xbiquge.bz/book/1234/1.html", Title: "chapter name 1"},
+				{URL: "https://www.xbiquge.bz/book/1234/2.html", Title: "chapter name 2"},
+				{URL: "https://www.xbiquge.bz/book/1234/3.html", Title: "chapter name 3"},
+			},
+			wantError: nil,
+		},
+		{
+			name: "leading duplicated chapters kept if not configured",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.VendorConfig.MaxLeadingDuplicatedChapters = 0
+
+				return conf
+			},
+			body: `<data>
+				<dd><a href="2.html">chapter name 2</a></dd>
+				<dd><a href="1.html">chapter name 1</a></dd>
+				<dd><a href="2.html">chapter name 2</a></dd>
+			</data>`,
+			bookID: "1234",
+			want: vendor.ChapterList{
+				{URL: "https://www.xbiquge.bz/book/1234/2.html", Title: "chapter name 2"},
+				{URL: "https://www.xbiquge.bz/book/1234/1.html", Title: "chapter name 1"},
+				{URL: "https://www.xbiquge.bz/book/1234/2.html", Title: "chapter name 2"},
+			},
+			wantError: nil,
+		},
+		{
+			name:    "2nd chapter missing href",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<dd><a href="1.html">chapter name 1</a></dd>
+				<dd><a href="">chapter name 2</a></dd>
+			</data>`,
+			bookID: "1234",
+			want: vendor.ChapterList{
+				{URL: "https://www.xbiquge.bz/book/1234/1.html", Title: "chapter name 1"},
+				{URL: "", Title: "chapter name 2"},
+			},
+			wantError: vendor.ErrChapterListUrlNotFound,
+		},
+		{
+			name:      "no chapters found",
+			getConf:   func() config.SiteConfig { return testSiteConf },
+			body:      "<data></data>",
+			bookID:    "1234",
+			want:      nil,
+			wantError: vendor.ErrChapterListEmpty,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewVendorService("test", test.getConf())
+			assert.NoError(t, err)
+
+			got, err := p.ParseChapterList(test.bookID, test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_ParseChapter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getConf   func() config.SiteConfig
+		body      string
+		want      *vendor.ChapterInfo
+		wantError error
+	}{
+		{
+			name:    "happy flow",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<div class="bookname"><h1>chapter name</h1></div>
+				<div id="content">chapter content</div>
+			</data>`,
+			want: &vendor.ChapterInfo{
+				Title: "chapter name", Body: "chapter content",
+			},
+			wantError: nil,
+		},
+		{
+			name: "content from multiple selection",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.ChapterContent = config.GoquerySelectorConfig{
+					Selector: "div>p", UnwantedContent: []string{"ads"},
+				}
+
+				return conf
+			},
+			body: `<data>
+				<div class="bookname"><h1>chapter name</h1></div>
+				<div><p>line 1ads</p><p>line 2</p></div>
+			</data>`,
+			want: &vendor.ChapterInfo{
+				Title: "chapter name", Body: "line 1\nline 2",
+			},
+			wantError: nil,
+		},
+		{
+			name:    "body empty",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			body: `<data>
+				<div class="bookname"><h1>chapter name</h1></div>
+				<div id="content"></div>
+			</data>`,
+			want: &vendor.ChapterInfo{
+				Title: "chapter name", Body: "",
+			},
+			wantError: vendor.ErrChapterContentNotFound,
+		},
+		{
+			name:      "all fields not found",
+			getConf:   func() config.SiteConfig { return testSiteConf },
+			body:      "<data></data>",
+			want:      &vendor.ChapterInfo{},
+			wantError: vendor.ErrFieldsNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := NewVendorService("test", test.getConf())
+			assert.NoError(t, err)
+
+			got, err := p.ParseChapter(test.body)
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
+
+func TestParser_RealDataMatchesBuiltinVendor(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewVendorService("test", testSiteConf)
+	assert.NoError(t, err)
+
+	builtin := xbiquge.VendorService{}
+
+	wantChapterList, wantErr := builtin.ParseChapterList("45525", string(testChapterListBytes))
+	gotChapterList, gotErr := p.ParseChapterList("45525", string(testChapterListBytes))
+	assert.Equal(t, wantChapterList, gotChapterList)
+	assert.Equal(t, wantErr, gotErr)
+
+	wantChapter, wantErr := builtin.ParseChapter(string(testChapterBytes))
+	gotChapter, gotErr := p.ParseChapter(string(testChapterBytes))
+	assert.Equal(t, wantChapter, gotChapter)
+	assert.Equal(t, wantErr, gotErr)
+}
+
+func TestParser_IsAvailable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{
+			name: "available",
+			body: "<title>笔趣阁</title>",
+			want: true,
+		},
+		{
+			name: "not available",
+			body: "<title>other</title>",
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, _ := NewVendorService("test", testSiteConf)
+			assert.Equal(t, test.want, p.IsAvailable(test.body))
+		})
+	}
+}
+
+func TestParser_FindMissingIds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		strategy config.MissingIDStrategy
+		ids      []int
+		want     []int
+	}{
+		{
+			name:     "no missing ids",
+			strategy: config.MissingIDStrategySequential,
+			ids:      []int{4, 2, 3, 1, 5},
+			want:     nil,
+		},
+		{
+			name:     "some id is missing",
+			strategy: config.MissingIDStrategySequential,
+			ids:      []int{3, 5, 1},
+			want:     []int{2, 4},
+		},
+		{
+			name:     "default strategy is sequential",
+			strategy: "",
+			ids:      []int{3, 5, 1},
+			want:     []int{2, 4},
+		},
+		{
+			name:     "none strategy",
+			strategy: config.MissingIDStrategyNone,
+			ids:      []int{3, 5, 1},
+			want:     nil,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := testSiteConf
+			conf.VendorConfig.MissingIDStrategy = test.strategy
+
+			p, _ := NewVendorService("test", conf)
+			got := p.FindMissingIds(test.ids)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
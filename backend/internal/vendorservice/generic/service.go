@@ -0,0 +1,63 @@
+package generic
+
+import (
+	"errors"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/htchan/BookSpider/internal/repo"
+	"github.com/htchan/BookSpider/internal/service"
+	serviceV1 "github.com/htchan/BookSpider/internal/service/v1"
+	vendor "github.com/htchan/BookSpider/internal/vendorservice"
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	ErrBookInfoSelectorEmpty    = errors.New("book info selector is empty")
+	ErrChapterListSelectorEmpty = errors.New("chapter list selector is empty")
+	ErrChapterSelectorEmpty     = errors.New("chapter selector is empty")
+)
+
+// VendorService parse vendor pages by the goquery selectors, urls and
+// availability settings defined in site config instead of hard coded values
+type VendorService struct {
+	name         string
+	urls         config.URLConfig
+	selectors    config.GoquerySelectorsConfig
+	availability config.AvailabilityConfig
+	conf         config.VendorConfig
+}
+
+var _ vendor.VendorService = (*VendorService)(nil)
+
+func NewVendorService(name string, conf config.SiteConfig) (*VendorService, error) {
+	selectors := conf.GoquerySelectorsConfig
+	if selectors.Title.Selector == "" || selectors.Writer.Selector == "" || selectors.BookType.Selector == "" ||
+		selectors.LastUpdate.Selector == "" || selectors.LastChapter.Selector == "" {
+		return nil, ErrBookInfoSelectorEmpty
+	}
+
+	if selectors.BookChapterURL.Selector == "" {
+		return nil, ErrChapterListSelectorEmpty
+	}
+
+	if selectors.ChapterTitle.Selector == "" || selectors.ChapterContent.Selector == "" {
+		return nil, ErrChapterSelectorEmpty
+	}
+
+	return &VendorService{
+		name:         name,
+		urls:         conf.URL,
+		selectors:    selectors,
+		availability: conf.AvailabilityConfig,
+		conf:         conf.VendorConfig,
+	}, nil
+}
+
+func NewService(name string, rpo repo.Repository, sema *semaphore.Weighted, conf config.SiteConfig) (service.Service, error) {
+	vendorService, err := NewVendorService(name, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return serviceV1.NewService(name, rpo, vendorService, sema, conf), nil
+}
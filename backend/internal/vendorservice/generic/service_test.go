@@ -0,0 +1,76 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/htchan/BookSpider/internal/config/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVendorService(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		getConf   func() config.SiteConfig
+		want      *VendorService
+		wantError error
+	}{
+		{
+			name:    "happy flow",
+			getConf: func() config.SiteConfig { return testSiteConf },
+			want: &VendorService{
+				name:         "test",
+				urls:         testSiteConf.URL,
+				selectors:    testSiteConf.GoquerySelectorsConfig,
+				availability: testSiteConf.AvailabilityConfig,
+				conf:         testSiteConf.VendorConfig,
+			},
+			wantError: nil,
+		},
+		{
+			name: "book info selector empty",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.Writer.Selector = ""
+
+				return conf
+			},
+			want:      nil,
+			wantError: ErrBookInfoSelectorEmpty,
+		},
+		{
+			name: "chapter list selector empty",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.BookChapterURL.Selector = ""
+
+				return conf
+			},
+			want:      nil,
+			wantError: ErrChapterListSelectorEmpty,
+		},
+		{
+			name: "chapter selector empty",
+			getConf: func() config.SiteConfig {
+				conf := testSiteConf
+				conf.GoquerySelectorsConfig.ChapterContent.Selector = ""
+
+				return conf
+			},
+			want:      nil,
+			wantError: ErrChapterSelectorEmpty,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewVendorService("test", test.getConf())
+			assert.Equal(t, test.want, got)
+			assert.ErrorIs(t, err, test.wantError)
+		})
+	}
+}
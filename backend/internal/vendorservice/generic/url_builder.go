@@ -0,0 +1,47 @@
+package generic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+func (b *VendorService) BookURL(bookID string) string {
+	return fmt.Sprintf(b.urls.Base, bookID)
+}
+
+func (b *VendorService) ChapterListURL(bookID string) string {
+	return fmt.Sprintf(b.urls.Download, bookID)
+}
+
+func (b *VendorService) ChapterURL(resources ...string) string {
+	if len(resources) == 0 {
+		return ""
+	}
+
+	uri := resources[0]
+	if uri == "" {
+		return ""
+	} else if strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://") {
+		return uri
+	} else if strings.HasPrefix(uri, "/") {
+		return strings.TrimSuffix(b.urls.ChapterPrefix, "/") + uri
+	} else if len(resources) == 2 {
+		// relative chapter url is resolved against the chapter list page of the book
+		chapterListURL := b.ChapterListURL(resources[1])
+
+		return chapterListURL[:strings.LastIndex(chapterListURL, "/")+1] + uri
+	}
+
+	log.Error().
+		Str("vendor", b.name).
+		Strs("resources", resources).
+		Msg("unexpected resources for building chapter url")
+
+	return uri
+}
+
+func (b *VendorService) AvailabilityURL() string {
+	return b.availability.URL
+}
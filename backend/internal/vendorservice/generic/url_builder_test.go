@@ -0,0 +1,133 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVendorService_BookURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		bkID string
+		want string
+	}{
+		{
+			name: "int book id",
+			bkID: "1234",
+			want: "https://www.xbiquge.bz/book/1234/",
+		},
+		{
+			name: "non int book id",
+			bkID: "abcd",
+			want: "https://www.xbiquge.bz/book/abcd/",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv, _ := NewVendorService("test", testSiteConf)
+			got := serv.BookURL(test.bkID)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_ChapterListURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		bkID string
+		want string
+	}{
+		{
+			name: "int book id",
+			bkID: "1234",
+			want: "https://www.xbiquge.bz/book/1234/",
+		},
+		{
+			name: "non int book id",
+			bkID: "abcd",
+			want: "https://www.xbiquge.bz/book/abcd/",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv, _ := NewVendorService("test", testSiteConf)
+			got := serv.ChapterListURL(test.bkID)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_ChapterURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		resources []string
+		want      string
+	}{
+		{
+			name:      "no resources",
+			resources: nil,
+			want:      "",
+		},
+		{
+			name:      "empty uri",
+			resources: []string{""},
+			want:      "",
+		},
+		{
+			name:      "absolute url",
+			resources: []string{"https://www.other.com/chapter/1.html"},
+			want:      "https://www.other.com/chapter/1.html",
+		},
+		{
+			name:      "uri start with slash",
+			resources: []string{"/book/1234/1.html"},
+			want:      "https://www.xbiquge.bz/book/1234/1.html",
+		},
+		{
+			name:      "relative uri with book id",
+			resources: []string{"1.html", "1234"},
+			want:      "https://www.xbiquge.bz/book/1234/1.html",
+		},
+		{
+			name:      "relative uri without book id",
+			resources: []string{"1.html"},
+			want:      "1.html",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			serv, _ := NewVendorService("test", testSiteConf)
+			got := serv.ChapterURL(test.resources...)
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVendorService_AvailabilityURL(t *testing.T) {
+	t.Parallel()
+
+	serv, _ := NewVendorService("test", testSiteConf)
+	assert.Equal(t, "https://www.xbiquge.bz", serv.AvailabilityURL())
+}
@@ -1,11 +1,17 @@
 package hjwzw
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"testing"
+	"time"
 
+	client "github.com/htchan/BookSpider/internal/client/v2"
+	"github.com/htchan/BookSpider/internal/client/v2/replay"
+	"github.com/htchan/BookSpider/internal/client/v2/simple"
 	"go.uber.org/goleak"
 )
 
@@ -15,25 +21,62 @@ var (
 	testChapterListBytes []byte
 )
 
-func TestMain(m *testing.M) {
-	var err error
-	testBookBytes, err = os.ReadFile("../test_resources/hjwzw_book.html")
-	if err != nil {
-		log.Fatalf("could not read book string")
+const fixtureArchive = "../test_resources/hjwzw.json"
+
+var fixtureURLs = []string{
+	"https://tw.hjwzw.com/Book/37656/",
+	"https://tw.hjwzw.com/Book/Chapter/37656/",
+	"https://tw.hjwzw.com/Book/Read/37656,16491126",
+}
+
+// recordFixtures fetch the fixture urls from the vendor site and save the
+// responses to the fixture archive
+func recordFixtures() error {
+	cli := replay.NewClient(
+		&replay.ReplayClientConfig{Mode: replay.ModeRecord, Archive: fixtureArchive},
+		simple.NewClient(&simple.SimpleClientConfig{
+			RequestTimeout: 30 * time.Second,
+			DecodeMethod:   client.DecodeMethodUTF8,
+		}),
+	)
+
+	for _, url := range fixtureURLs {
+		if _, err := cli.Get(context.Background(), url); err != nil {
+			return fmt.Errorf("record %s: %w", url, err)
+		}
 	}
 
-	testChapterBytes, err = os.ReadFile("../test_resources/hjwzw_chapter.html")
-	if err != nil {
-		log.Fatalf("could not read book string")
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	record := flag.Bool("record", false, "record fixtures from vendor site")
+	leak := flag.Bool("leak", false, "check for memory leaks")
+	flag.Parse()
+
+	if *record {
+		if err := recordFixtures(); err != nil {
+			log.Fatalf("could not record fixtures: %v", err)
+		}
 	}
 
-	testChapterListBytes, err = os.ReadFile("../test_resources/hjwzw_chapter_list.html")
+	archive, err := replay.LoadArchive(fixtureArchive)
 	if err != nil {
-		log.Fatalf("could not read book string")
+		log.Fatalf("could not load fixtures: %v", err)
 	}
 
-	leak := flag.Bool("leak", false, "check for memory leaks")
-	flag.Parse()
+	for url, bytes := range map[string]*[]byte{
+		fixtureURLs[0]: &testBookBytes,
+		fixtureURLs[1]: &testChapterListBytes,
+		fixtureURLs[2]: &testChapterBytes,
+	} {
+		fixture, ok := archive.Find(url)
+		if !ok {
+			log.Fatalf("could not find fixture of %s", url)
+		}
+
+		*bytes = []byte(fixture.Body)
+	}
 
 	if *leak {
 		goleak.VerifyTestMain(m)
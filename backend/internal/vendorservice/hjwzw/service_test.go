@@ -0,0 +1,51 @@
+package hjwzw
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	circuitbreaker "github.com/htchan/BookSpider/internal/client/v2/circuit_breaker"
+	"github.com/htchan/BookSpider/internal/client/v2/replay"
+	"github.com/htchan/BookSpider/internal/client/v2/retry"
+	"github.com/htchan/BookSpider/internal/config/v2"
+	repomock "github.com/htchan/BookSpider/internal/mock/repo"
+	"github.com/htchan/BookSpider/internal/model"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/semaphore"
+)
+
+func TestService_UpdateBook_Replay(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rpo := repomock.NewMockRepository(ctrl)
+	rpo.EXPECT().SaveWriter(&model.Writer{Name: "龍蛇枝"}).Return(nil)
+	rpo.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+	rpo.EXPECT().SaveError(gomock.Any(), nil).Return(nil)
+
+	serv := NewService(rpo, semaphore.NewWeighted(1), config.SiteConfig{
+		ClientConfig: config.ClientConfig{
+			Retry: retry.RetryClientConfig{MaxRetryWeight: 1},
+			CircuitBreaker: circuitbreaker.CircuitBreakerClientConfig{
+				OpenThreshold:         10,
+				AcquireTimeout:        time.Second,
+				MaxConcurrencyThreads: 10,
+			},
+			Replay: replay.ReplayClientConfig{
+				Mode:    replay.ModeReplay,
+				Archive: fixtureArchive,
+			},
+		},
+	})
+
+	bk := &model.Book{Site: Host, ID: 37656, Status: model.StatusError}
+	err := serv.UpdateBook(context.Background(), bk, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "恐怖修仙世界", bk.Title)
+	assert.Equal(t, "龍蛇枝", bk.Writer.Name)
+	assert.EqualValues(t, model.StatusInProgress, bk.Status)
+}
@@ -56,6 +56,19 @@ class BookSpiderRepostory {
     });
   }
 
+  Future<List<Book>> searchAllBooks(
+      {required title, required writer, required int page, required perPage}) {
+    return http
+        .get(Uri.parse(
+            "${this.url}/books/search?title=${title}&writer=${writer}&page=${page}&per_page=${perPage}"))
+        .then((response) {
+      Map<String, dynamic> responseMap = Map.from(jsonDecode(response.body));
+      List booksResponse = responseMap['books'];
+      return List<Book>.from(booksResponse
+          .map((resp) => Book.from(Map<String, dynamic>.from(resp))));
+    });
+  }
+
   Future<List<Book>> randomBook({required String site, required int perPage}) {
     return http
         .get(Uri.parse(